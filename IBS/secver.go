@@ -29,13 +29,19 @@ type Share_Sig struct {
 	SemiHS1 *[]shmpc.Share_Fp
 }
 
-func SecureVerInit(Partynum int, mpk *MasterPubKey, ismalicious bool, isWAN bool, bandwidth float64) *SecureVer {
+// SecureVerInit returns mpc.ErrBandwidth, or shmpc.ErrBandwidth for a
+// semi-honest system, when isWAN is set without a positive bandwidth.
+func SecureVerInit(Partynum int, mpk *MasterPubKey, ismalicious bool, isWAN bool, bandwidth float64) (*SecureVer, error) {
 	securever := new(SecureVer)
 	securever.Security = ismalicious
 	securever.mpk = mpk
 	if ismalicious {
 		if isWAN {
-			securever.System = *mpc.SystemInitWAN(Partynum, bandwidth)
+			system, err := mpc.SystemInitWAN(Partynum, bandwidth)
+			if err != nil {
+				return nil, err
+			}
+			securever.System = *system
 		} else {
 			securever.System = *mpc.SystemInit(Partynum)
 		}
@@ -43,14 +49,25 @@ func SecureVerInit(Partynum int, mpk *MasterPubKey, ismalicious bool, isWAN bool
 		securever.IdentityGTbytes = securever.System.IdentityGT.Marshal()
 	} else {
 		if isWAN {
-			securever.SemiSystem = *shmpc.SystemInitWAN(Partynum, bandwidth)
+			system, err := shmpc.SystemInitWAN(Partynum, bandwidth)
+			if err != nil {
+				return nil, err
+			}
+			securever.SemiSystem = *system
 		} else {
 			securever.SemiSystem = *shmpc.SystemInit(Partynum)
 		}
 		securever.Semimpkshare = securever.SemiSystem.Share_A_G2(mpk.Mpk)
 		securever.IdentityGTbytes = securever.SemiSystem.IdentityGT.Marshal()
 	}
-	return securever
+	return securever, nil
+}
+
+// EnableIdentifiableAbort switches System to the identifiable-abort mode and
+// shares the master public key again so that it carries MACs too.
+func (securever *SecureVer) EnableIdentifiableAbort() {
+	securever.System.EnableIdentifiableAbort()
+	securever.mpkshare = securever.System.Share_A_G2(securever.mpk.Mpk)
 }
 
 func (securever *SecureVer) Share_A_Sig(sig Sig, msg []byte, id *big.Int) *Share_Sig {
@@ -71,6 +88,31 @@ func (securever *SecureVer) Share_A_Sig(sig Sig, msg []byte, id *big.Int) *Share
 	return share_sig
 }
 
+// Share_A_Sig_Owner is Share_A_Sig for a signature held by party owner. In
+// the malicious security setting the hashes are shared with
+// Share_An_Fp_Proven, so every party has checked its shares against the
+// commitments of the owner before they are used; the error of a failed check
+// is returned. The semi-honest setting trusts the owner and shares as
+// Share_A_Sig does.
+func (securever *SecureVer) Share_A_Sig_Owner(owner int, sig Sig, msg []byte, id *big.Int) (*Share_Sig, error) {
+	if !securever.Security {
+		return securever.Share_A_Sig(sig, msg, id), nil
+	}
+	share_sig := new(Share_Sig)
+	share_sig.S = sig
+	var err error
+	if share_sig.HM, err = securever.System.Share_An_Fp_Proven(owner, H1(msg)); err != nil {
+		return nil, err
+	}
+	if share_sig.HID, err = securever.System.Share_An_Fp_Proven(owner, H2(id)); err != nil {
+		return nil, err
+	}
+	if share_sig.HS1, err = securever.System.Share_An_Fp_Proven(owner, H3(sig.S1)); err != nil {
+		return nil, err
+	}
+	return share_sig, nil
+}
+
 func (securever *SecureVer) SecVer(sigshares *Share_Sig) (bool, bool) {
 	if securever.Security {
 		g2hidshares := securever.System.EXP_P_G2_1(curve.Gen2, sigshares.HID)
@@ -0,0 +1,79 @@
+package ibs
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/Oryx/curve"
+	"github.com/Oryx/shamir"
+)
+
+// ShamirVer is the semi-honest setting of SecureVer on the t-out-of-n
+// system of shamir, so verification goes on while parties drop out.
+type ShamirVer struct {
+	mpk             *MasterPubKey
+	IdentityGTbytes []byte
+	System          *shamir.ShareSystem
+	mpkshare        *[]shamir.Share_G2
+}
+
+type Share_Sig_Shamir struct {
+	S   Sig
+	HM  *[]shamir.Share_Fp
+	HID *[]shamir.Share_Fp
+	HS1 *[]shamir.Share_Fp
+}
+
+// ShamirVerInit returns shamir.ErrThreshold as shamir.SystemInit does, and
+// shamir.ErrBandwidth when isWAN is set without a positive bandwidth.
+func ShamirVerInit(Partynum, Threshold int, mpk *MasterPubKey, isWAN bool, bandwidth float64) (*ShamirVer, error) {
+	securever := new(ShamirVer)
+	securever.mpk = mpk
+	var system *shamir.ShareSystem
+	var err error
+	if isWAN {
+		system, err = shamir.SystemInitWAN(Partynum, Threshold, bandwidth)
+	} else {
+		system, err = shamir.SystemInit(Partynum, Threshold)
+	}
+	if err != nil {
+		return nil, err
+	}
+	securever.System = system
+	securever.mpkshare = securever.System.Share_A_G2(mpk.Mpk)
+	securever.IdentityGTbytes = securever.System.IdentityGT.Marshal()
+	return securever, nil
+}
+
+func (securever *ShamirVer) Share_A_Sig(sig Sig, msg []byte, id *big.Int) *Share_Sig_Shamir {
+	share_sig := new(Share_Sig_Shamir)
+	share_sig.S = sig
+	share_sig.HM = securever.System.Share_An_Fp_Offline(H1(msg))
+	share_sig.HID = securever.System.Share_An_Fp_Offline(H2(id))
+	share_sig.HS1 = securever.System.Share_An_Fp_Offline(H3(sig.S1))
+	return share_sig
+}
+
+// SecVer returns shamir.ErrDropouts when too few parties are online to open
+// the result.
+func (securever *ShamirVer) SecVer(sigshares *Share_Sig_Shamir) (bool, error) {
+	resshares := securever.SecVerWithoutOpen(sigshares)
+	res, err := securever.System.OpenGT(*resshares)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(res.Marshal(), securever.IdentityGTbytes), nil
+}
+
+// SecVerWithoutOpen is SemiSecVerWithoutOpen on Shamir shares. It is local,
+// so it needs no party to be online.
+func (securever *ShamirVer) SecVerWithoutOpen(sigshares *Share_Sig_Shamir) *[]shamir.Share_GT {
+	g2hidshares := securever.System.EXP_P_G2_1(curve.Gen2, sigshares.HID)
+	mpkg2hidshares := securever.System.SecAdd_G2(*g2hidshares, *securever.mpkshare)
+	wshares := securever.System.Pair_P_2(sigshares.S.S2, mpkg2hidshares)
+	hidaddhs1shares := securever.System.SecAdd(*sigshares.HM, *sigshares.HS1)
+	hshares := securever.System.EXP_P_GT_1(securever.mpk.G, hidaddhs1shares)
+	haddwshares := securever.System.SecAdd_GT(*wshares, *hshares)
+	resshares := securever.System.SecSubPlaintext_GT(*haddwshares, sigshares.S.S1)
+	return resshares
+}
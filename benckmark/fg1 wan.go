@@ -12,7 +12,11 @@ import (
 
 func TestMaliciousHalfOpenG1WAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		_, g1, _ := curve.RandomG1(rand.Reader)
 		shares1 := system.Share_A_G1(g1)
 		testnum := 1 << 18
@@ -40,7 +44,11 @@ func TestMaliciousHalfOpenG1WAN(bandwidth float64) {
 
 func TestMaliciousOpenG1WAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		_, g1, _ := curve.RandomG1(rand.Reader)
 		shares1 := system.Share_A_G1(g1)
 		testnum := 1 << 18
@@ -68,7 +76,11 @@ func TestMaliciousOpenG1WAN(bandwidth float64) {
 
 func TestMaliciousSecAddG1WAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		_, g1, _ := curve.RandomG1(rand.Reader)
 		_, g2, _ := curve.RandomG1(rand.Reader)
 		shares1 := system.Share_A_G1(g1)
@@ -98,7 +110,11 @@ func TestMaliciousSecAddG1WAN(bandwidth float64) {
 
 func TestMaliciousSecAddPG1WAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		_, g1, _ := curve.RandomG1(rand.Reader)
 		_, g2, _ := curve.RandomG1(rand.Reader)
 		shares1 := system.Share_A_G1(g1)
@@ -127,7 +143,11 @@ func TestMaliciousSecAddPG1WAN(bandwidth float64) {
 
 func TestMaliciousSecSubG1WAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		_, g1, _ := curve.RandomG1(rand.Reader)
 		_, g2, _ := curve.RandomG1(rand.Reader)
 		shares1 := system.Share_A_G1(g1)
@@ -157,7 +177,11 @@ func TestMaliciousSecSubG1WAN(bandwidth float64) {
 
 func TestMaliciousSecSubPG1WAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		_, g1, _ := curve.RandomG1(rand.Reader)
 		_, g2, _ := curve.RandomG1(rand.Reader)
 		shares1 := system.Share_A_G1(g1)
@@ -186,7 +210,11 @@ func TestMaliciousSecSubPG1WAN(bandwidth float64) {
 
 func TestMaliciousSecExp1G1WAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		x1, _ := rand.Int(rand.Reader, system.Order)
 		_, g1, _ := curve.RandomG1(rand.Reader)
 		shares1 := system.Share_An_Fp(x1)
@@ -215,7 +243,11 @@ func TestMaliciousSecExp1G1WAN(bandwidth float64) {
 
 func TestMaliciousSecExp2G1WAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		x1, _ := rand.Int(rand.Reader, system.Order)
 		_, g1, _ := curve.RandomG1(rand.Reader)
 		shares1 := system.Share_A_G1(g1)
@@ -244,7 +276,11 @@ func TestMaliciousSecExp2G1WAN(bandwidth float64) {
 
 func TestMaliciousSecExp3G1WAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		x1, _ := rand.Int(rand.Reader, system.Order)
 		_, g1, _ := curve.RandomG1(rand.Reader)
 		shares1 := system.Share_An_Fp(x1)
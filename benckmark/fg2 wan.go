@@ -12,7 +12,11 @@ import (
 
 func TestMaliciousHalfOpenG2WAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		_, g1, _ := curve.RandomG2(rand.Reader)
 		shares1 := system.Share_A_G2(g1)
 		testnum := 1 << 15
@@ -40,7 +44,11 @@ func TestMaliciousHalfOpenG2WAN(bandwidth float64) {
 
 func TestMaliciousOpenG2WAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		_, g1, _ := curve.RandomG2(rand.Reader)
 		shares1 := system.Share_A_G2(g1)
 		testnum := 1 << 15
@@ -68,7 +76,11 @@ func TestMaliciousOpenG2WAN(bandwidth float64) {
 
 func TestMaliciousSecAddG2WAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		_, g1, _ := curve.RandomG2(rand.Reader)
 		_, g2, _ := curve.RandomG2(rand.Reader)
 		shares1 := system.Share_A_G2(g1)
@@ -98,7 +110,11 @@ func TestMaliciousSecAddG2WAN(bandwidth float64) {
 
 func TestMaliciousSecAddPG2WAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		_, g1, _ := curve.RandomG2(rand.Reader)
 		_, g2, _ := curve.RandomG2(rand.Reader)
 		shares1 := system.Share_A_G2(g1)
@@ -127,7 +143,11 @@ func TestMaliciousSecAddPG2WAN(bandwidth float64) {
 
 func TestMaliciousSecSubG2WAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		_, g1, _ := curve.RandomG2(rand.Reader)
 		_, g2, _ := curve.RandomG2(rand.Reader)
 		shares1 := system.Share_A_G2(g1)
@@ -157,7 +177,11 @@ func TestMaliciousSecSubG2WAN(bandwidth float64) {
 
 func TestMaliciousSecSubPG2WAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		_, g1, _ := curve.RandomG2(rand.Reader)
 		_, g2, _ := curve.RandomG2(rand.Reader)
 		shares1 := system.Share_A_G2(g1)
@@ -186,7 +210,11 @@ func TestMaliciousSecSubPG2WAN(bandwidth float64) {
 
 func TestMaliciousSecExp1G2WAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		x1, _ := rand.Int(rand.Reader, system.Order)
 		_, g1, _ := curve.RandomG2(rand.Reader)
 		shares1 := system.Share_An_Fp(x1)
@@ -215,7 +243,11 @@ func TestMaliciousSecExp1G2WAN(bandwidth float64) {
 
 func TestMaliciousSecExp2G2WAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		x1, _ := rand.Int(rand.Reader, system.Order)
 		_, g1, _ := curve.RandomG2(rand.Reader)
 		shares1 := system.Share_A_G2(g1)
@@ -243,7 +275,11 @@ func TestMaliciousSecExp2G2WAN(bandwidth float64) {
 
 func TestMaliciousSecExp3G2WAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		x1, _ := rand.Int(rand.Reader, system.Order)
 		_, g1, _ := curve.RandomG2(rand.Reader)
 		shares1 := system.Share_An_Fp(x1)
@@ -11,7 +11,11 @@ import (
 
 func TestMaliciousHalfOpenG_WAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.ECCSystemInitWAN(partynum, bandwidth)
+		system, err := mpc.ECCSystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		gx, gy := system.RandomG()
 		shares1 := system.Share_A_G(gx, gy)
 		testnum := 1 << 18
@@ -39,7 +43,11 @@ func TestMaliciousHalfOpenG_WAN(bandwidth float64) {
 
 func TestMaliciousOpenG_WAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.ECCSystemInitWAN(partynum, bandwidth)
+		system, err := mpc.ECCSystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		gx, gy := system.RandomG()
 		shares1 := system.Share_A_G(gx, gy)
 		testnum := 1 << 18
@@ -67,7 +75,11 @@ func TestMaliciousOpenG_WAN(bandwidth float64) {
 
 func TestMaliciousSecAddG_WAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.ECCSystemInitWAN(partynum, bandwidth)
+		system, err := mpc.ECCSystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		gx1, gy1 := system.RandomG()
 		gx2, gy2 := system.RandomG()
 		shares1 := system.Share_A_G(gx1, gy1)
@@ -97,7 +109,11 @@ func TestMaliciousSecAddG_WAN(bandwidth float64) {
 
 func TestMaliciousSecAddPG_WAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.ECCSystemInitWAN(partynum, bandwidth)
+		system, err := mpc.ECCSystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		gx1, gy1 := system.RandomG()
 		gx2, gy2 := system.RandomG()
 		shares1 := system.Share_A_G(gx1, gy1)
@@ -126,7 +142,11 @@ func TestMaliciousSecAddPG_WAN(bandwidth float64) {
 
 func TestMaliciousSecSubG_WAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.ECCSystemInitWAN(partynum, bandwidth)
+		system, err := mpc.ECCSystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		gx1, gy1 := system.RandomG()
 		gx2, gy2 := system.RandomG()
 		shares1 := system.Share_A_G(gx1, gy1)
@@ -156,7 +176,11 @@ func TestMaliciousSecSubG_WAN(bandwidth float64) {
 
 func TestMaliciousSecSubPG_WAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.ECCSystemInitWAN(partynum, bandwidth)
+		system, err := mpc.ECCSystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		gx1, gy1 := system.RandomG()
 		gx2, gy2 := system.RandomG()
 		shares1 := system.Share_A_G(gx1, gy1)
@@ -185,7 +209,11 @@ func TestMaliciousSecSubPG_WAN(bandwidth float64) {
 
 func TestMaliciousSecExp1G_WAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.ECCSystemInitWAN(partynum, bandwidth)
+		system, err := mpc.ECCSystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		x1, _ := rand.Int(rand.Reader, system.Order)
 		gx1, gy1 := system.RandomG()
 		shares1 := system.Share_An_Fp(x1)
@@ -214,7 +242,11 @@ func TestMaliciousSecExp1G_WAN(bandwidth float64) {
 
 func TestMaliciousSecExp2G_WAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.ECCSystemInitWAN(partynum, bandwidth)
+		system, err := mpc.ECCSystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		x1, _ := rand.Int(rand.Reader, system.Order)
 		gx1, gy1 := system.RandomG()
 		shares1 := system.Share_A_G(gx1, gy1)
@@ -243,7 +275,11 @@ func TestMaliciousSecExp2G_WAN(bandwidth float64) {
 
 func TestMaliciousSecExp3G_WAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.ECCSystemInitWAN(partynum, bandwidth)
+		system, err := mpc.ECCSystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		x1, _ := rand.Int(rand.Reader, system.Order)
 		gx1, gy1 := system.RandomG()
 		shares1 := system.Share_An_Fp(x1)
@@ -12,7 +12,11 @@ import (
 
 func TestMaliciousHalfOpenGTWAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		_, g1, _ := curve.RandomGTK(rand.Reader)
 		shares1 := system.Share_A_GT(g1)
 		testnum := 1 << 15
@@ -40,7 +44,11 @@ func TestMaliciousHalfOpenGTWAN(bandwidth float64) {
 
 func TestMaliciousOpenGTWAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		_, g1, _ := curve.RandomGTK(rand.Reader)
 		shares1 := system.Share_A_GT(g1)
 		testnum := 1 << 15
@@ -68,7 +76,11 @@ func TestMaliciousOpenGTWAN(bandwidth float64) {
 
 func TestMaliciousSecAddGTWAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		_, g1, _ := curve.RandomGTK(rand.Reader)
 		_, g2, _ := curve.RandomGTK(rand.Reader)
 		shares1 := system.Share_A_GT(g1)
@@ -98,7 +110,11 @@ func TestMaliciousSecAddGTWAN(bandwidth float64) {
 
 func TestMaliciousSecAddPGTWAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		_, g1, _ := curve.RandomGTK(rand.Reader)
 		_, g2, _ := curve.RandomGTK(rand.Reader)
 		shares1 := system.Share_A_GT(g1)
@@ -127,7 +143,11 @@ func TestMaliciousSecAddPGTWAN(bandwidth float64) {
 
 func TestMaliciousSecSubGTWAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		_, g1, _ := curve.RandomGTK(rand.Reader)
 		_, g2, _ := curve.RandomGTK(rand.Reader)
 		shares1 := system.Share_A_GT(g1)
@@ -157,7 +177,11 @@ func TestMaliciousSecSubGTWAN(bandwidth float64) {
 
 func TestMaliciousSecSubPGTWAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		_, g1, _ := curve.RandomGTK(rand.Reader)
 		_, g2, _ := curve.RandomGTK(rand.Reader)
 		shares1 := system.Share_A_GT(g1)
@@ -186,7 +210,11 @@ func TestMaliciousSecSubPGTWAN(bandwidth float64) {
 
 func TestMaliciousSecExp1GTWAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		x1, _ := rand.Int(rand.Reader, system.Order)
 		_, g1, _ := curve.RandomGTK(rand.Reader)
 		shares1 := system.Share_An_Fp(x1)
@@ -215,7 +243,11 @@ func TestMaliciousSecExp1GTWAN(bandwidth float64) {
 
 func TestMaliciousSecExp2GTWAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		x1, _ := rand.Int(rand.Reader, system.Order)
 		_, g1, _ := curve.RandomGTK(rand.Reader)
 		shares1 := system.Share_A_GT(g1)
@@ -244,7 +276,11 @@ func TestMaliciousSecExp2GTWAN(bandwidth float64) {
 
 func TestMaliciousSecExp3GTWAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		x1, _ := rand.Int(rand.Reader, system.Order)
 		_, g1, _ := curve.RandomGTK(rand.Reader)
 		shares1 := system.Share_An_Fp(x1)
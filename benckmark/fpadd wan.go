@@ -13,7 +13,11 @@ import (
 func TestMaliciousHalfOpenWAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
 		e1, _ := curve.RandomK(rand.Reader)
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		shares1 := system.Share_An_Fp(e1)
 		testnum := 1 << 18
 		worker := 8192
@@ -41,7 +45,11 @@ func TestMaliciousHalfOpenWAN(bandwidth float64) {
 func TestMaliciousOpenWAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
 		e1, _ := curve.RandomK(rand.Reader)
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		shares1 := system.Share_An_Fp(e1)
 		testnum := 1 << 18
 		worker := 8192
@@ -70,7 +78,11 @@ func TestMaliciousSecAddWAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
 		e1, _ := curve.RandomK(rand.Reader)
 		e2, _ := curve.RandomK(rand.Reader)
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		shares1 := system.Share_An_Fp(e1)
 		shares2 := system.Share_An_Fp(e2)
 		testnum := 1 << 18
@@ -100,7 +112,11 @@ func TestMaliciousSecSubWAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
 		e1, _ := curve.RandomK(rand.Reader)
 		e2, _ := curve.RandomK(rand.Reader)
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		shares1 := system.Share_An_Fp(e1)
 		shares2 := system.Share_An_Fp(e2)
 		testnum := 1 << 18
@@ -130,7 +146,11 @@ func TestMaliciousSecMulPWAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
 		e1, _ := curve.RandomK(rand.Reader)
 		e2, _ := curve.RandomK(rand.Reader)
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		shares1 := system.Share_An_Fp(e1)
 		testnum := 1 << 18
 		worker := 8192
@@ -159,7 +179,11 @@ func TestMaliciousSecMulWAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
 		e1, _ := curve.RandomK(rand.Reader)
 		e2, _ := curve.RandomK(rand.Reader)
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		shares1 := system.Share_An_Fp(e1)
 		shares2 := system.Share_An_Fp(e2)
 		testnum := 1 << 14
@@ -188,7 +212,11 @@ func TestMaliciousSecMulWAN(bandwidth float64) {
 func TestMaliciousSecSquareWAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
 		e1, _ := curve.RandomK(rand.Reader)
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		shares1 := system.Share_An_Fp(e1)
 		testnum := 1 << 16
 		worker := 8192
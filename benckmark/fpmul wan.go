@@ -13,8 +13,12 @@ import (
 func TestMaliciousHalfOpenMulWAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
 		e1, _ := curve.RandomK(rand.Reader)
-		system := mpc.SystemInitWAN(partynum, bandwidth)
-		shares1 := system.Share_An_Fp_Mul(e1)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		shares1, _ := system.Share_An_Fp_Mul(e1)
 		testnum := 1 << 18
 		worker := 8192
 		var wg sync.WaitGroup // 创建 WaitGroup 实例
@@ -41,8 +45,12 @@ func TestMaliciousHalfOpenMulWAN(bandwidth float64) {
 func TestMaliciousOpenMulWAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
 		e1, _ := curve.RandomK(rand.Reader)
-		system := mpc.SystemInitWAN(partynum, bandwidth)
-		shares1 := system.Share_An_Fp_Mul(e1)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		shares1, _ := system.Share_An_Fp_Mul(e1)
 		testnum := 1 << 18
 		worker := 8192
 		var wg sync.WaitGroup // 创建 WaitGroup 实例
@@ -70,9 +78,13 @@ func TestMaliciousSecMul_MulWAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
 		e1, _ := curve.RandomK(rand.Reader)
 		e2, _ := curve.RandomK(rand.Reader)
-		system := mpc.SystemInitWAN(partynum, bandwidth)
-		shares1 := system.Share_An_Fp_Mul(e1)
-		shares2 := system.Share_An_Fp_Mul(e2)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		shares1, _ := system.Share_An_Fp_Mul(e1)
+		shares2, _ := system.Share_An_Fp_Mul(e2)
 		testnum := 1 << 18
 		worker := 8192
 		var wg sync.WaitGroup // 创建 WaitGroup 实例
@@ -100,9 +112,13 @@ func TestMaliciousSecDiv_MulWAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
 		e1, _ := curve.RandomK(rand.Reader)
 		e2, _ := curve.RandomK(rand.Reader)
-		system := mpc.SystemInitWAN(partynum, bandwidth)
-		shares1 := system.Share_An_Fp_Mul(e1)
-		shares2 := system.Share_An_Fp_Mul(e2)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		shares1, _ := system.Share_An_Fp_Mul(e1)
+		shares2, _ := system.Share_An_Fp_Mul(e2)
 		testnum := 1 << 18
 		worker := 8192
 		var wg sync.WaitGroup // 创建 WaitGroup 实例
@@ -130,8 +146,12 @@ func TestMaliciousSecExp1WAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
 		e1, _ := curve.RandomK(rand.Reader)
 		e2, _ := curve.RandomK(rand.Reader)
-		system := mpc.SystemInitWAN(partynum, bandwidth)
-		shares2 := system.Share_An_Fp_for_EXP(e2)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		shares2, _ := system.Share_An_Fp_for_EXP(e2)
 		testnum := 1 << 18
 		worker := 8192
 		var wg sync.WaitGroup // 创建 WaitGroup 实例
@@ -159,8 +179,12 @@ func TestMaliciousSecExp2WAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
 		e1, _ := curve.RandomK(rand.Reader)
 		e2, _ := curve.RandomK(rand.Reader)
-		system := mpc.SystemInitWAN(partynum, bandwidth)
-		shares1 := system.Share_An_Fp_Mul(e1)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		shares1, _ := system.Share_An_Fp_Mul(e1)
 		testnum := 1 << 18
 		worker := 8192
 		var wg sync.WaitGroup // 创建 WaitGroup 实例
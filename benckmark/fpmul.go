@@ -14,7 +14,7 @@ func TestMaliciousHalfOpenMul() {
 	for partynum := 2; partynum <= 10; partynum++ {
 		e1, _ := curve.RandomK(rand.Reader)
 		system := mpc.SystemInit(partynum)
-		shares1 := system.Share_An_Fp_Mul(e1)
+		shares1, _ := system.Share_An_Fp_Mul(e1)
 		testnum := 1 << 20
 		worker := 8192
 		var wg sync.WaitGroup // 创建 WaitGroup 实例
@@ -41,7 +41,7 @@ func TestMaliciousOpenMul() {
 	for partynum := 2; partynum <= 10; partynum++ {
 		e1, _ := curve.RandomK(rand.Reader)
 		system := mpc.SystemInit(partynum)
-		shares1 := system.Share_An_Fp_Mul(e1)
+		shares1, _ := system.Share_An_Fp_Mul(e1)
 		testnum := 1 << 20
 		worker := 8192
 		var wg sync.WaitGroup // 创建 WaitGroup 实例
@@ -69,8 +69,8 @@ func TestMaliciousSecMul_Mul() {
 		e1, _ := curve.RandomK(rand.Reader)
 		e2, _ := curve.RandomK(rand.Reader)
 		system := mpc.SystemInit(partynum)
-		shares1 := system.Share_An_Fp_Mul(e1)
-		shares2 := system.Share_An_Fp_Mul(e2)
+		shares1, _ := system.Share_An_Fp_Mul(e1)
+		shares2, _ := system.Share_An_Fp_Mul(e2)
 		testnum := 1 << 20
 		worker := 8192
 		var wg sync.WaitGroup // 创建 WaitGroup 实例
@@ -98,8 +98,8 @@ func TestMaliciousSecDiv_Mul() {
 		e1, _ := curve.RandomK(rand.Reader)
 		e2, _ := curve.RandomK(rand.Reader)
 		system := mpc.SystemInit(partynum)
-		shares1 := system.Share_An_Fp_Mul(e1)
-		shares2 := system.Share_An_Fp_Mul(e2)
+		shares1, _ := system.Share_An_Fp_Mul(e1)
+		shares2, _ := system.Share_An_Fp_Mul(e2)
 		testnum := 1 << 20
 		worker := 8192
 		var wg sync.WaitGroup // 创建 WaitGroup 实例
@@ -127,7 +127,7 @@ func TestMaliciousSecExp1() {
 		e1, _ := curve.RandomK(rand.Reader)
 		e2, _ := curve.RandomK(rand.Reader)
 		system := mpc.SystemInit(partynum)
-		shares2 := system.Share_An_Fp_for_EXP(e2)
+		shares2, _ := system.Share_An_Fp_for_EXP(e2)
 		testnum := 1 << 20
 		worker := 8192
 		var wg sync.WaitGroup // 创建 WaitGroup 实例
@@ -155,7 +155,7 @@ func TestMaliciousSecExp2() {
 		e1, _ := curve.RandomK(rand.Reader)
 		e2, _ := curve.RandomK(rand.Reader)
 		system := mpc.SystemInit(partynum)
-		shares1 := system.Share_An_Fp_Mul(e1)
+		shares1, _ := system.Share_An_Fp_Mul(e1)
 		testnum := 1 << 20
 		worker := 8192
 		var wg sync.WaitGroup // 创建 WaitGroup 实例
@@ -12,7 +12,11 @@ import (
 
 func TestMaliciousSecPair1WAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		_, g1, _ := curve.RandomG1(rand.Reader)
 		_, g2, _ := curve.RandomG2(rand.Reader)
 		shares1 := system.Share_A_G1(g1)
@@ -41,7 +45,11 @@ func TestMaliciousSecPair1WAN(bandwidth float64) {
 
 func TestMaliciousSecPair2WAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		_, g1, _ := curve.RandomG1(rand.Reader)
 		_, g2, _ := curve.RandomG2(rand.Reader)
 		shares2 := system.Share_A_G2(g2)
@@ -70,7 +78,11 @@ func TestMaliciousSecPair2WAN(bandwidth float64) {
 
 func TestMaliciousSecPair3WAN(bandwidth float64) {
 	for partynum := 2; partynum <= 10; partynum++ {
-		system := mpc.SystemInitWAN(partynum, bandwidth)
+		system, err := mpc.SystemInitWAN(partynum, bandwidth)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 		_, g1, _ := curve.RandomG1(rand.Reader)
 		_, g2, _ := curve.RandomG2(rand.Reader)
 		shares1 := system.Share_A_G1(g1)
@@ -7,6 +7,7 @@ import (
 	"github.com/Oryx/pii"
 	"github.com/Oryx/pii_bls"
 	"github.com/Oryx/pii_ecdsa"
+	"github.com/Oryx/pii_rss"
 )
 
 // PII based on Our AIBS
@@ -19,7 +20,9 @@ func TwoPartyPII_AIBS_example() {
 	t1 := time.Now()
 
 	// If the third parameter is 0, it is the PII protocol, and if it is 1, it is the PIIv protocol.
-	pii.PIIProtocol(intersize, inputsize, 0, false, 0)
+	if _, err := pii.PIIProtocol(intersize, inputsize, 0, false, 0); err != nil {
+		fmt.Println(err)
+	}
 	t2 := time.Since(t1)
 	fmt.Println(t2)
 }
@@ -29,7 +32,9 @@ func TwoPartyPIIv_AIBS_example() {
 	inputsize := []int{32, 32}
 	intersize := 10
 	t1 := time.Now()
-	pii.PIIProtocol(intersize, inputsize, 1, false, 0)
+	if _, err := pii.PIIProtocol(intersize, inputsize, 1, false, 0); err != nil {
+		fmt.Println(err)
+	}
 	t2 := time.Since(t1)
 	fmt.Println(t2)
 }
@@ -39,7 +44,9 @@ func MutiPartyPIIv_AIBS_example() {
 	inputsize := []int{32, 32, 32}
 	intersize := 10
 	t1 := time.Now()
-	pii.PIIProtocol(intersize, inputsize, 1, false, 0)
+	if _, err := pii.PIIProtocol(intersize, inputsize, 1, false, 0); err != nil {
+		fmt.Println(err)
+	}
 	t2 := time.Since(t1)
 	fmt.Println(t2)
 }
@@ -49,7 +56,9 @@ func TwoPartyPII_BLS_example() {
 	inputsize := []int{32, 32}
 	intersize := 10
 	t1 := time.Now()
-	pii_bls.PIIProtocol(intersize, inputsize, 0, false, 0)
+	if _, err := pii_bls.PIIProtocol(intersize, inputsize, 0, false, 0); err != nil {
+		fmt.Println(err)
+	}
 	t2 := time.Since(t1)
 	fmt.Println(t2)
 }
@@ -59,7 +68,9 @@ func TwoPartyPIIv_BLS_example() {
 	inputsize := []int{32, 32}
 	intersize := 10
 	t1 := time.Now()
-	pii_bls.PIIProtocol(intersize, inputsize, 1, false, 0)
+	if _, err := pii_bls.PIIProtocol(intersize, inputsize, 1, false, 0); err != nil {
+		fmt.Println(err)
+	}
 	t2 := time.Since(t1)
 	fmt.Println(t2)
 }
@@ -69,7 +80,9 @@ func TwoPartyPII_ECDSA_example() {
 	inputsize := []int{32, 32}
 	intersize := 10
 	t1 := time.Now()
-	pii_ecdsa.PIIProtocol(intersize, inputsize, 0, false, 0)
+	if _, err := pii_ecdsa.PIIProtocol(intersize, inputsize, 0, false, 0); err != nil {
+		fmt.Println(err)
+	}
 	t2 := time.Since(t1)
 	fmt.Println(t2)
 }
@@ -79,7 +92,9 @@ func TwoPartyPIIv_ECDSA_example() {
 	inputsize := []int{32, 32}
 	intersize := 10
 	t1 := time.Now()
-	pii_ecdsa.PIIProtocol(intersize, inputsize, 1, false, 0)
+	if _, err := pii_ecdsa.PIIProtocol(intersize, inputsize, 1, false, 0); err != nil {
+		fmt.Println(err)
+	}
 	t2 := time.Since(t1)
 	fmt.Println(t2)
 }
@@ -94,7 +109,40 @@ func BenckmarkMutiPartyPII_ECDSA_example() {
 			}
 			intersize := inputsizetests[i] / 2
 			t1 := time.Now()
-			pii.PIIProtocol(intersize, inputsize, 1, false, 0)
+			if _, err := pii.PIIProtocol(intersize, inputsize, 1, false, 0); err != nil {
+				fmt.Println(err)
+			}
+			t2 := time.Since(t1)
+			fmt.Println(t2)
+		}
+	}
+}
+
+// PII on 3-party replicated sharing, to compare with TwoPartyPII_AIBS_example
+func TwoPartyPII_RSS_example() {
+	inputsize := []int{32, 32}
+	intersize := 10
+	t1 := time.Now()
+	if _, err := pii_rss.PIIProtocol(intersize, inputsize, false, 0); err != nil {
+		fmt.Println(err)
+	}
+	t2 := time.Since(t1)
+	fmt.Println(t2)
+}
+
+func BenckmarkPII_RSS_example() {
+	inputsizetests := []int{10, 20, 50, 100, 200, 500, 1000}
+	for i := 0; i < len(inputsizetests); i++ {
+		for j := 2; j <= 3; j++ {
+			inputsize := make([]int, 0, j)
+			for k := 0; k < j; k++ {
+				inputsize = append(inputsize, inputsizetests[i])
+			}
+			intersize := inputsizetests[i] / 2
+			t1 := time.Now()
+			if _, err := pii_rss.PIIProtocol(intersize, inputsize, false, 0); err != nil {
+				fmt.Println(err)
+			}
 			t2 := time.Since(t1)
 			fmt.Println(t2)
 		}
@@ -7,6 +7,7 @@ import (
 	"github.com/Oryx/pii"
 	"github.com/Oryx/pii_bls"
 	"github.com/Oryx/pii_ecdsa"
+	"github.com/Oryx/pii_rss"
 )
 
 // PII based on Our AIBS
@@ -19,7 +20,9 @@ func TwoPartyPII_AIBS_example_WAN(bandwidth float64) {
 	t1 := time.Now()
 
 	// If the third parameter is 0, it is the PII protocol, and if it is 1, it is the PIIv protocol.
-	pii.PIIProtocol(intersize, inputsize, 0, true, bandwidth)
+	if _, err := pii.PIIProtocol(intersize, inputsize, 0, true, bandwidth); err != nil {
+		fmt.Println(err)
+	}
 	t2 := time.Since(t1)
 	fmt.Println(t2)
 }
@@ -29,7 +32,9 @@ func TwoPartyPIIv_AIBS_example_WAN(bandwidth float64) {
 	inputsize := []int{10, 10}
 	intersize := 5
 	t1 := time.Now()
-	pii.PIIProtocol(intersize, inputsize, 1, true, bandwidth)
+	if _, err := pii.PIIProtocol(intersize, inputsize, 1, true, bandwidth); err != nil {
+		fmt.Println(err)
+	}
 	t2 := time.Since(t1)
 	fmt.Println(t2)
 }
@@ -41,7 +46,9 @@ func BenckmarkTwoPartyPIIv_AIBS_example_WAN(bandwidth float64) {
 		inputsize := []int{inputsizetests[i], inputsizetests[i]}
 		intersize := inputsizetests[i] / 2
 		t1 := time.Now()
-		pii.PIIProtocol(intersize, inputsize, 1, true, bandwidth)
+		if _, err := pii.PIIProtocol(intersize, inputsize, 1, true, bandwidth); err != nil {
+			fmt.Println(err)
+		}
 		t2 := time.Since(t1)
 		fmt.Println(t2)
 	}
@@ -58,7 +65,9 @@ func BenckmarkMutiPartyPIIv_AIBS_example_WAN(bandwidth float64) {
 			}
 			intersize := inputsizetests[i] / 2
 			t1 := time.Now()
-			pii.PIIProtocol(intersize, inputsize, 1, true, bandwidth)
+			if _, err := pii.PIIProtocol(intersize, inputsize, 1, true, bandwidth); err != nil {
+				fmt.Println(err)
+			}
 			t2 := time.Since(t1)
 			fmt.Println(t2)
 		}
@@ -70,7 +79,9 @@ func TwoPartyPII_BLS_example_WAN(bandwidth float64) {
 	inputsize := []int{32, 32}
 	intersize := 10
 	t1 := time.Now()
-	pii_bls.PIIProtocol(intersize, inputsize, 0, true, bandwidth)
+	if _, err := pii_bls.PIIProtocol(intersize, inputsize, 0, true, bandwidth); err != nil {
+		fmt.Println(err)
+	}
 	t2 := time.Since(t1)
 	fmt.Println(t2)
 }
@@ -80,7 +91,9 @@ func TwoPartyPIIv_BLS_example_WAN(bandwidth float64) {
 	inputsize := []int{32, 32}
 	intersize := 10
 	t1 := time.Now()
-	pii_bls.PIIProtocol(intersize, inputsize, 1, true, bandwidth)
+	if _, err := pii_bls.PIIProtocol(intersize, inputsize, 1, true, bandwidth); err != nil {
+		fmt.Println(err)
+	}
 	t2 := time.Since(t1)
 	fmt.Println(t2)
 }
@@ -92,7 +105,9 @@ func BenchmarkTwoPartyPIIv_BLS_example_WAN(bandwidth float64) {
 		inputsize := []int{inputsizetests[i], inputsizetests[i]}
 		intersize := inputsizetests[i] / 2
 		t1 := time.Now()
-		pii_bls.PIIProtocol(intersize, inputsize, 1, true, bandwidth)
+		if _, err := pii_bls.PIIProtocol(intersize, inputsize, 1, true, bandwidth); err != nil {
+			fmt.Println(err)
+		}
 		t2 := time.Since(t1)
 		fmt.Println(t2)
 	}
@@ -103,7 +118,9 @@ func TwoPartyPII_ECDSA_example_WAN(bandwidth float64) {
 	inputsize := []int{32, 32}
 	intersize := 10
 	t1 := time.Now()
-	pii_ecdsa.PIIProtocol(intersize, inputsize, 0, true, bandwidth)
+	if _, err := pii_ecdsa.PIIProtocol(intersize, inputsize, 0, true, bandwidth); err != nil {
+		fmt.Println(err)
+	}
 	t2 := time.Since(t1)
 	fmt.Println(t2)
 }
@@ -115,7 +132,9 @@ func BenchmarkTwoPartyPII_ECDSA_example_WAN(bandwidth float64) {
 		inputsize := []int{inputsizetests[i], inputsizetests[i]}
 		intersize := inputsizetests[i] / 2
 		t1 := time.Now()
-		pii_ecdsa.PIIProtocol(intersize, inputsize, 1, true, bandwidth)
+		if _, err := pii_ecdsa.PIIProtocol(intersize, inputsize, 1, true, bandwidth); err != nil {
+			fmt.Println(err)
+		}
 		t2 := time.Since(t1)
 		fmt.Println(t2)
 	}
@@ -126,7 +145,24 @@ func TwoPartyPIIv_ECDSA_example_WAN(bandwidth float64) {
 	inputsize := []int{32, 32}
 	intersize := 10
 	t1 := time.Now()
-	pii_ecdsa.PIIProtocol(intersize, inputsize, 1, true, bandwidth)
+	if _, err := pii_ecdsa.PIIProtocol(intersize, inputsize, 1, true, bandwidth); err != nil {
+		fmt.Println(err)
+	}
 	t2 := time.Since(t1)
 	fmt.Println(t2)
 }
+
+// PII on 3-party replicated sharing
+func BenckmarkTwoPartyPII_RSS_example_WAN(bandwidth float64) {
+	inputsizetests := []int{10, 20, 50, 100, 200, 500, 1000}
+	for i := 0; i < len(inputsizetests); i++ {
+		inputsize := []int{inputsizetests[i], inputsizetests[i]}
+		intersize := inputsizetests[i] / 2
+		t1 := time.Now()
+		if _, err := pii_rss.PIIProtocol(intersize, inputsize, true, bandwidth); err != nil {
+			fmt.Println(err)
+		}
+		t2 := time.Since(t1)
+		fmt.Println(t2)
+	}
+}
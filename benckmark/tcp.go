@@ -0,0 +1,82 @@
+package benckmark
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/Oryx/curve"
+	mpc "github.com/Oryx/mpc"
+	"github.com/Oryx/network"
+)
+
+// TCPParty_example runs one process of a TCP deployment. addrs lists the
+// parties followed by the dealer, so the last index plays the dealer.
+func TCPParty_example(index int, addrs []string) error {
+	partynum := len(addrs) - 1
+	net, err := network.NewTCPTransport(index, addrs, 30*time.Second)
+	if err != nil {
+		return err
+	}
+	defer net.Close()
+	x := big.NewInt(6)
+	y := big.NewInt(7)
+	h := big.NewInt(5)
+	gen := curve.Pair(curve.Gen1, curve.Gen2)
+	if index == partynum {
+		system := mpc.SystemInit(partynum)
+		dealer := mpc.NewDealer(system, net)
+		if err := dealer.DealKeys(); err != nil {
+			return err
+		}
+		if err := dealer.DealFp(*system.Share_An_Fp_Offline(x)); err != nil {
+			return err
+		}
+		if err := dealer.DealFp(*system.Share_An_Fp_Offline(y)); err != nil {
+			return err
+		}
+		if err := dealer.DealGT(*system.Share_A_GT_Offline(new(curve.GT).ScalarMult(gen, h))); err != nil {
+			return err
+		}
+		if err := dealer.DealTriplet(); err != nil {
+			return err
+		}
+		fmt.Printf("dealer: offline communication %d bytes\n", system.OfflineCom)
+		return nil
+	}
+	party := mpc.NewParty(index, partynum, net)
+	if err := party.RecvKey(); err != nil {
+		return err
+	}
+	xshare, err := party.RecvFp()
+	if err != nil {
+		return err
+	}
+	yshare, err := party.RecvFp()
+	if err != nil {
+		return err
+	}
+	hshare, err := party.RecvGT()
+	if err != nil {
+		return err
+	}
+	t1 := time.Now()
+	sum, chk, err := party.OpenFp(party.SecAdd(xshare, yshare))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("party %d: x+y = %s, mac check %v\n", index, sum, chk)
+	wshare, err := party.EXP_S_GT(hshare, xshare)
+	if err != nil {
+		return err
+	}
+	w, chk, err := party.OpenGT(wshare)
+	if err != nil {
+		return err
+	}
+	want := new(curve.GT).ScalarMult(gen, new(big.Int).Mul(h, x))
+	fmt.Printf("party %d: h^x correct %v, mac check %v\n", index, bytes.Equal(w.Marshal(), want.Marshal()), chk)
+	fmt.Printf("party %d: online took %s, sent %d bytes\n", index, time.Since(t1), party.Com)
+	return nil
+}
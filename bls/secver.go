@@ -22,23 +22,33 @@ type Share_Sig struct {
 	SemiPkshare *[]shmpc.Share_G2
 }
 
-func SecureVerInit(Partynum int, ismalicious bool, isWAN bool, bandwidth float64) *SecureVer {
+// SecureVerInit returns mpc.ErrBandwidth, or shmpc.ErrBandwidth for a
+// semi-honest system, when isWAN is set without a positive bandwidth.
+func SecureVerInit(Partynum int, ismalicious bool, isWAN bool, bandwidth float64) (*SecureVer, error) {
 	securever := new(SecureVer)
 	securever.Security = ismalicious
 	if ismalicious {
 		if isWAN {
-			securever.System = *mpc.SystemInitWAN(Partynum, bandwidth)
+			system, err := mpc.SystemInitWAN(Partynum, bandwidth)
+			if err != nil {
+				return nil, err
+			}
+			securever.System = *system
 		} else {
 			securever.System = *mpc.SystemInit(Partynum)
 		}
 	} else {
 		if isWAN {
-			securever.SemiSystem = *shmpc.SystemInitWAN(Partynum, bandwidth)
+			system, err := shmpc.SystemInitWAN(Partynum, bandwidth)
+			if err != nil {
+				return nil, err
+			}
+			securever.SemiSystem = *system
 		} else {
 			securever.SemiSystem = *shmpc.SystemInit(Partynum)
 		}
 	}
-	return securever
+	return securever, nil
 }
 
 func (securever *SecureVer) Share_A_Sig(sig *Sig, HM *curve.G1, pk *PublicKey) *Share_Sig {
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Oryx/benckmark"
+)
+
+// Start one process per address, e.g. for two parties and the dealer:
+//
+//	go run ./cmd/party -id 0 -addrs 127.0.0.1:9000,127.0.0.1:9001,127.0.0.1:9002
+//	go run ./cmd/party -id 1 -addrs 127.0.0.1:9000,127.0.0.1:9001,127.0.0.1:9002
+//	go run ./cmd/party -id 2 -addrs 127.0.0.1:9000,127.0.0.1:9001,127.0.0.1:9002
+func main() {
+	id := flag.Int("id", 0, "index of this process; the last address is the dealer")
+	addrs := flag.String("addrs", "", "comma separated listen addresses of all parties and the dealer")
+	flag.Parse()
+	if err := benckmark.TCPParty_example(*id, strings.Split(*addrs, ",")); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
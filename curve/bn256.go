@@ -98,6 +98,20 @@ func (e *G1) Set(a *G1) *G1 {
 	return e
 }
 
+// Identity sets e to the point at infinity and then returns e.
+func (e *G1) Identity() *G1 {
+	if e.p == nil {
+		e.p = &curvePoint{}
+	}
+	e.p.SetInfinity()
+	return e
+}
+
+// Generator sets e to Gen1 and then returns e.
+func (e *G1) Generator() *G1 {
+	return e.Set(Gen1)
+}
+
 // Marshal converts e to a byte slice.
 func (e *G1) Marshal() []byte {
 	// Each value is a 256-bit number.
@@ -230,6 +244,20 @@ func (e *G2) Set(a *G2) *G2 {
 	return e
 }
 
+// Identity sets e to the point at infinity and then returns e.
+func (e *G2) Identity() *G2 {
+	if e.p == nil {
+		e.p = &twistPoint{}
+	}
+	e.p.SetInfinity()
+	return e
+}
+
+// Generator sets e to Gen2 and then returns e.
+func (e *G2) Generator() *G2 {
+	return e.Set(Gen2)
+}
+
 // Marshal converts e into a byte slice.
 func (e *G2) Marshal() []byte {
 	// Each value is a 256-bit number.
@@ -386,6 +414,21 @@ func (e *GT) Set(a *GT) *GT {
 	return e
 }
 
+// Identity sets e to one and then returns e.
+func (e *GT) Identity() *GT {
+	if e.p == nil {
+		e.p = &gfP12{}
+	}
+	e.p.SetOne()
+	return e
+}
+
+// Generator sets e to GenGT, the pairing of Gen1 and Gen2, and then returns
+// e. Unlike ScalarBaseMult it stays in the group of order Order.
+func (e *GT) Generator() *GT {
+	return e.Set(GenGT)
+}
+
 // Finalize is a linear function from F_p^12 to GT.
 func (e *GT) Finalize() *GT {
 	ret := finalExponentiation(e.p)
@@ -0,0 +1,40 @@
+package curve
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+// Group is what G1, G2 and GT have in common, written additively: for GT,
+// Add is the product and ScalarMult the power. Like the methods themselves,
+// it is satisfied by the pointer type, so code generic over Group[E] takes
+// and returns *E. Generator must generate the group of order Order.
+type Group[E any] interface {
+	*E
+	Add(a, b *E) *E
+	Neg(a *E) *E
+	ScalarMult(a *E, k *big.Int) *E
+	Set(a *E) *E
+	Identity() *E
+	Generator() *E
+	Marshal() []byte
+	Unmarshal(m []byte) ([]byte, error)
+}
+
+var (
+	_ = Equal[G1, *G1]
+	_ = Equal[G2, *G2]
+	_ = Equal[GT, *GT]
+)
+
+// RandomElement returns a random element of the group generated by
+// Generator.
+func RandomElement[E any, P Group[E]]() *E {
+	k, _ := RandomK(rand.Reader)
+	return P(new(E)).ScalarMult(P(new(E)).Generator(), k)
+}
+
+// Equal reports whether a and b are the same element.
+func Equal[E any, P Group[E]](a, b *E) bool {
+	return string(P(a).Marshal()) == string(P(b).Marshal())
+}
@@ -12,3 +12,29 @@ func HashToG1(msg []byte) *G1 {
 	hmpoint := new(G1).ScalarBaseMult(hmInt)
 	return hmpoint
 }
+
+// MapToG1 hashes msg to a point of G1 by trying x = H(ctr, msg) until x^3+b
+// is a square. Unlike for HashToG1 nobody knows its discrete logarithm to
+// Gen1, so it can serve as an independent generator.
+func MapToG1(msg []byte) *G1 {
+	b := big.NewInt(5)
+	for ctr := 0; ; ctr++ {
+		h := sha256.Sum256(append([]byte{byte(ctr >> 8), byte(ctr)}, msg...))
+		x := new(big.Int).SetBytes(h[:])
+		x = x.Mod(x, p)
+		rhs := new(big.Int).Exp(x, big.NewInt(3), p)
+		rhs = rhs.Add(rhs, b)
+		rhs = rhs.Mod(rhs, p)
+		y := new(big.Int).ModSqrt(rhs, p)
+		if y == nil {
+			continue
+		}
+		m := make([]byte, 64)
+		x.FillBytes(m[:32])
+		y.FillBytes(m[32:])
+		point := new(G1)
+		if _, err := point.Unmarshal(m); err == nil {
+			return point
+		}
+	}
+}
@@ -24,25 +24,35 @@ type Share_Sig struct {
 	SemiPkshare *[]shmpc.Share_G
 }
 
-func SecureVerInit(Partynum int, ismalicious bool, isWAN bool, bandwidth float64) *SecureVer {
+// SecureVerInit returns mpc.ErrBandwidth, or shmpc.ErrBandwidth for a
+// semi-honest system, when isWAN is set without a positive bandwidth.
+func SecureVerInit(Partynum int, ismalicious bool, isWAN bool, bandwidth float64) (*SecureVer, error) {
 	securever := new(SecureVer)
 	securever.Ecdsa = NewECDSA()
 	if ismalicious {
 		if isWAN {
-			securever.System = *mpc.ECCSystemInitWAN(Partynum, bandwidth)
+			system, err := mpc.ECCSystemInitWAN(Partynum, bandwidth)
+			if err != nil {
+				return nil, err
+			}
+			securever.System = *system
 		} else {
 			securever.System = *mpc.ECCSystemInit(Partynum)
 		}
 		securever.Security = true
 	} else {
 		if isWAN {
-			securever.SemiSystem = *shmpc.ECCSystemInitWAN(Partynum, bandwidth)
+			system, err := shmpc.ECCSystemInitWAN(Partynum, bandwidth)
+			if err != nil {
+				return nil, err
+			}
+			securever.SemiSystem = *system
 		} else {
 			securever.SemiSystem = *shmpc.ECCSystemInit(Partynum)
 		}
 		securever.Security = false
 	}
-	return securever
+	return securever, nil
 }
 
 func (securever *SecureVer) Share_A_Sig(sig SigInv, pk *PublicKey) *Share_Sig {
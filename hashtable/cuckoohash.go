@@ -25,6 +25,19 @@ func NewCuckooHashTable(size int) *CuckooHashTable {
 	}
 }
 
+// NewCuckooHashTableWithBins creates a table for size elements with a given
+// number of bins, e.g. a larger one after Insert failed.
+func NewCuckooHashTableWithBins(size int, cuckoosize int) *CuckooHashTable {
+	return &CuckooHashTable{
+		table:      make([]*big.Int, cuckoosize),
+		size:       size,
+		cuckoosize: cuckoosize,
+		maxLoops:   500,
+	}
+}
+
+// Insert places the keys; it returns false when a key could not be placed
+// within maxLoops evictions, which leaves the table unusable.
 func (cht *CuckooHashTable) Insert(key []*big.Int) bool {
 	Hash_index := make([]uint64, cht.cuckoosize)
 	for i := 0; i < cht.size; i++ {
@@ -46,8 +59,7 @@ func (cht *CuckooHashTable) Insert(key []*big.Int) bool {
 				old_hash_id = old_hash_id%3 + 1
 			}
 		}
-		if j == MAXITER-1 {
-			fmt.Println("insert failed, ", i)
+		if j == MAXITER {
 			return false
 		}
 	}
@@ -81,3 +93,9 @@ func (cht *CuckooHashTable) Iterate() {
 func (cht *CuckooHashTable) GetSize() (int, int) {
 	return cht.size, cht.cuckoosize
 }
+
+// Table returns the bins of the table; an empty bin is nil. Element key sits
+// in bin XX64(key.Bytes(), h) % cuckoosize for one h in 1..3.
+func (cht *CuckooHashTable) Table() []*big.Int {
+	return cht.table
+}
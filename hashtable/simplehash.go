@@ -1,7 +1,6 @@
 package hashtable
 
 import (
-	"fmt"
 	"math/big"
 	"math/rand"
 )
@@ -29,15 +28,54 @@ func NewSimpleHash(size int, bucketSize int, hashnum int) *SimpleHash {
 	}
 }
 
+// NewSimpleHashWithBuckets creates a table with a given number of buckets,
+// e.g. the size of a CuckooHashTable whose bins it has to match.
+func NewSimpleHashWithBuckets(numBuckets int, bucketSize int, hashnum int) *SimpleHash {
+	table := make([][]*big.Int, numBuckets)
+	for i := range table {
+		table[i] = make([]*big.Int, 0, bucketSize)
+	}
+	return &SimpleHash{
+		NumBuckets: numBuckets,
+		BucketSize: bucketSize,
+		Elements:   table,
+		Hashnum:    hashnum,
+	}
+}
+
+// Insert puts key into the bucket of each of the hash functions 1..Hashnum,
+// the ones CuckooHashTable uses, and into each bucket only once.
 func (h *SimpleHash) Insert(key *big.Int) {
-	for i := 0; i < h.Hashnum; i++ {
+	indexes := make([]uint64, 0, h.Hashnum)
+	for i := 1; i <= h.Hashnum; i++ {
 		index := XX64(key.Bytes(), uint64(i)) % uint64(h.NumBuckets)
+		dup := false
+		for _, prev := range indexes {
+			if prev == index {
+				dup = true
+			}
+		}
+		if dup {
+			continue
+		}
+		indexes = append(indexes, index)
 		h.Elements[index] = append(h.Elements[index], key)
 	}
 }
 
+// MaxLoad returns the number of elements in the fullest bucket.
+func (h *SimpleHash) MaxLoad() int {
+	maxBucketSize := 0
+	for _, bucket := range h.Elements {
+		if len(bucket) > maxBucketSize {
+			maxBucketSize = len(bucket)
+		}
+	}
+	return maxBucketSize
+}
+
 func (h *SimpleHash) Find(key *big.Int) bool {
-	for i := 0; i < h.Hashnum; i++ {
+	for i := 1; i <= h.Hashnum; i++ {
 		index := XX64(key.Bytes(), uint64(i)) % uint64(h.NumBuckets)
 		for _, value := range h.Elements[index] {
 			if value.Cmp(key) == 0 {
@@ -49,13 +87,7 @@ func (h *SimpleHash) Find(key *big.Int) bool {
 }
 
 func (h *SimpleHash) FillBuckets() {
-	maxBucketSize := 0
-	for _, bucket := range h.Elements {
-		if len(bucket) > maxBucketSize {
-			maxBucketSize = len(bucket)
-		}
-	}
-	fmt.Println(maxBucketSize)
+	maxBucketSize := h.MaxLoad()
 	h.BucketSize = maxBucketSize
 	for i := 0; i < h.NumBuckets; i++ {
 		for len(h.Elements[i]) < maxBucketSize {
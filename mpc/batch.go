@@ -0,0 +1,505 @@
+package mpc
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/big"
+	"sync"
+
+	curve "github.com/Oryx/curve"
+	"github.com/Oryx/network"
+)
+
+// OpenBatch collects openings whose MAC checks are deferred. For every value
+// opened through the batch each party keeps its local difference
+// Gama_i - Alphas[i]*(v + Delta); DeferredMacCheck verifies a random linear
+// combination of all of them in a single commit-and-open round. Opened values
+// must not be used for outputs before the check has passed.
+//
+// In the identifiable-abort mode the batch also keeps the opened shares, so
+// that after a failed check Cheater can name the deviating party. Openings
+// made through a view from At are located by Err.
+type OpenBatch struct {
+	*openBatch
+	at location
+}
+
+// openBatch is the state shared by an OpenBatch and its views.
+type openBatch struct {
+	system  *ShareSystem
+	mu      sync.Mutex
+	fp      [][]*big.Int
+	g1      [][]*curve.G1
+	g2      [][]*curve.G2
+	gt      [][]*curve.GT
+	opened  openedShares
+	locs    openedLocations
+	cheater int
+	failed  location
+}
+
+type openedShares struct {
+	fp [][]Share_Fp
+	g1 [][]Share_G1
+	g2 [][]Share_G2
+	gt [][]Share_GT
+}
+
+// location is the party and the input element an opening belongs to, -1 for
+// either if it is not known.
+type location struct {
+	party   int
+	element int
+}
+
+var noLocation = location{-1, -1}
+
+type openedLocations struct {
+	fp []location
+	g1 []location
+	g2 []location
+	gt []location
+}
+
+func (system *ShareSystem) NewOpenBatch() *OpenBatch {
+	batch := new(OpenBatch)
+	batch.openBatch = new(openBatch)
+	batch.system = system
+	batch.cheater = -1
+	batch.at = noLocation
+	batch.failed = noLocation
+	return batch
+}
+
+// At returns a view of the batch whose openings belong to element of party.
+// They are checked with the rest of the batch; when the check fails, Err
+// names the first of them that does not pass.
+func (batch *OpenBatch) At(party, element int) *OpenBatch {
+	return &OpenBatch{openBatch: batch.openBatch, at: location{party, element}}
+}
+
+// Cheater returns the party found responsible by the last failed
+// DeferredMacCheck, or -1 if it passed or the party could not be identified.
+func (batch *OpenBatch) Cheater() int {
+	batch.mu.Lock()
+	defer batch.mu.Unlock()
+	return batch.cheater
+}
+
+// Err returns the *MacCheckError of the last failed DeferredMacCheck for the
+// opening op, with the party and element of the first opening that did not
+// pass and the Cheater.
+func (batch *OpenBatch) Err(op string) error {
+	batch.mu.Lock()
+	defer batch.mu.Unlock()
+	return &MacCheckError{Op: op, Party: batch.failed.party, Element: batch.failed.element, Cheater: batch.cheater}
+}
+
+// locate returns the location of the first opening whose differences do not
+// sum to zero. After an abort the parties may open their differences one by
+// one, since the values they belong to are not used.
+func (batch *OpenBatch) locate() location {
+	system := batch.system
+	for k, d := range batch.fp {
+		sum := big.NewInt(0)
+		for _, di := range d {
+			sum = sum.Add(sum, di)
+		}
+		if sum.Mod(sum, system.Order).Sign() != 0 {
+			return batch.locs.fp[k]
+		}
+	}
+	if k := groupFailed(batch.g1); k >= 0 {
+		return batch.locs.g1[k]
+	}
+	if k := groupFailed(batch.g2); k >= 0 {
+		return batch.locs.g2[k]
+	}
+	if k := groupFailed(batch.gt); k >= 0 {
+		return batch.locs.gt[k]
+	}
+	return noLocation
+}
+
+// groupFailed returns the index of the first opening whose differences do
+// not sum to the identity, or -1.
+func groupFailed[E any, P curve.Group[E]](diffs [][]*E) int {
+	for k, d := range diffs {
+		sum := P(new(E)).Identity()
+		for _, di := range d {
+			sum = P(sum).Add(sum, di)
+		}
+		if !curve.Equal[E, P](sum, P(new(E)).Identity()) {
+			return k
+		}
+	}
+	return -1
+}
+
+// identify runs the identification of the identifiable-abort mode on every
+// opening of the batch until one names a party.
+func (batch *OpenBatch) identify() int {
+	system := batch.system
+	for _, shares := range batch.opened.fp {
+		if cheater := system.IdentifyFp(shares); cheater >= 0 {
+			return cheater
+		}
+	}
+	for _, shares := range batch.opened.g1 {
+		if cheater := system.IdentifyG1(shares); cheater >= 0 {
+			return cheater
+		}
+	}
+	for _, shares := range batch.opened.g2 {
+		if cheater := system.IdentifyG2(shares); cheater >= 0 {
+			return cheater
+		}
+	}
+	for _, shares := range batch.opened.gt {
+		if cheater := system.IdentifyGT(shares); cheater >= 0 {
+			return cheater
+		}
+	}
+	return -1
+}
+
+// Len returns the number of openings waiting for DeferredMacCheck.
+func (batch *OpenBatch) Len() int {
+	batch.mu.Lock()
+	defer batch.mu.Unlock()
+	return len(batch.fp) + len(batch.g1) + len(batch.g2) + len(batch.gt)
+}
+
+func (batch *OpenBatch) OpenFp(shares []Share_Fp) *big.Int {
+	system := batch.system
+	value := system.HalfOpenFp(shares)
+	diffs := make([]*big.Int, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		diffs[i] = system.macDiffFp(system.Alphas[i], shares[i], value)
+	}
+	batch.mu.Lock()
+	batch.fp = append(batch.fp, diffs)
+	batch.locs.fp = append(batch.locs.fp, batch.at)
+	if system.ia != nil {
+		batch.opened.fp = append(batch.opened.fp, shares)
+	}
+	batch.mu.Unlock()
+	return value
+}
+
+func (batch *OpenBatch) OpenG1(shares []Share_G1) *curve.G1 {
+	value := batch.system.HalfOpenG1(shares)
+	diffs := groupDiffs(batch.system, shares, value)
+	batch.mu.Lock()
+	batch.g1 = append(batch.g1, diffs)
+	batch.locs.g1 = append(batch.locs.g1, batch.at)
+	if batch.system.ia != nil {
+		batch.opened.g1 = append(batch.opened.g1, shares)
+	}
+	batch.mu.Unlock()
+	return value
+}
+
+func (batch *OpenBatch) OpenG2(shares []Share_G2) *curve.G2 {
+	value := batch.system.HalfOpenG2(shares)
+	diffs := groupDiffs(batch.system, shares, value)
+	batch.mu.Lock()
+	batch.g2 = append(batch.g2, diffs)
+	batch.locs.g2 = append(batch.locs.g2, batch.at)
+	if batch.system.ia != nil {
+		batch.opened.g2 = append(batch.opened.g2, shares)
+	}
+	batch.mu.Unlock()
+	return value
+}
+
+func (batch *OpenBatch) OpenGT(shares []Share_GT) *curve.GT {
+	value := batch.system.HalfOpenGT(shares)
+	diffs := groupDiffs(batch.system, shares, value)
+	batch.mu.Lock()
+	batch.gt = append(batch.gt, diffs)
+	batch.locs.gt = append(batch.locs.gt, batch.at)
+	if batch.system.ia != nil {
+		batch.opened.gt = append(batch.opened.gt, shares)
+	}
+	batch.mu.Unlock()
+	return value
+}
+
+// groupCombine returns the combination of the differences of party i with
+// the coefficients from coeffs[k] on, and the next unused k.
+func groupCombine[E any, P curve.Group[E]](diffs [][]*E, i int, coeffs []*big.Int, k int) (*E, int) {
+	sum := P(new(E)).Identity()
+	for _, d := range diffs {
+		sum = P(sum).Add(sum, P(new(E)).ScalarMult(d[i], coeffs[k]))
+		k++
+	}
+	return sum, k
+}
+
+// coin returns a public random value from commitCoin. It panics if the
+// randomness source fails.
+func (system *ShareSystem) coin() *big.Int {
+	t, err := commitCoin(system.Partynum, system.Order, func(i int, msg []byte) {
+		var wg sync.WaitGroup
+		wg.Add(1)
+		system.Broadcast(&wg, i, msg)
+	})
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// coefficient expands the public seed into the k-th coefficient of the
+// random linear combination.
+func coefficient(seed *big.Int, k int, order *big.Int) *big.Int {
+	hasher := sha256.New()
+	hasher.Write(seed.Bytes())
+	hasher.Write(binary.BigEndian.AppendUint64(nil, uint64(k)))
+	r := new(big.Int).SetBytes(hasher.Sum(nil))
+	return r.Mod(r, order)
+}
+
+// DeferredMacCheck checks all openings collected in batch and empties it.
+// Each party combines its differences with public random coefficients, then
+// the parties commit to and open their combinations; the check passes if
+// they sum to zero in every group. In the identifiable-abort mode a failed
+// check is followed by the identification recorded in batch.Cheater.
+func (system *ShareSystem) DeferredMacCheck(batch *OpenBatch) bool {
+	batch.mu.Lock()
+	defer batch.mu.Unlock()
+	defer func() {
+		batch.fp, batch.g1, batch.g2, batch.gt = nil, nil, nil, nil
+		batch.opened = openedShares{}
+		batch.locs = openedLocations{}
+	}()
+	batch.cheater = -1
+	batch.failed = noLocation
+	if system.deferredMacCheck(batch) {
+		return true
+	}
+	batch.failed = batch.locate()
+	batch.cheater = batch.identify()
+	return false
+}
+
+func (system *ShareSystem) deferredMacCheck(batch *OpenBatch) bool {
+	seed := system.coin()
+	coeffs := make([]*big.Int, len(batch.fp)+len(batch.g1)+len(batch.g2)+len(batch.gt))
+	for k := range coeffs {
+		coeffs[k] = coefficient(seed, k, system.Order)
+	}
+	var wg sync.WaitGroup
+	chkfp := big.NewInt(0)
+	chkg1 := new(curve.G1).Set(system.IdentityG1)
+	chkg2 := new(curve.G2).Set(system.IdentityG2)
+	chkgt := new(curve.GT).Set(system.IdentityGT)
+	for i := 0; i < system.Partynum; i++ {
+		k := 0
+		sfp := big.NewInt(0)
+		for _, d := range batch.fp {
+			sfp = sfp.Add(sfp, new(big.Int).Mul(coeffs[k], d[i]))
+			k++
+		}
+		sfp = sfp.Mod(sfp, system.Order)
+		sg1, k := groupCombine(batch.g1, i, coeffs, k)
+		sg2, k := groupCombine(batch.g2, i, coeffs, k)
+		sgt, _ := groupCombine(batch.gt, i, coeffs, k)
+		msg := network.Pack(sfp.Bytes(), sg1.Marshal(), sg2.Marshal(), sgt.Marshal())
+		commit, r := Com(msg)
+		wg.Add(3)
+		go system.Broadcast(&wg, i, commit)
+		go system.Broadcast(&wg, i, r.Bytes())
+		go system.Broadcast(&wg, i, msg)
+		if !OpenComit(msg, commit, r) {
+			wg.Wait()
+			return false
+		}
+		chkfp = chkfp.Add(chkfp, sfp)
+		chkg1 = chkg1.Add(chkg1, sg1)
+		chkg2 = chkg2.Add(chkg2, sg2)
+		chkgt = chkgt.Add(chkgt, sgt)
+	}
+	wg.Wait()
+	chkfp = chkfp.Mod(chkfp, system.Order)
+	return chkfp.Sign() == 0 &&
+		bytes.Equal(chkg1.Marshal(), system.IdentityG1.Marshal()) &&
+		bytes.Equal(chkg2.Marshal(), system.IdentityG2.Marshal()) &&
+		bytes.Equal(chkgt.Marshal(), system.IdentityGTBytes)
+}
+
+// ECCOpenBatch is the OpenBatch of an ECCShareSystem. The ECC system has no
+// identifiable-abort mode, so a failed check names no Cheater.
+type ECCOpenBatch struct {
+	*eccOpenBatch
+	at location
+}
+
+type eccOpenBatch struct {
+	system *ECCShareSystem
+	mu     sync.Mutex
+	fp     [][]*big.Int
+	g      [][][2]*big.Int
+	locs   struct{ fp, g []location }
+	failed location
+}
+
+func (system *ECCShareSystem) NewOpenBatch() *ECCOpenBatch {
+	batch := new(ECCOpenBatch)
+	batch.eccOpenBatch = new(eccOpenBatch)
+	batch.system = system
+	batch.at = noLocation
+	batch.failed = noLocation
+	return batch
+}
+
+// At is OpenBatch.At.
+func (batch *ECCOpenBatch) At(party, element int) *ECCOpenBatch {
+	return &ECCOpenBatch{eccOpenBatch: batch.eccOpenBatch, at: location{party, element}}
+}
+
+// Err is OpenBatch.Err; its Cheater is always -1.
+func (batch *ECCOpenBatch) Err(op string) error {
+	batch.mu.Lock()
+	defer batch.mu.Unlock()
+	return &MacCheckError{Op: op, Party: batch.failed.party, Element: batch.failed.element, Cheater: -1}
+}
+
+// locate is OpenBatch.locate.
+func (batch *ECCOpenBatch) locate() location {
+	system := batch.system
+	for k, d := range batch.fp {
+		sum := big.NewInt(0)
+		for _, di := range d {
+			sum = sum.Add(sum, di)
+		}
+		if sum.Mod(sum, system.Order).Sign() != 0 {
+			return batch.locs.fp[k]
+		}
+	}
+	for k, d := range batch.g {
+		x := new(big.Int).Set(system.IdentityGx)
+		y := new(big.Int).Set(system.IdentityGy)
+		for _, di := range d {
+			x, y = system.Curve.Add(x, y, di[0], di[1])
+		}
+		if x.Sign() != 0 || y.Sign() != 0 {
+			return batch.locs.g[k]
+		}
+	}
+	return noLocation
+}
+
+func (batch *ECCOpenBatch) Len() int {
+	batch.mu.Lock()
+	defer batch.mu.Unlock()
+	return len(batch.fp) + len(batch.g)
+}
+
+func (batch *ECCOpenBatch) OpenFp(shares []Share_Fp) *big.Int {
+	system := batch.system
+	value := system.HalfOpenFp(shares)
+	t := new(big.Int).Add(value, shares[0].Delta)
+	diffs := make([]*big.Int, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		d := new(big.Int).Mul(system.Alphas[i], t)
+		d = d.Sub(shares[i].Gama, d)
+		diffs[i] = d.Mod(d, system.Order)
+	}
+	batch.mu.Lock()
+	batch.fp = append(batch.fp, diffs)
+	batch.locs.fp = append(batch.locs.fp, batch.at)
+	batch.mu.Unlock()
+	return value
+}
+
+func (batch *ECCOpenBatch) OpenG(shares []Share_G) (*big.Int, *big.Int) {
+	system := batch.system
+	valueX, valueY := system.HalfOpenG(shares)
+	tx, ty := system.Curve.Add(valueX, valueY, shares[0].DeltaX, shares[0].DeltaY)
+	diffs := make([][2]*big.Int, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		dx, dy := system.Curve.ScalarMult(tx, ty, system.Alphas[i].Bytes())
+		dy = new(big.Int).Mod(new(big.Int).Neg(dy), system.Curve.P)
+		dx, dy = system.Curve.Add(shares[i].GamaX, shares[i].GamaY, dx, dy)
+		diffs[i] = [2]*big.Int{dx, dy}
+	}
+	batch.mu.Lock()
+	batch.g = append(batch.g, diffs)
+	batch.locs.g = append(batch.locs.g, batch.at)
+	batch.mu.Unlock()
+	return valueX, valueY
+}
+
+func (system *ECCShareSystem) coin() *big.Int {
+	t, err := commitCoin(system.Partynum, system.Order, func(i int, msg []byte) {
+		var wg sync.WaitGroup
+		wg.Add(1)
+		system.Broadcast(&wg, i, msg)
+	})
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// DeferredMacCheck is ShareSystem.DeferredMacCheck for the ECC system.
+func (system *ECCShareSystem) DeferredMacCheck(batch *ECCOpenBatch) bool {
+	batch.mu.Lock()
+	defer batch.mu.Unlock()
+	defer func() {
+		batch.fp, batch.g = nil, nil
+		batch.locs.fp, batch.locs.g = nil, nil
+	}()
+	batch.failed = noLocation
+	if system.deferredMacCheck(batch) {
+		return true
+	}
+	batch.failed = batch.locate()
+	return false
+}
+
+func (system *ECCShareSystem) deferredMacCheck(batch *ECCOpenBatch) bool {
+	seed := system.coin()
+	coeffs := make([]*big.Int, len(batch.fp)+len(batch.g))
+	for k := range coeffs {
+		coeffs[k] = coefficient(seed, k, system.Order)
+	}
+	var wg sync.WaitGroup
+	chkfp := big.NewInt(0)
+	chkX := new(big.Int).Set(system.IdentityGx)
+	chkY := new(big.Int).Set(system.IdentityGy)
+	for i := 0; i < system.Partynum; i++ {
+		k := 0
+		sfp := big.NewInt(0)
+		for _, d := range batch.fp {
+			sfp = sfp.Add(sfp, new(big.Int).Mul(coeffs[k], d[i]))
+			k++
+		}
+		sfp = sfp.Mod(sfp, system.Order)
+		sx := new(big.Int).Set(system.IdentityGx)
+		sy := new(big.Int).Set(system.IdentityGy)
+		for _, d := range batch.g {
+			px, py := system.Curve.ScalarMult(d[i][0], d[i][1], coeffs[k].Bytes())
+			sx, sy = system.Curve.Add(sx, sy, px, py)
+			k++
+		}
+		msg := network.Pack(sfp.Bytes(), sx.Bytes(), sy.Bytes())
+		commit, r := Com(msg)
+		wg.Add(3)
+		go system.Broadcast(&wg, i, commit)
+		go system.Broadcast(&wg, i, r.Bytes())
+		go system.Broadcast(&wg, i, msg)
+		if !OpenComit(msg, commit, r) {
+			wg.Wait()
+			return false
+		}
+		chkfp = chkfp.Add(chkfp, sfp)
+		chkX, chkY = system.Curve.Add(chkX, chkY, sx, sy)
+	}
+	wg.Wait()
+	chkfp = chkfp.Mod(chkfp, system.Order)
+	return chkfp.Sign() == 0 && chkX.Sign() == 0 && chkY.Sign() == 0
+}
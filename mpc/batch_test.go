@@ -0,0 +1,52 @@
+package mpc
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestDeferredMacCheck(t *testing.T) {
+	system := SystemInit(3)
+	values := []*big.Int{big.NewInt(0), big.NewInt(42), new(big.Int).Sub(system.Order, one)}
+	for _, tc := range []struct {
+		name    string
+		corrupt int
+	}{
+		{"honest", -1},
+		{"corrupted first", 0},
+		{"corrupted last", len(values) - 1},
+	} {
+		batch := system.NewOpenBatch()
+		for k, v := range values {
+			shares := *system.Share_An_Fp(v)
+			if k == tc.corrupt {
+				shares[1].Share = new(big.Int).Add(shares[1].Share, one)
+			}
+			got := batch.At(1, k).OpenFp(shares)
+			if k != tc.corrupt && got.Cmp(v) != 0 {
+				t.Fatalf("%s: got %v, want %v", tc.name, got, v)
+			}
+		}
+		if batch.Len() != len(values) {
+			t.Fatalf("%s: got %d openings in the batch, want %d", tc.name, batch.Len(), len(values))
+		}
+		ok := system.DeferredMacCheck(batch)
+		if ok != (tc.corrupt < 0) {
+			t.Fatalf("%s: got %v from DeferredMacCheck", tc.name, ok)
+		}
+		if batch.Len() != 0 {
+			t.Fatalf("%s: got %d openings after the check, want 0", tc.name, batch.Len())
+		}
+		if ok {
+			continue
+		}
+		var merr *MacCheckError
+		if !errors.As(batch.Err("test"), &merr) {
+			t.Fatalf("%s: got %v, want a *MacCheckError", tc.name, batch.Err("test"))
+		}
+		if merr.Party != 1 || merr.Element != tc.corrupt {
+			t.Fatalf("%s: got party %d element %d, want party 1 element %d", tc.name, merr.Party, merr.Element, tc.corrupt)
+		}
+	}
+}
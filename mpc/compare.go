@@ -0,0 +1,142 @@
+package mpc
+
+import (
+	"math/big"
+)
+
+// SecEqZero returns a sharing of 1 if x is 0 and of 0 otherwise, without
+// opening anything but the masked values of the multiplications. It computes
+// 1 - x^(p-1) by square-and-multiply, which by Fermat's little theorem is the
+// wanted bit: about log p square pairs and log p / 2 triples.
+func (system *ShareSystem) SecEqZero(shares1 []Share_Fp) *[]Share_Fp {
+	e := new(big.Int).Sub(system.Order, one)
+	shares := &shares1
+	for b := e.BitLen() - 2; b >= 0; b-- {
+		shares = system.SecSquare(*shares)
+		if e.Bit(b) == 1 {
+			shares = system.SecMul(*shares, shares1)
+		}
+	}
+	shares = system.SecMulPlaintext(*shares, e)
+	return system.SecAddPlaintext(*shares, one)
+}
+
+// SecEq returns a sharing of 1 if x equals y and of 0 otherwise.
+func (system *ShareSystem) SecEq(shares1, shares2 []Share_Fp) *[]Share_Fp {
+	return system.SecEqZero(*system.SecSub(shares1, shares2))
+}
+
+// EqZeroCost returns the triples and square pairs one SecEqZero takes.
+func (system *ShareSystem) EqZeroCost() (int, int) {
+	e := new(big.Int).Sub(system.Order, one)
+	triplets := 0
+	for b := e.BitLen() - 2; b >= 0; b-- {
+		triplets += int(e.Bit(b))
+	}
+	return triplets, e.BitLen() - 1
+}
+
+// statSecurity is the statistical security, in bits, with which BitDecompose
+// hides its input.
+const statSecurity = 40
+
+// RandomBit returns a sharing of a random bit no party knows. A random r is
+// squared and the square opened; r / sqrt(r^2) is then 1 or -1 with equal
+// probability, and one half of it plus one is the bit. Like RandomShareFp it
+// panics with the error when the opening fails its MAC check, see Recover.
+func (system *ShareSystem) RandomBit() *[]Share_Fp {
+	half := new(big.Int).ModInverse(two, system.Order)
+	for {
+		r, err := system.freshRandomShareFp()
+		if err != nil {
+			panic(err)
+		}
+		r2, chk := system.OpenFp(*system.SecSquare(*r))
+		if !chk {
+			panic(&MacCheckError{Op: "RandomBit", Party: -1, Element: -1, Cheater: -1})
+		}
+		if r2.Sign() == 0 {
+			continue
+		}
+		s := new(big.Int).ModSqrt(r2, system.Order)
+		s.ModInverse(s, system.Order)
+		shares := system.SecMulPlaintext(*r, s)
+		shares = system.SecAddPlaintext(*shares, one)
+		return system.SecMulPlaintext(*shares, half)
+	}
+}
+
+// secXor returns a sharing of a xor b for shared bits, given a sharing of
+// their product.
+func (system *ShareSystem) secXor(a, b, ab []Share_Fp) *[]Share_Fp {
+	shares := system.SecAdd(a, b)
+	return system.SecSub(*shares, *system.SecMulPlaintext(ab, two))
+}
+
+// BitDecompose returns sharings of the bits of x, least significant first,
+// for x below 2^bits. bits random bits r_i and statSecurity more form a mask
+// r; c = x + r is opened, which does not wrap around p, and x is the low bits
+// of c - r, found by a subtraction circuit on the public bits of c and the
+// shared r_i that takes one SecMul per bit. It panics with ErrBitLength when
+// bits + statSecurity does not fit below p, and with a *MacCheckError when
+// the opening of c fails.
+func (system *ShareSystem) BitDecompose(shares1 []Share_Fp, bits int) []*[]Share_Fp {
+	if bits < 1 || bits+statSecurity >= system.Order.BitLen() {
+		panic(ErrBitLength)
+	}
+	rbits := make([]*[]Share_Fp, bits+statSecurity)
+	var rshares *[]Share_Fp
+	for i := range rbits {
+		rbits[i] = system.RandomBit()
+		ri := system.SecMulPlaintext(*rbits[i], new(big.Int).Lsh(one, uint(i)))
+		if rshares == nil {
+			rshares = ri
+		} else {
+			rshares = system.SecAdd(*rshares, *ri)
+		}
+	}
+	c, chk := system.OpenFp(*system.SecAdd(shares1, *rshares))
+	if !chk {
+		panic(&MacCheckError{Op: "BitDecompose", Party: -1, Element: -1, Cheater: -1})
+	}
+	xbits := make([]*[]Share_Fp, bits)
+	var borrow *[]Share_Fp
+	for i := 0; i < bits; i++ {
+		if borrow == nil {
+			// no borrow so far, as for the lowest bit
+			if c.Bit(i) == 0 {
+				xbits[i], borrow = rbits[i], rbits[i]
+			} else {
+				xbits[i] = system.SecAddPlaintext(*system.SecMulPlaintext(*rbits[i], new(big.Int).Sub(system.Order, one)), one)
+			}
+			continue
+		}
+		rb := system.SecMul(*rbits[i], *borrow)
+		d := system.secXor(*rbits[i], *borrow, *rb)
+		if c.Bit(i) == 0 {
+			xbits[i] = d
+			borrow = system.SecSub(*system.SecAdd(*rbits[i], *borrow), *rb)
+		} else {
+			xbits[i] = system.SecAddPlaintext(*system.SecMulPlaintext(*d, new(big.Int).Sub(system.Order, one)), one)
+			borrow = rb
+		}
+	}
+	return xbits
+}
+
+// SecGreaterEq returns a sharing of 1 if x >= y and of 0 otherwise, for x and
+// y below 2^bits. It is the top bit of x - y + 2^bits, by BitDecompose.
+func (system *ShareSystem) SecGreaterEq(shares1, shares2 []Share_Fp, bits int) *[]Share_Fp {
+	shares := system.SecSub(shares1, shares2)
+	shares = system.SecAddPlaintext(*shares, new(big.Int).Lsh(one, uint(bits)))
+	zbits := system.BitDecompose(*shares, bits+1)
+	return zbits[bits]
+}
+
+// SecLessThan returns a sharing of 1 if x < y and of 0 otherwise, for x and
+// y below 2^bits.
+func (system *ShareSystem) SecLessThan(shares1, shares2 []Share_Fp, bits int) *[]Share_Fp {
+	shares := system.SecGreaterEq(shares1, shares2, bits)
+	shares = system.SecMulPlaintext(*shares, new(big.Int).Sub(system.Order, one))
+	return system.SecAddPlaintext(*shares, one)
+}
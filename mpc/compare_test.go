@@ -0,0 +1,96 @@
+package mpc
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestSecEqZero(t *testing.T) {
+	system := SystemInit(3)
+	pminus1 := new(big.Int).Sub(system.Order, one)
+	for _, tc := range []struct {
+		x    *big.Int
+		want int64
+	}{
+		{big.NewInt(0), 1},
+		{big.NewInt(1), 0},
+		{big.NewInt(12345), 0},
+		{pminus1, 0},
+	} {
+		got, chk := system.OpenFp(*system.SecEqZero(*system.Share_An_Fp(tc.x)))
+		if !chk {
+			t.Fatalf("MAC check failed for x = %v", tc.x)
+		}
+		if got.Cmp(big.NewInt(tc.want)) != 0 {
+			t.Fatalf("got %v for x = %v, want %d", got, tc.x, tc.want)
+		}
+	}
+}
+
+func TestRandomBit(t *testing.T) {
+	system := SystemInit(3)
+	for i := 0; i < 8; i++ {
+		b, chk := system.OpenFp(*system.RandomBit())
+		if !chk {
+			t.Fatal("MAC check failed")
+		}
+		if b.Cmp(zero) != 0 && b.Cmp(one) != 0 {
+			t.Fatalf("got %v, want a bit", b)
+		}
+	}
+}
+
+func TestBitDecompose(t *testing.T) {
+	system := SystemInit(3)
+	const bits = 16
+	for _, x := range []int64{0, 1, 2, 0x5a5a, 1<<bits - 1} {
+		xbits := system.BitDecompose(*system.Share_An_Fp(big.NewInt(x)), bits)
+		if len(xbits) != bits {
+			t.Fatalf("got %d bits, want %d", len(xbits), bits)
+		}
+		for i, shares := range xbits {
+			b, chk := system.OpenFp(*shares)
+			if !chk {
+				t.Fatalf("MAC check failed for bit %d of %d", i, x)
+			}
+			if b.Int64() != (x>>i)&1 {
+				t.Fatalf("got %v for bit %d of %d, want %d", b, i, x, (x>>i)&1)
+			}
+		}
+	}
+}
+
+func TestSecGreaterEq(t *testing.T) {
+	system := SystemInit(3)
+	const bits = 16
+	for _, tc := range []struct {
+		x, y int64
+	}{
+		{0, 0},
+		{0, 1},
+		{1, 0},
+		{300, 299},
+		{299, 300},
+		{1<<bits - 1, 0},
+		{0, 1<<bits - 1},
+		{1<<bits - 1, 1<<bits - 1},
+	} {
+		x := system.Share_An_Fp(big.NewInt(tc.x))
+		y := system.Share_An_Fp(big.NewInt(tc.y))
+		want := int64(0)
+		if tc.x >= tc.y {
+			want = 1
+		}
+		got, chk := system.OpenFp(*system.SecGreaterEq(*x, *y, bits))
+		if !chk {
+			t.Fatalf("MAC check failed for %d >= %d", tc.x, tc.y)
+		}
+		if got.Int64() != want {
+			t.Fatalf("got %v for %d >= %d, want %d", got, tc.x, tc.y, want)
+		}
+		got, chk = system.OpenFp(*system.SecLessThan(*x, *y, bits))
+		if !chk || got.Int64() != 1-want {
+			t.Fatalf("got %v for %d < %d, want %d", got, tc.x, tc.y, 1-want)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package mpc
+
+import (
+	"crypto/rand"
+	"math/big"
+	"sync"
+
+	"github.com/Oryx/curve"
+)
+
+// SetupDistributedMACKey replaces the MAC key of the dealer with one that no
+// one knows. Every party picks its own Alphas[i] and AlphasMul[i]; alpha is
+// the sum of the Alphas and exists nowhere. The dealer's key is dropped, and
+// the OfflinePhase is set up with a Paillier modulus of the given size to
+// produce triples and MACs. It must be called before any value is shared.
+//
+// From then on inputs are shared with Share_An_Fp_Owner and
+// Share_A_G1_Owner, Share_A_G2_Owner and Share_A_GT_Owner, and with
+// Share_An_Fp_Mul_Owner and Share_An_Fp_for_EXP_Owner for the shares of
+// fpmul.go. Public constants, random values, the masks of TruncPr, AddToMul
+// and MulToAdd need no dealer. Share_An_Fp and the other sharings of a value
+// the dealer knows panic with ErrDistributedKey, or return it in fpmul.go.
+func (system *ShareSystem) SetupDistributedMACKey(bits int) error {
+	n := system.Partynum
+	system.Alphas = make([]*big.Int, n)
+	system.AlphasMul = make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		system.Alphas[i], _ = rand.Int(rand.Reader, system.Order)
+		system.AlphasMul[i], _ = rand.Int(rand.Reader, system.OrderMul)
+	}
+	system.alpha = nil
+	return system.SetupOfflinePhase(bits)
+}
+
+// dealerKey returns the MAC key of the dealer and panics with
+// ErrDistributedKey once SetupDistributedMACKey has dropped it.
+func (system *ShareSystem) dealerKey() *big.Int {
+	if system.alpha == nil {
+		panic(ErrDistributedKey)
+	}
+	return system.alpha
+}
+
+// constFp shares a public constant. The dealer shares it as any other value
+// while it holds the key; with a distributed key party 0 takes the constant
+// as its share and every party Alphas[i] times it as its MAC share.
+func (system *ShareSystem) constFp(scalar *big.Int) *[]Share_Fp {
+	if system.alpha != nil {
+		return system.Share_An_Fp(scalar)
+	}
+	shares := make([]Share_Fp, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i].Index = i
+		shares[i].Share = big.NewInt(0)
+		if i == 0 {
+			shares[i].Share = new(big.Int).Mod(scalar, system.Order)
+		}
+		shares[i].Gama = new(big.Int).Mul(system.Alphas[i], scalar)
+		shares[i].Gama = shares[i].Gama.Mod(shares[i].Gama, system.Order)
+		shares[i].Delta = big.NewInt(0)
+		shares[i].Tag = system.constTag(i, scalar)
+	}
+	return &shares
+}
+
+// Share_An_Fp_Owner shares an input of party owner without the dealer. The
+// owner splits it into random additive shares and sends one to every other
+// party; the parties then MAC their shares together with the pairwise
+// products of the OfflinePhase, so nobody needs to know alpha. It panics with
+// ErrNoOfflinePhase before SetupOfflinePhase or SetupDistributedMACKey.
+func (system *ShareSystem) Share_An_Fp_Owner(owner int, element *big.Int) *[]Share_Fp {
+	if system.Offline == nil {
+		panic(ErrNoOfflinePhase)
+	}
+	values := make([]*big.Int, system.Partynum)
+	rest := new(big.Int).Set(element)
+	for i := 0; i < system.Partynum; i++ {
+		if i == owner {
+			continue
+		}
+		values[i], _ = rand.Int(rand.Reader, system.Order)
+		rest = rest.Sub(rest, values[i])
+		system.transfer(owner, i, values[i].Bytes())
+	}
+	values[owner] = rest.Mod(rest, system.Order)
+	shares, err := system.Offline.authenticate(values)
+	if err != nil {
+		panic(err)
+	}
+	return &shares
+}
+
+// Share_A_G1_Owner shares a G1 input of party owner without the dealer. The
+// parties take a random r, compute shares of R = r*Gen1 and send them to the
+// owner, who broadcasts element - R; adding that constant to the shares of R
+// gives the shares of element. R hides the input from the others, and the
+// MACs of R carry over. It panics like Share_An_Fp_Owner.
+func (system *ShareSystem) Share_A_G1_Owner(owner int, element *curve.G1) *[]Share_G1 {
+	return groupShare_A_Owner(system, owner, element)
+}
+
+func (system *ShareSystem) Share_A_G2_Owner(owner int, element *curve.G2) *[]Share_G2 {
+	return groupShare_A_Owner(system, owner, element)
+}
+
+func (system *ShareSystem) Share_A_GT_Owner(owner int, element *curve.GT) *[]Share_GT {
+	return groupShare_A_Owner(system, owner, element)
+}
+
+// SetupDistributedMACKey is the same setup for the ECC system, which has no
+// multiplicative shares and so no second key. Inputs are then shared with
+// Share_An_Fp_Owner and Share_A_G_Owner, and Share_An_Fp and Share_A_G panic
+// with ErrDistributedKey.
+func (system *ECCShareSystem) SetupDistributedMACKey(bits int) error {
+	n := system.Partynum
+	system.Alphas = make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		system.Alphas[i], _ = rand.Int(rand.Reader, system.Order)
+	}
+	system.alpha = nil
+	return system.SetupOfflinePhase(bits)
+}
+
+func (system *ECCShareSystem) dealerKey() *big.Int {
+	if system.alpha == nil {
+		panic(ErrDistributedKey)
+	}
+	return system.alpha
+}
+
+func (system *ECCShareSystem) constFp(scalar *big.Int) *[]Share_Fp {
+	if system.alpha != nil {
+		return system.Share_An_Fp(scalar)
+	}
+	shares := make([]Share_Fp, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i].Index = i
+		shares[i].Share = big.NewInt(0)
+		if i == 0 {
+			shares[i].Share = new(big.Int).Mod(scalar, system.Order)
+		}
+		shares[i].Gama = new(big.Int).Mul(system.Alphas[i], scalar)
+		shares[i].Gama = shares[i].Gama.Mod(shares[i].Gama, system.Order)
+		shares[i].Delta = big.NewInt(0)
+	}
+	return &shares
+}
+
+// constG shares a public point as constFp does; the point at infinity is
+// (0, 0).
+func (system *ECCShareSystem) constG(scalarX, scalarY *big.Int) *[]Share_G {
+	if system.alpha != nil {
+		return system.Share_A_G_Offline(scalarX, scalarY)
+	}
+	shares := make([]Share_G, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i].Index = i
+		shares[i].ShareX, shares[i].ShareY = new(big.Int), new(big.Int)
+		if i == 0 {
+			shares[i].ShareX, shares[i].ShareY = new(big.Int).Set(scalarX), new(big.Int).Set(scalarY)
+		}
+		shares[i].GamaX, shares[i].GamaY = system.Curve.ScalarMult(scalarX, scalarY, system.Alphas[i].Bytes())
+		shares[i].DeltaX, shares[i].DeltaY = new(big.Int), new(big.Int)
+	}
+	return &shares
+}
+
+// Share_An_Fp_Owner is ShareSystem.Share_An_Fp_Owner for the ECC system.
+func (system *ECCShareSystem) Share_An_Fp_Owner(owner int, element *big.Int) *[]Share_Fp {
+	if system.Offline == nil {
+		panic(ErrNoOfflinePhase)
+	}
+	values := make([]*big.Int, system.Partynum)
+	rest := new(big.Int).Set(element)
+	for i := 0; i < system.Partynum; i++ {
+		if i == owner {
+			continue
+		}
+		values[i], _ = rand.Int(rand.Reader, system.Order)
+		rest = rest.Sub(rest, values[i])
+		system.transfer(owner, i, values[i].Bytes())
+	}
+	values[owner] = rest.Mod(rest, system.Order)
+	shares, err := system.Offline.authenticate(values)
+	if err != nil {
+		panic(err)
+	}
+	return &shares
+}
+
+// Share_A_G_Owner shares a point of party owner as Share_A_G1_Owner does:
+// the parties send the owner their shares of R = r*G, and the owner
+// broadcasts element - R.
+func (system *ECCShareSystem) Share_A_G_Owner(owner int, elementX, elementY *big.Int) *[]Share_G {
+	if system.Offline == nil {
+		panic(ErrNoOfflinePhase)
+	}
+	rshares := system.EXP_P_G_1(system.Curve.Gx, system.Curve.Gy, system.randomShareFp())
+	RX := new(big.Int).Set((*rshares)[owner].ShareX)
+	RY := new(big.Int).Set((*rshares)[owner].ShareY)
+	for i := 0; i < system.Partynum; i++ {
+		if i != owner {
+			RX, RY = system.Curve.Add(RX, RY, (*rshares)[i].ShareX, (*rshares)[i].ShareY)
+			system.transfer(i, owner, append((*rshares)[i].ShareX.Bytes(), (*rshares)[i].ShareY.Bytes()...))
+		}
+	}
+	cX, cY := system.Curve.Add(elementX, elementY, RX, new(big.Int).Mod(new(big.Int).Neg(RY), system.Curve.P))
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go system.Broadcast(&wg, owner, cX.Bytes())
+	go system.Broadcast(&wg, owner, cY.Bytes())
+	wg.Wait()
+	return system.SecAdd_G(*rshares, *system.constG(cX, cY))
+}
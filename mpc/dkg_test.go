@@ -0,0 +1,75 @@
+package mpc
+
+import (
+	"math/big"
+	"testing"
+)
+
+// paillierBits is a Paillier modulus size just above what the offline phase
+// needs, which keeps these tests fast.
+const paillierBits = 640
+
+func TestSetupDistributedMACKey(t *testing.T) {
+	system := SystemInit(2)
+	if err := system.SetupDistributedMACKey(paillierBits); err != nil {
+		t.Fatal(err)
+	}
+	if err := system.SetupPRSS(); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		x, y *big.Int
+	}{
+		{big.NewInt(0), big.NewInt(7)},
+		{big.NewInt(6), big.NewInt(7)},
+		{new(big.Int).Sub(system.Order, one), big.NewInt(2)},
+	} {
+		x := system.Share_An_Fp_Owner(0, tc.x)
+		y := system.Share_An_Fp_Owner(1, tc.y)
+		want := new(big.Int).Mul(tc.x, tc.y)
+		want.Mod(want, system.Order)
+		got, chk := system.OpenFp(*system.SecMul(*x, *y))
+		if !chk {
+			t.Fatalf("MAC check failed for %v * %v", tc.x, tc.y)
+		}
+		if got.Cmp(want) != 0 {
+			t.Fatalf("got %v for %v * %v, want %v", got, tc.x, tc.y, want)
+		}
+	}
+	k := system.ReservePRSS(2)
+	sharings, err := system.RandomSharesFp_PRSS(k, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for j, shares := range sharings {
+		got, chk := system.OpenFp(*shares)
+		if !chk || got.Cmp(prssValue(system, k+uint64(j))) != 0 {
+			t.Fatalf("got %v for PRSS index %d", got, k+uint64(j))
+		}
+	}
+	x := system.Share_An_Fp_Owner(1, big.NewInt(5))
+	mshares, err := system.AddToMul(*x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ashares, err := system.MulToAdd(*mshares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, chk := system.OpenFp(*ashares); !chk || got.Int64() != 5 {
+		t.Fatalf("got %v after AddToMul and MulToAdd, want 5", got)
+	}
+}
+
+func TestDistributedKeyDealer(t *testing.T) {
+	system := SystemInit(2)
+	if err := system.SetupDistributedMACKey(paillierBits); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if r := recover(); r != ErrDistributedKey {
+			t.Fatalf("got %v, want a panic with %v", r, ErrDistributedKey)
+		}
+	}()
+	system.Share_An_Fp(big.NewInt(1))
+}
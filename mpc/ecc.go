@@ -23,6 +23,9 @@ type Share_G struct {
 // and then computes the shares using the Share_A_G_Offline function.
 // The generated shares are returned as a pointer to a slice of Share_G.
 func (system *ECCShareSystem) RandomShareG() *[]Share_G {
+	if system.alpha == nil {
+		return system.EXP_P_G_1(system.Curve.Gx, system.Curve.Gy, system.randomShareFp())
+	}
 	rX, rY := system.RandomG()
 	rshares := system.Share_A_G_Offline(rX, rY)
 	return rshares
@@ -46,14 +49,14 @@ func (system *ECCShareSystem) Share_A_G(elementX, elementY *big.Int) *[]Share_G
 	ori_valueX := new(big.Int).Set(elementX)
 	ori_valueY := new(big.Int).Set(elementY)
 	wg.Add(2)
-	go system.Send(&wg, ori_valueX.Bytes())
-	go system.Send(&wg, ori_valueY.Bytes())
+	go system.Send(&wg, system.Partynum, ori_valueX.Bytes())
+	go system.Send(&wg, system.Partynum, ori_valueY.Bytes())
 	DeltaX, DeltaY := system.RandomG()
 	wg.Add(2)
 	go system.BroadcastN(&wg, DeltaX.Bytes())
 	go system.BroadcastN(&wg, DeltaY.Bytes())
 	GamaX, GamaY := system.Curve.Add(ori_valueX, ori_valueY, DeltaX, DeltaY)
-	GamaX, GamaY = system.Curve.ScalarMult(GamaX, GamaY, system.alpha.Bytes())
+	GamaX, GamaY = system.Curve.ScalarMult(GamaX, GamaY, system.dealerKey().Bytes())
 	shares := make([]Share_G, system.Partynum)
 	for i := 0; i < system.Partynum; i++ {
 		shares[i].Index = i
@@ -71,10 +74,10 @@ func (system *ECCShareSystem) Share_A_G(elementX, elementY *big.Int) *[]Share_G
 			shares[i].GamaY = GamaY
 		}
 		wg.Add(4)
-		go system.Send(&wg, shares[i].ShareX.Bytes())
-		go system.Send(&wg, shares[i].ShareY.Bytes())
-		go system.Send(&wg, shares[i].GamaX.Bytes())
-		go system.Send(&wg, shares[i].GamaY.Bytes())
+		go system.Send(&wg, i, shares[i].ShareX.Bytes())
+		go system.Send(&wg, i, shares[i].ShareY.Bytes())
+		go system.Send(&wg, i, shares[i].GamaX.Bytes())
+		go system.Send(&wg, i, shares[i].GamaY.Bytes())
 	}
 	wg.Wait()
 	return &shares
@@ -95,7 +98,7 @@ func (system *ECCShareSystem) Share_A_G_Offline(elementX, elementY *big.Int) *[]
 	go system.OfflineBroadcastN(&wg, DeltaX.Bytes())
 	go system.OfflineBroadcastN(&wg, DeltaY.Bytes())
 	GamaX, GamaY := system.Curve.Add(ori_valueX, ori_valueY, DeltaX, DeltaY)
-	GamaX, GamaY = system.Curve.ScalarMult(GamaX, GamaY, system.alpha.Bytes())
+	GamaX, GamaY = system.Curve.ScalarMult(GamaX, GamaY, system.dealerKey().Bytes())
 	shares := make([]Share_G, system.Partynum)
 	for i := 0; i < system.Partynum; i++ {
 		shares[i].Index = i
@@ -113,10 +116,10 @@ func (system *ECCShareSystem) Share_A_G_Offline(elementX, elementY *big.Int) *[]
 			shares[i].GamaY = GamaY
 		}
 		wg.Add(4)
-		go system.OfflineSend(&wg, shares[i].ShareX.Bytes())
-		go system.OfflineSend(&wg, shares[i].ShareY.Bytes())
-		go system.OfflineSend(&wg, shares[i].GamaX.Bytes())
-		go system.OfflineSend(&wg, shares[i].GamaY.Bytes())
+		go system.OfflineSend(&wg, i, shares[i].ShareX.Bytes())
+		go system.OfflineSend(&wg, i, shares[i].ShareY.Bytes())
+		go system.OfflineSend(&wg, i, shares[i].GamaX.Bytes())
+		go system.OfflineSend(&wg, i, shares[i].GamaY.Bytes())
 	}
 	wg.Wait()
 	return &shares
@@ -198,12 +201,12 @@ func (system *ECCShareSystem) shareAdd_G(shares1, shares2 Share_G) Share_G {
 // It takes in an array of shares `shares1`, representing the shares of the first operand,
 // and two scalar values `scalarX` and `scalarY`, representing the plaintext values to be added.
 // It returns a pointer to an array of shares, where each share represents the result of the addition.
-// The function internally generates a second set of shares using the `Share_A_G_Offline` function,
+// The function internally generates a second set of shares using the `constG` function,
 // and then performs the share-wise addition of the two sets of shares using the `shareAdd_G` function.
 // The resulting shares are stored in a new array and returned as a pointer.
 func (system *ECCShareSystem) SecAddPlaintext_G(shares1 []Share_G, scalarX, scalarY *big.Int) *[]Share_G {
 	shares := make([]Share_G, system.Partynum)
-	shares2 := system.Share_A_G_Offline(scalarX, scalarY)
+	shares2 := system.constG(scalarX, scalarY)
 	for i := 0; i < system.Partynum; i++ {
 		shares[i] = system.shareAdd_G(shares1[i], (*shares2)[i])
 	}
@@ -239,7 +242,7 @@ func (system *ECCShareSystem) SecSub_G(shares1, shares2 []Share_G) *[]Share_G {
 
 func (system *ECCShareSystem) SecSubPlaintext_G(shares1 []Share_G, scalarX, scalarY *big.Int) *[]Share_G {
 	shares := make([]Share_G, system.Partynum)
-	shares2 := system.Share_A_G_Offline(scalarX, scalarY)
+	shares2 := system.constG(scalarX, scalarY)
 	for i := 0; i < system.Partynum; i++ {
 		shares[i] = system.shareSub_G(shares1[i], (*shares2)[i])
 	}
@@ -247,7 +250,10 @@ func (system *ECCShareSystem) SecSubPlaintext_G(shares1 []Share_G, scalarX, scal
 }
 
 func (system *ECCShareSystem) EXP_S_G(hshares []Share_G, xshares []Share_Fp) *[]Share_G {
-	sharesA, sharesB, sharesC := system.GenTriplets()
+	sharesA, sharesB, sharesC, err := system.GenTriplets()
+	if err != nil {
+		panic(err)
+	}
 	sharesgB := system.EXP_P_G_1(system.Curve.Gx, system.Curve.Gy, sharesB)
 	sharesgC := system.EXP_P_G_1(system.Curve.Gx, system.Curve.Gy, sharesC)
 	XsubAshares := system.SecSub(xshares, *sharesA)
@@ -275,8 +281,8 @@ func (system *ECCShareSystem) HalfOpenG(shares []Share_G) (*big.Int, *big.Int) {
 			ori_valueX, ori_valueY = system.Curve.Add(ori_valueX, ori_valueY, shares[i].ShareX, shares[i].ShareY)
 		}
 		wg.Add(2)
-		go system.Broadcast(&wg, shares[i].ShareX.Bytes())
-		go system.Broadcast(&wg, shares[i].ShareY.Bytes())
+		go system.Broadcast(&wg, i, shares[i].ShareX.Bytes())
+		go system.Broadcast(&wg, i, shares[i].ShareY.Bytes())
 	}
 	wg.Wait()
 	return ori_valueX, ori_valueY
@@ -294,10 +300,10 @@ func (system *ECCShareSystem) MacCheckG(shares []Share_G, res_valueX, res_valueY
 		deltaX, deltaY = system.Curve.Add(shares[i].GamaX, shares[i].GamaY, deltaX, new(big.Int).Mod(new(big.Int).Neg(deltaY), system.Curve.P))
 		commit, r := Com(deltaX.Bytes())
 		wg.Add(4)
-		go system.Broadcast(&wg, deltaX.Bytes())
-		go system.Broadcast(&wg, deltaX.Bytes())
-		go system.Broadcast(&wg, commit)
-		go system.Broadcast(&wg, r.Bytes())
+		go system.Broadcast(&wg, i, deltaX.Bytes())
+		go system.Broadcast(&wg, i, deltaX.Bytes())
+		go system.Broadcast(&wg, i, commit)
+		go system.Broadcast(&wg, i, r.Bytes())
 		opencommit := OpenComit(deltaX.Bytes(), commit, r)
 		if !opencommit {
 			return false
@@ -320,8 +326,8 @@ func (system *ECCShareSystem) OpenG(shares []Share_G) (*big.Int, *big.Int, bool)
 			ori_valueX, ori_valueY = system.Curve.Add(ori_valueX, ori_valueY, shares[i].ShareX, shares[i].ShareY)
 		}
 		wg.Add(2)
-		go system.Broadcast(&wg, shares[i].ShareX.Bytes())
-		go system.Broadcast(&wg, shares[i].ShareY.Bytes())
+		go system.Broadcast(&wg, i, shares[i].ShareX.Bytes())
+		go system.Broadcast(&wg, i, shares[i].ShareY.Bytes())
 	}
 	wg.Wait()
 	chk := system.MacCheckG(shares, ori_valueX, ori_valueY)
@@ -332,12 +338,12 @@ func (system *ECCShareSystem) Share_An_Fp(element *big.Int) *[]Share_Fp {
 	var wg sync.WaitGroup
 	ori_value := new(big.Int).Set(element)
 	wg.Add(1)
-	go system.Send(&wg, ori_value.Bytes())
+	go system.Send(&wg, system.Partynum, ori_value.Bytes())
 	Delta, _ := rand.Int(rand.Reader, system.Order)
 	wg.Add(1)
 	go system.BroadcastN(&wg, Delta.Bytes())
 	Gama := new(big.Int).Add(ori_value, Delta)
-	Gama = Gama.Mul(system.alpha, Gama)
+	Gama = Gama.Mul(system.dealerKey(), Gama)
 	Gama = Gama.Mod(Gama, system.Order)
 	shares := make([]Share_Fp, system.Partynum)
 	for i := 0; i < system.Partynum; i++ {
@@ -355,8 +361,8 @@ func (system *ECCShareSystem) Share_An_Fp(element *big.Int) *[]Share_Fp {
 			shares[i].Gama = Gama
 		}
 		wg.Add(2)
-		go system.Send(&wg, shares[i].Share.Bytes())
-		go system.Send(&wg, shares[i].Gama.Bytes())
+		go system.Send(&wg, i, shares[i].Share.Bytes())
+		go system.Send(&wg, i, shares[i].Gama.Bytes())
 	}
 	wg.Wait()
 	return &shares
@@ -369,7 +375,7 @@ func (system *ECCShareSystem) Share_An_Fp_Offline(element *big.Int) *[]Share_Fp
 	wg.Add(1)
 	go system.OfflineBroadcastN(&wg, Delta.Bytes())
 	Gama := new(big.Int).Add(ori_value, Delta)
-	Gama = Gama.Mul(system.alpha, Gama)
+	Gama = Gama.Mul(system.dealerKey(), Gama)
 	Gama = Gama.Mod(Gama, system.Order)
 	shares := make([]Share_Fp, system.Partynum)
 	for i := 0; i < system.Partynum; i++ {
@@ -387,16 +393,37 @@ func (system *ECCShareSystem) Share_An_Fp_Offline(element *big.Int) *[]Share_Fp
 			shares[i].Gama = Gama
 		}
 		wg.Add(2)
-		go system.OfflineSend(&wg, shares[i].Share.Bytes())
-		go system.OfflineSend(&wg, shares[i].Gama.Bytes())
+		go system.OfflineSend(&wg, i, shares[i].Share.Bytes())
+		go system.OfflineSend(&wg, i, shares[i].Gama.Bytes())
 	}
 	wg.Wait()
 	return &shares
 }
 
-func (system *ECCShareSystem) RandomShareFp() *[]Share_Fp {
+// RandomShareFp has the dealer sample the value, or with a distributed MAC
+// key every party samples its share and the OfflinePhase MACs them.
+func (system *ECCShareSystem) RandomShareFp() (*[]Share_Fp, error) {
+	if system.alpha == nil {
+		values, err := system.Offline.random()
+		if err != nil {
+			return nil, err
+		}
+		shares, err := system.Offline.authenticate(values)
+		if err != nil {
+			return nil, err
+		}
+		return &shares, nil
+	}
 	r, _ := rand.Int(rand.Reader, system.Order)
 	rshares := system.Share_An_Fp_Offline(r)
+	return rshares, nil
+}
+
+func (system *ECCShareSystem) randomShareFp() *[]Share_Fp {
+	rshares, err := system.RandomShareFp()
+	if err != nil {
+		panic(err)
+	}
 	return rshares
 }
 
@@ -415,7 +442,7 @@ func (system *ECCShareSystem) shareAdd(shares1, shares2 Share_Fp) Share_Fp {
 
 func (system *ECCShareSystem) SecAddPlaintext(shares1 []Share_Fp, scalar *big.Int) *[]Share_Fp {
 	shares := make([]Share_Fp, system.Partynum)
-	shares2 := system.Share_An_Fp(scalar)
+	shares2 := system.constFp(scalar)
 	for i := 0; i < system.Partynum; i++ {
 		shares[i] = system.shareAdd(shares1[i], (*shares2)[i])
 	}
@@ -432,7 +459,7 @@ func (system *ECCShareSystem) SecAdd(shares1, shares2 []Share_Fp) *[]Share_Fp {
 
 func (system *ECCShareSystem) SecSubPlaintext(shares1 []Share_Fp, scalar *big.Int) *[]Share_Fp {
 	shares := make([]Share_Fp, system.Partynum)
-	shares2 := system.Share_An_Fp(scalar)
+	shares2 := system.constFp(scalar)
 	for i := 0; i < system.Partynum; i++ {
 		shares[i] = system.shareSub(shares1[i], (*shares2)[i])
 	}
@@ -483,14 +510,17 @@ func (system *ECCShareSystem) SecMulPlaintext(shares1 []Share_Fp, scalar *big.In
 
 func (system *ECCShareSystem) SecMul(shares1, shares2 []Share_Fp) *[]Share_Fp {
 	shares := make([]Share_Fp, system.Partynum)
-	sharesA, sharesB, sharesC := system.GenTriplets()
+	sharesA, sharesB, sharesC, err := system.GenTriplets()
+	if err != nil {
+		panic(err)
+	}
 	eshares := system.SecSub(shares1, *sharesA)
 	fshares := system.SecSub(shares2, *sharesB)
 	e := system.HalfOpenFp(*eshares)
 	f := system.HalfOpenFp(*fshares)
 	ef := new(big.Int).Mul(e, f)
 	ef = ef.Mod(ef, system.Order)
-	efshares := system.Share_An_Fp(ef)
+	efshares := system.constFp(ef)
 	for i := 0; i < system.Partynum; i++ {
 		beshare := system.shareMulPlaintext((*sharesB)[i], e)
 		afshare := system.shareMulPlaintext((*sharesA)[i], f)
@@ -502,7 +532,10 @@ func (system *ECCShareSystem) SecMul(shares1, shares2 []Share_Fp) *[]Share_Fp {
 }
 
 func (system *ECCShareSystem) SecSquare(shares1 []Share_Fp) *[]Share_Fp {
-	sharesA, sharesB := system.GenSquarePair()
+	sharesA, sharesB, err := system.GenSquarePair()
+	if err != nil {
+		panic(err)
+	}
 	eshares := system.SecSub(shares1, *sharesA)
 	e := system.HalfOpenFp(*eshares)
 	e2 := new(big.Int).Mul(e, two)
@@ -519,7 +552,7 @@ func (system *ECCShareSystem) HalfOpenFp(shares []Share_Fp) *big.Int {
 	var wg sync.WaitGroup
 	for i := 0; i < system.Partynum; i++ {
 		wg.Add(1)
-		go system.Broadcast(&wg, shares[i].Share.Bytes())
+		go system.Broadcast(&wg, i, shares[i].Share.Bytes())
 		ori_value = ori_value.Add(ori_value, shares[i].Share)
 	}
 	wg.Wait()
@@ -538,9 +571,9 @@ func (system *ECCShareSystem) MacCheckFp(shares []Share_Fp, res_value *big.Int)
 		delta = delta.Sub(shares[i].Gama, delta)
 		commit, r := Com(delta.Bytes())
 		wg.Add(3)
-		go system.Broadcast(&wg, delta.Bytes())
-		go system.Broadcast(&wg, commit)
-		go system.Broadcast(&wg, r.Bytes())
+		go system.Broadcast(&wg, i, delta.Bytes())
+		go system.Broadcast(&wg, i, commit)
+		go system.Broadcast(&wg, i, r.Bytes())
 		opencommit := OpenComit(delta.Bytes(), commit, r)
 		if !opencommit {
 			return false
@@ -557,7 +590,7 @@ func (system *ECCShareSystem) OpenFp(shares []Share_Fp) (*big.Int, bool) {
 	var wg sync.WaitGroup
 	for i := 0; i < system.Partynum; i++ {
 		wg.Add(1)
-		go system.Broadcast(&wg, shares[i].Share.Bytes())
+		go system.Broadcast(&wg, i, shares[i].Share.Bytes())
 		ori_value = ori_value.Add(ori_value, shares[i].Share)
 	}
 	wg.Wait()
@@ -0,0 +1,39 @@
+package mpc
+
+import (
+	"errors"
+	"math/big"
+
+	curve "github.com/Oryx/curve"
+	"github.com/Oryx/network"
+)
+
+var ErrBadShare = errors.New("mpc: malformed share")
+
+func MarshalFp(share Share_Fp) []byte {
+	return network.Pack(share.Share.Bytes(), share.Gama.Bytes(), share.Delta.Bytes())
+}
+
+func UnmarshalFp(msg []byte, index int) (Share_Fp, error) {
+	var share Share_Fp
+	parts, err := network.Unpack(msg)
+	if err != nil {
+		return share, err
+	}
+	if len(parts) != 3 {
+		return share, ErrBadShare
+	}
+	share.Share = new(big.Int).SetBytes(parts[0])
+	share.Gama = new(big.Int).SetBytes(parts[1])
+	share.Delta = new(big.Int).SetBytes(parts[2])
+	share.Index = index
+	return share, nil
+}
+
+func MarshalGT(share Share_GT) []byte {
+	return marshalShare(share)
+}
+
+func UnmarshalGT(msg []byte, index int) (Share_GT, error) {
+	return unmarshalShare[curve.GT](msg, index)
+}
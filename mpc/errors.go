@@ -0,0 +1,77 @@
+package mpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrMacCheckFailed  = errors.New("mpc: MAC check failed")
+	ErrBandwidth       = errors.New("mpc: bandwidth must be positive")
+	ErrBitLength       = errors.New("mpc: bit length too large for the field")
+	ErrNoInverse       = errors.New("mpc: zero has no inverse")
+	ErrDistributedKey  = errors.New("mpc: the MAC key is distributed, so the dealer cannot share values")
+	ErrNoOfflinePhase  = errors.New("mpc: the offline phase is not set up")
+	ErrInputProof      = errors.New("mpc: input sharing proof failed")
+	ErrDivisor         = errors.New("mpc: divisor must be finite and nonzero")
+	ErrFixedPointRange = errors.New("mpc: fixed-point value is infinite, NaN or out of range")
+)
+
+// MacCheckError reports an opening whose MAC check failed. Op names the
+// opening, Party and Element locate the input element it belongs to. Both are
+// -1 when the failure cannot be pinned to one element, e.g. when the opening
+// that failed a DeferredMacCheck was not made through OpenBatch.At. Cheater is the party that sent
+// a wrong share or check value, as found in the identifiable-abort mode, and
+// -1 when it is not known.
+type MacCheckError struct {
+	Op      string
+	Party   int
+	Element int
+	Cheater int
+}
+
+func (e *MacCheckError) Error() string {
+	msg := fmt.Sprintf("mpc: MAC check failed in %s", e.Op)
+	if e.Element >= 0 {
+		msg += fmt.Sprintf(" for element %d of party %d", e.Element, e.Party)
+	}
+	if e.Cheater >= 0 {
+		msg += fmt.Sprintf(", caused by party %d", e.Cheater)
+	}
+	return msg
+}
+
+func (e *MacCheckError) Unwrap() error {
+	return ErrMacCheckFailed
+}
+
+// InputProofError reports an input whose shares did not match the
+// commitment of its owner, as found by party Party.
+type InputProofError struct {
+	Owner int
+	Party int
+}
+
+func (e *InputProofError) Error() string {
+	return fmt.Sprintf("mpc: party %d rejected the input shares of party %d", e.Party, e.Owner)
+}
+
+func (e *InputProofError) Unwrap() error {
+	return ErrInputProof
+}
+
+// Recover is deferred in protocol goroutines. SecMul, SecSquare and the
+// other operations that take a triple or square pair, RandomBit,
+// BitDecompose and TruncPr cannot return an error and panic with it instead
+// when the preprocessing or an opening fails; Recover turns such a panic into
+// a cancellation of the protocol with that error. Other panics are passed on.
+func Recover(cancel context.CancelCauseFunc) {
+	if r := recover(); r != nil {
+		err, ok := r.(error)
+		if !ok {
+			panic(r)
+		}
+		cancel(err)
+	}
+}
@@ -0,0 +1,193 @@
+package mpc
+
+import (
+	"crypto/rand"
+	"math"
+	"math/big"
+)
+
+// A fixed-point value x is encoded as round(x * 2^FixedFrac) in Fp, negative
+// values as p minus their magnitude. Encodings must stay below 2^(FixedBits-1)
+// in magnitude, also for the product of two values before it is truncated.
+const (
+	FixedBits = 96
+	FixedFrac = 20
+)
+
+// FixedPoint is a sharing of the encoding of a fixed-point value; each party
+// holds the Share_Fp at its index.
+type FixedPoint []Share_Fp
+
+// EncodeFixedPoint returns the encoding of x in Fp. It returns
+// ErrFixedPointRange when x is infinite or NaN or its encoding is not below
+// 2^(FixedBits-1) in magnitude.
+func (system *ShareSystem) EncodeFixedPoint(x float64) (*big.Int, error) {
+	if math.IsInf(x, 0) || math.IsNaN(x) {
+		return nil, ErrFixedPointRange
+	}
+	r := new(big.Rat).SetFloat64(x)
+	r.Mul(r, new(big.Rat).SetInt(new(big.Int).Lsh(one, FixedFrac)))
+	v := roundRat(r)
+	if !fixedInRange(v) {
+		return nil, ErrFixedPointRange
+	}
+	return v.Mod(v, system.Order), nil
+}
+
+// fixedInRange reports whether v is below 2^(FixedBits-1) in magnitude.
+func fixedInRange(v *big.Int) bool {
+	return new(big.Int).Abs(v).Cmp(new(big.Int).Lsh(one, FixedBits-1)) < 0
+}
+
+// roundRat rounds r to the nearest integer, halves away from zero.
+func roundRat(r *big.Rat) *big.Int {
+	num := new(big.Int).Abs(r.Num())
+	v, rem := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+	if rem.Lsh(rem, 1).Cmp(r.Denom()) >= 0 {
+		v.Add(v, one)
+	}
+	if r.Sign() < 0 {
+		v.Neg(v)
+	}
+	return v
+}
+
+// DecodeFixedPoint returns the value an opened encoding stands for.
+func (system *ShareSystem) DecodeFixedPoint(v *big.Int) *big.Rat {
+	x := new(big.Int).Mod(v, system.Order)
+	if x.Cmp(new(big.Int).Rsh(system.Order, 1)) > 0 {
+		x.Sub(x, system.Order)
+	}
+	return new(big.Rat).SetFrac(x, new(big.Int).Lsh(one, FixedFrac))
+}
+
+// Share_A_FixedPoint returns the errors of EncodeFixedPoint.
+func (system *ShareSystem) Share_A_FixedPoint(x float64) (*FixedPoint, error) {
+	v, err := system.EncodeFixedPoint(x)
+	if err != nil {
+		return nil, err
+	}
+	shares := FixedPoint(*system.Share_An_Fp(v))
+	return &shares, nil
+}
+
+func (system *ShareSystem) SecAdd_FixedPoint(shares1, shares2 FixedPoint) *FixedPoint {
+	shares := FixedPoint(*system.SecAdd(shares1, shares2))
+	return &shares
+}
+
+func (system *ShareSystem) SecSub_FixedPoint(shares1, shares2 FixedPoint) *FixedPoint {
+	shares := FixedPoint(*system.SecSub(shares1, shares2))
+	return &shares
+}
+
+// SecAddPlaintext_FixedPoint returns the errors of EncodeFixedPoint.
+func (system *ShareSystem) SecAddPlaintext_FixedPoint(shares1 FixedPoint, scalar float64) (*FixedPoint, error) {
+	v, err := system.EncodeFixedPoint(scalar)
+	if err != nil {
+		return nil, err
+	}
+	shares := FixedPoint(*system.SecAddPlaintext(shares1, v))
+	return &shares, nil
+}
+
+// SecMul_FixedPoint multiplies with SecMul and truncates the product, which
+// carries 2*FixedFrac fractional bits, back with TruncPr.
+func (system *ShareSystem) SecMul_FixedPoint(shares1, shares2 FixedPoint) *FixedPoint {
+	shares := FixedPoint(*system.TruncPr(*system.SecMul(shares1, shares2), FixedFrac))
+	return &shares
+}
+
+// SecMulPlaintext_FixedPoint returns the errors of EncodeFixedPoint.
+func (system *ShareSystem) SecMulPlaintext_FixedPoint(shares1 FixedPoint, scalar float64) (*FixedPoint, error) {
+	v, err := system.EncodeFixedPoint(scalar)
+	if err != nil {
+		return nil, err
+	}
+	product := system.SecMulPlaintext(shares1, v)
+	shares := FixedPoint(*system.TruncPr(*product, FixedFrac))
+	return &shares, nil
+}
+
+// SecDivPlaintext_FixedPoint divides by a public constant d. It multiplies
+// with round(2^(2*FixedFrac)/d), which keeps FixedFrac more bits of 1/d than
+// its encoding would, and truncates the product by 2*FixedFrac bits. The
+// product has to stay below 2^(FixedBits-1), so the quotient must stay below
+// 2^(FixedBits-1-3*FixedFrac) in magnitude. It returns ErrDivisor when d is
+// zero, infinite or NaN, and ErrFixedPointRange when d is so small that the
+// multiplier is not below 2^(FixedBits-1).
+func (system *ShareSystem) SecDivPlaintext_FixedPoint(shares1 FixedPoint, d float64) (*FixedPoint, error) {
+	if d == 0 || math.IsInf(d, 0) || math.IsNaN(d) {
+		return nil, ErrDivisor
+	}
+	m := 2 * FixedFrac
+	r := new(big.Rat).SetInt(new(big.Int).Lsh(one, uint(m)))
+	r.Quo(r, new(big.Rat).SetFloat64(d))
+	c := roundRat(r)
+	if !fixedInRange(c) {
+		return nil, ErrFixedPointRange
+	}
+	product := system.SecMulPlaintext(shares1, c.Mod(c, system.Order))
+	shares := FixedPoint(*system.TruncPr(*product, m))
+	return &shares, nil
+}
+
+// TruncPr returns a sharing of x / 2^m, rounded up or down at random with
+// probability given by the dropped bits, for x below 2^(FixedBits-1) in
+// magnitude. x is shifted to be positive and masked by a random value r whose
+// low m bits r1 are shared separately; the masked value c is opened, and
+// x - (c mod 2^m - r1) is a multiple of 2^m up to the rounding. The mask
+// hides x with statSecurity bits. It panics with a *MacCheckError when the
+// opening fails.
+func (system *ShareSystem) TruncPr(shares1 []Share_Fp, m int) *[]Share_Fp {
+	r1shares, rshares := system.truncMask(m)
+	b := system.SecAddPlaintext(shares1, new(big.Int).Lsh(one, FixedBits-1))
+	c, chk := system.OpenFp(*system.SecAdd(*b, *rshares))
+	if !chk {
+		panic(&MacCheckError{Op: "TruncPr", Party: -1, Element: -1, Cheater: -1})
+	}
+	low := new(big.Int).Mod(c, new(big.Int).Lsh(one, uint(m)))
+	shares := system.SecSubPlaintext(shares1, low)
+	shares = system.SecAdd(*shares, *r1shares)
+	inv := new(big.Int).ModInverse(new(big.Int).Lsh(one, uint(m)), system.Order)
+	return system.SecMulPlaintext(*shares, inv)
+}
+
+// truncMask returns sharings of a random r1 below 2^m and of r = r2*2^m + r1
+// for TruncPr. The dealer samples r2 below 2^(FixedBits+statSecurity-m).
+// With a distributed MAC key r1 is put together from m RandomBits, and every
+// party picks its own share of r2 below that bound, which the OfflinePhase
+// MACs; r2 is then below Partynum times the bound, which still leaves c far
+// below the order.
+func (system *ShareSystem) truncMask(m int) (*[]Share_Fp, *[]Share_Fp) {
+	bound := new(big.Int).Lsh(one, uint(FixedBits+statSecurity-m))
+	if system.alpha != nil {
+		r1, _ := rand.Int(rand.Reader, new(big.Int).Lsh(one, uint(m)))
+		r2, _ := rand.Int(rand.Reader, bound)
+		r1shares := system.Share_An_Fp_Offline(r1)
+		rshares := system.Share_An_Fp_Offline(new(big.Int).Add(new(big.Int).Lsh(r2, uint(m)), r1))
+		return r1shares, rshares
+	}
+	r1shares := system.constFp(zero)
+	for i := 0; i < m; i++ {
+		bit := system.SecMulPlaintext(*system.RandomBit(), new(big.Int).Lsh(one, uint(i)))
+		r1shares = system.SecAdd(*r1shares, *bit)
+	}
+	values := make([]*big.Int, system.Partynum)
+	for i := range values {
+		values[i], _ = rand.Int(rand.Reader, bound)
+	}
+	r2shares, err := system.Offline.authenticate(values)
+	if err != nil {
+		panic(err)
+	}
+	rshares := system.SecMulPlaintext(r2shares, new(big.Int).Lsh(one, uint(m)))
+	return r1shares, system.SecAdd(*rshares, *r1shares)
+}
+
+// OpenFixedPoint opens a fixed-point sharing; Float64 on the result gives the
+// nearest float.
+func (system *ShareSystem) OpenFixedPoint(shares FixedPoint) (*big.Rat, bool) {
+	v, chk := system.OpenFp(shares)
+	return system.DecodeFixedPoint(v), chk
+}
@@ -0,0 +1,91 @@
+package mpc
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+)
+
+// ulp is one unit in the last place of the fixed-point encoding, by which
+// the probabilistic rounding of TruncPr may be off.
+var ulp = 1.0 / (1 << FixedFrac)
+
+func TestTruncPr(t *testing.T) {
+	system := SystemInit(3)
+	for _, tc := range []struct {
+		x int64
+		m int
+	}{
+		{0, 8},
+		{1 << 20, 20},
+		{12345678, 4},
+		{-12345678, 4},
+		{-1 << 30, 10},
+	} {
+		x := new(big.Int).Mod(big.NewInt(tc.x), system.Order)
+		v, chk := system.OpenFp(*system.TruncPr(*system.Share_An_Fp(x), tc.m))
+		if !chk {
+			t.Fatalf("MAC check failed for %d >> %d", tc.x, tc.m)
+		}
+		if v.Cmp(new(big.Int).Rsh(system.Order, 1)) > 0 {
+			v.Sub(v, system.Order)
+		}
+		floor := tc.x >> tc.m
+		if got := v.Int64(); got != floor && got != floor+1 {
+			t.Fatalf("got %d for %d >> %d, want %d or %d", got, tc.x, tc.m, floor, floor+1)
+		}
+	}
+}
+
+func TestSecDivPlaintext_FixedPoint(t *testing.T) {
+	system := SystemInit(3)
+	for _, tc := range []struct {
+		x, d float64
+	}{
+		{0, 3},
+		{10, 4},
+		{-10, 4},
+		{-7.5, -2.5},
+		{1234.5, 0.5},
+		{1, 1000},
+	} {
+		x, err := system.Share_A_FixedPoint(tc.x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		q, err := system.SecDivPlaintext_FixedPoint(*x, tc.d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, chk := system.OpenFixedPoint(*q)
+		if !chk {
+			t.Fatalf("MAC check failed for %v / %v", tc.x, tc.d)
+		}
+		got, _ := r.Float64()
+		if math.Abs(got-tc.x/tc.d) > 2*ulp {
+			t.Fatalf("got %v for %v / %v, want %v", got, tc.x, tc.d, tc.x/tc.d)
+		}
+	}
+}
+
+func TestSecDivPlaintext_FixedPointErrors(t *testing.T) {
+	system := SystemInit(3)
+	x, err := system.Share_A_FixedPoint(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		d    float64
+		want error
+	}{
+		{0, ErrDivisor},
+		{math.Inf(1), ErrDivisor},
+		{math.NaN(), ErrDivisor},
+		{1e-30, ErrFixedPointRange},
+	} {
+		if _, err := system.SecDivPlaintext_FixedPoint(*x, tc.d); !errors.Is(err, tc.want) {
+			t.Fatalf("got %v for d = %v, want %v", err, tc.d, tc.want)
+		}
+	}
+}
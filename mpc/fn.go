@@ -18,7 +18,7 @@ func (system *RSAShareSystem) Share_An_Fn(element *big.Int) *[]Share_Fn {
 	ori_value := new(big.Int).Set(element)
 	Delta, _ := rand.Int(rand.Reader, system.Order)
 	wg.Add(2)
-	go system.Send(&wg, ori_value.Bytes())
+	go system.Send(&wg, system.Partynum, ori_value.Bytes())
 	go system.BroadcastN(&wg, Delta.Bytes())
 	Gama := new(big.Int).Add(ori_value, Delta)
 	Gama = Gama.Mul(system.alpha, Gama)
@@ -39,8 +39,8 @@ func (system *RSAShareSystem) Share_An_Fn(element *big.Int) *[]Share_Fn {
 			shares[i].Gama = Gama
 		}
 		wg.Add(2)
-		go system.Send(&wg, shares[i].Share.Bytes())
-		go system.Send(&wg, shares[i].Gama.Bytes())
+		go system.Send(&wg, i, shares[i].Share.Bytes())
+		go system.Send(&wg, i, shares[i].Gama.Bytes())
 	}
 	wg.Wait()
 	return &shares
@@ -71,8 +71,8 @@ func (system *RSAShareSystem) Share_An_Fn_Offline(element *big.Int) *[]Share_Fn
 			shares[i].Gama = Gama
 		}
 		wg.Add(2)
-		go system.OfflineSend(&wg, shares[i].Share.Bytes())
-		go system.OfflineSend(&wg, shares[i].Gama.Bytes())
+		go system.OfflineSend(&wg, i, shares[i].Share.Bytes())
+		go system.OfflineSend(&wg, i, shares[i].Gama.Bytes())
 	}
 	wg.Wait()
 	return &shares
@@ -203,7 +203,7 @@ func (system *RSAShareSystem) HalfOpenFn(shares []Share_Fn) *big.Int {
 	ori_value := big.NewInt(0)
 	for i := 0; i < system.Partynum; i++ {
 		wg.Add(1)
-		go system.Broadcast(&wg, shares[i].Share.Bytes())
+		go system.Broadcast(&wg, i, shares[i].Share.Bytes())
 		ori_value = ori_value.Add(ori_value, shares[i].Share)
 	}
 	ori_value = ori_value.Mod(ori_value, system.Order)
@@ -222,9 +222,9 @@ func (system *RSAShareSystem) MacCheckFn(shares []Share_Fn, res_value *big.Int)
 		delta = delta.Sub(shares[i].Gama, delta)
 		commit, r := Com(delta.Bytes())
 		wg.Add(3)
-		go system.Broadcast(&wg, commit)
-		go system.Broadcast(&wg, r.Bytes())
-		go system.Broadcast(&wg, delta.Bytes())
+		go system.Broadcast(&wg, i, commit)
+		go system.Broadcast(&wg, i, r.Bytes())
+		go system.Broadcast(&wg, i, delta.Bytes())
 		opencommit := OpenComit(delta.Bytes(), commit, r)
 		if !opencommit {
 			return false
@@ -241,7 +241,7 @@ func (system *RSAShareSystem) OpenFn(shares []Share_Fn) (*big.Int, bool) {
 	var wg sync.WaitGroup
 	for i := 0; i < system.Partynum; i++ {
 		wg.Add(1)
-		go system.Broadcast(&wg, shares[i].Share.Bytes())
+		go system.Broadcast(&wg, i, shares[i].Share.Bytes())
 		ori_value = ori_value.Add(ori_value, shares[i].Share)
 	}
 	ori_value = ori_value.Mod(ori_value, system.Order)
@@ -41,7 +41,7 @@ func (system *RSAShareSystem) Share_An_Fn_Mul(element *big.Int) *[]Share_Fn {
 	ori_value := new(big.Int).Set(element)
 	Delta, _ := rand.Int(rand.Reader, system.Order)
 	wg.Add(2)
-	go system.Send(&wg, ori_value.Bytes())
+	go system.Send(&wg, system.Partynum, ori_value.Bytes())
 	go system.BroadcastN(&wg, Delta.Bytes())
 	Gama := new(big.Int).Mul(ori_value, Delta)
 	Gama = Gama.Exp(Gama, system.alpha, system.Order)
@@ -63,8 +63,8 @@ func (system *RSAShareSystem) Share_An_Fn_Mul(element *big.Int) *[]Share_Fn {
 			shares[i].Gama = Gama
 		}
 		wg.Add(2)
-		go system.Send(&wg, shares[i].Share.Bytes())
-		go system.Send(&wg, shares[i].Gama.Bytes())
+		go system.Send(&wg, i, shares[i].Share.Bytes())
+		go system.Send(&wg, i, shares[i].Gama.Bytes())
 	}
 	wg.Wait()
 	return &shares
@@ -105,8 +105,8 @@ func (system *RSAShareSystem) Share_An_Fn_Mul_Offline(element *big.Int) *[]Share
 			shares[i].Gama = Gama
 		}
 		wg.Add(2)
-		go system.OfflineSend(&wg, shares[i].Share.Bytes())
-		go system.OfflineSend(&wg, shares[i].Gama.Bytes())
+		go system.OfflineSend(&wg, i, shares[i].Share.Bytes())
+		go system.OfflineSend(&wg, i, shares[i].Gama.Bytes())
 	}
 	wg.Wait()
 	return &shares
@@ -119,7 +119,7 @@ func (system *RSAShareSystem) HalfOpenFn_Mul(shares []Share_Fn) *big.Int {
 	ori_value := big.NewInt(1)
 	for i := 0; i < system.Partynum; i++ {
 		wg.Add(1)
-		go system.Broadcast(&wg, shares[i].Share.Bytes())
+		go system.Broadcast(&wg, i, shares[i].Share.Bytes())
 		ori_value = ori_value.Mul(ori_value, shares[i].Share)
 	}
 	ori_value = ori_value.Mod(ori_value, system.Order)
@@ -139,12 +139,12 @@ func (system *RSAShareSystem) MacCheckFn_Mul(shares []Share_Fn, res_value *big.I
 		commit1, r1 := Com(delta.Bytes())
 		commit2, r2 := Com(shares[i].Gama.Bytes())
 		wg.Add(6)
-		go system.Broadcast(&wg, commit1)
-		go system.Broadcast(&wg, commit2)
-		go system.Broadcast(&wg, r1.Bytes())
-		go system.Broadcast(&wg, r2.Bytes())
-		go system.Broadcast(&wg, delta.Bytes())
-		go system.Broadcast(&wg, shares[i].Gama.Bytes())
+		go system.Broadcast(&wg, i, commit1)
+		go system.Broadcast(&wg, i, commit2)
+		go system.Broadcast(&wg, i, r1.Bytes())
+		go system.Broadcast(&wg, i, r2.Bytes())
+		go system.Broadcast(&wg, i, delta.Bytes())
+		go system.Broadcast(&wg, i, shares[i].Gama.Bytes())
 		opencommit1 := OpenComit(delta.Bytes(), commit1, r1)
 		opencommit2 := OpenComit(shares[i].Gama.Bytes(), commit2, r2)
 		if !opencommit1 || !opencommit2 {
@@ -164,7 +164,7 @@ func (system *RSAShareSystem) OpenFn_Mul(shares []Share_Fn) (*big.Int, bool) {
 	ori_value := big.NewInt(1)
 	for i := 0; i < system.Partynum; i++ {
 		wg.Add(1)
-		go system.Broadcast(&wg, shares[i].Share.Bytes())
+		go system.Broadcast(&wg, i, shares[i].Share.Bytes())
 		ori_value = ori_value.Mul(ori_value, shares[i].Share)
 	}
 	ori_value = ori_value.Mod(ori_value, system.Order)
@@ -13,6 +13,8 @@ type Share_Fp struct {
 	Gama  *big.Int
 	Delta *big.Int
 	Index int
+	// Tag is set in the identifiable-abort mode, see EnableIdentifiableAbort.
+	Tag *Tag_Fp
 }
 
 func (system *ShareSystem) Share_An_Fp(element *big.Int) *[]Share_Fp {
@@ -20,10 +22,10 @@ func (system *ShareSystem) Share_An_Fp(element *big.Int) *[]Share_Fp {
 	ori_value := new(big.Int).Set(element)
 	Delta, _ := curve.RandomK(rand.Reader)
 	wg.Add(2)
-	go system.Send(&wg, ori_value.Bytes())
+	go system.Send(&wg, system.Partynum, ori_value.Bytes())
 	go system.BroadcastN(&wg, Delta.Bytes())
 	Gama := new(big.Int).Add(ori_value, Delta)
-	Gama = Gama.Mul(system.alpha, Gama)
+	Gama = Gama.Mul(system.dealerKey(), Gama)
 	Gama = Gama.Mod(Gama, system.Order)
 	shares := make([]Share_Fp, system.Partynum)
 	for i := 0; i < system.Partynum; i++ {
@@ -41,10 +43,11 @@ func (system *ShareSystem) Share_An_Fp(element *big.Int) *[]Share_Fp {
 			shares[i].Gama = Gama
 		}
 		wg.Add(2)
-		go system.Send(&wg, shares[i].Share.Bytes())
-		go system.Send(&wg, shares[i].Gama.Bytes())
+		go system.Send(&wg, i, shares[i].Share.Bytes())
+		go system.Send(&wg, i, shares[i].Gama.Bytes())
 	}
 	wg.Wait()
+	system.tagFp(shares, system.Send)
 	return &shares
 }
 
@@ -55,7 +58,7 @@ func (system *ShareSystem) Share_An_Fp_Offline(element *big.Int) *[]Share_Fp {
 	wg.Add(1)
 	go system.OfflineBroadcastN(&wg, Delta.Bytes())
 	Gama := new(big.Int).Add(ori_value, Delta)
-	Gama = Gama.Mul(system.alpha, Gama)
+	Gama = Gama.Mul(system.dealerKey(), Gama)
 	Gama = Gama.Mod(Gama, system.Order)
 	shares := make([]Share_Fp, system.Partynum)
 	for i := 0; i < system.Partynum; i++ {
@@ -73,17 +76,51 @@ func (system *ShareSystem) Share_An_Fp_Offline(element *big.Int) *[]Share_Fp {
 			shares[i].Gama = Gama
 		}
 		wg.Add(2)
-		go system.OfflineSend(&wg, shares[i].Share.Bytes())
-		go system.OfflineSend(&wg, shares[i].Gama.Bytes())
+		go system.OfflineSend(&wg, i, shares[i].Share.Bytes())
+		go system.OfflineSend(&wg, i, shares[i].Gama.Bytes())
 	}
 	wg.Wait()
+	system.tagFp(shares, system.OfflineSend)
 	return &shares
 }
 
-func (system *ShareSystem) RandomShareFp() *[]Share_Fp {
+// RandomShareFp returns a sharing of a random value. With a pool attached it
+// is taken from the pool, and a *PoolExhaustedError is returned once the
+// pool is empty.
+func (system *ShareSystem) RandomShareFp() (*[]Share_Fp, error) {
+	if system.Pool != nil {
+		return system.Pool.RandomShareFp()
+	}
+	return system.freshRandomShareFp()
+}
+
+// randomShareFp is RandomShareFp for the operations that cannot return an
+// error; it panics with it, see Recover.
+func (system *ShareSystem) randomShareFp() *[]Share_Fp {
+	rshares, err := system.RandomShareFp()
+	if err != nil {
+		panic(err)
+	}
+	return rshares
+}
+
+// freshRandomShareFp has the dealer sample the value, or with a distributed
+// MAC key every party samples its share and the OfflinePhase MACs them.
+func (system *ShareSystem) freshRandomShareFp() (*[]Share_Fp, error) {
+	if system.alpha == nil {
+		values, err := system.Offline.random()
+		if err != nil {
+			return nil, err
+		}
+		shares, err := system.Offline.authenticate(values)
+		if err != nil {
+			return nil, err
+		}
+		return &shares, nil
+	}
 	r, _ := curve.RandomK(rand.Reader)
 	rshares := system.Share_An_Fp_Offline(r)
-	return rshares
+	return rshares, nil
 }
 
 func (system *ShareSystem) shareAdd(shares1, shares2 Share_Fp) Share_Fp {
@@ -96,12 +133,13 @@ func (system *ShareSystem) shareAdd(shares1, shares2 Share_Fp) Share_Fp {
 	shares.Gama = shares.Gama.Mod(shares.Gama, system.Order)
 	shares.Share = new(big.Int).Add(shares1.Share, shares2.Share)
 	shares.Share = shares.Share.Mod(shares.Share, system.Order)
+	shares.Tag = system.tagAdd(shares1.Tag, shares2.Tag)
 	return *shares
 }
 
 func (system *ShareSystem) SecAddPlaintext(shares1 []Share_Fp, scalar *big.Int) *[]Share_Fp {
 	shares := make([]Share_Fp, system.Partynum)
-	shares2 := system.Share_An_Fp(scalar)
+	shares2 := system.constFp(scalar)
 	for i := 0; i < system.Partynum; i++ {
 		shares[i] = system.shareAdd(shares1[i], (*shares2)[i])
 	}
@@ -118,7 +156,7 @@ func (system *ShareSystem) SecAdd(shares1, shares2 []Share_Fp) *[]Share_Fp {
 
 func (system *ShareSystem) SecSubPlaintext(shares1 []Share_Fp, scalar *big.Int) *[]Share_Fp {
 	shares := make([]Share_Fp, system.Partynum)
-	shares2 := system.Share_An_Fp(scalar)
+	shares2 := system.constFp(scalar)
 	for i := 0; i < system.Partynum; i++ {
 		shares[i] = system.shareSub(shares1[i], (*shares2)[i])
 	}
@@ -135,6 +173,7 @@ func (system *ShareSystem) shareSub(shares1, shares2 Share_Fp) Share_Fp {
 	shares.Gama = shares.Gama.Mod(shares.Gama, system.Order)
 	shares.Share = new(big.Int).Sub(shares1.Share, shares2.Share)
 	shares.Share = shares.Share.Mod(shares.Share, system.Order)
+	shares.Tag = system.tagSub(shares1.Tag, shares2.Tag)
 	return *shares
 }
 
@@ -156,6 +195,7 @@ func (system *ShareSystem) shareMulPlaintext(shares1 Share_Fp, scalar *big.Int)
 	shares.Gama = shares.Gama.Mod(shares.Gama, system.Order)
 	shares.Share = new(big.Int).Mul(shares1.Share, scalar)
 	shares.Share = shares.Share.Mod(shares.Share, system.Order)
+	shares.Tag = system.tagMulPlaintext(shares1.Tag, scalar)
 	return *shares
 }
 
@@ -169,14 +209,17 @@ func (system *ShareSystem) SecMulPlaintext(shares1 []Share_Fp, scalar *big.Int)
 
 func (system *ShareSystem) SecMul(shares1, shares2 []Share_Fp) *[]Share_Fp {
 	shares := make([]Share_Fp, system.Partynum)
-	sharesA, sharesB, sharesC := system.GenTriplets()
+	sharesA, sharesB, sharesC, err := system.GenTriplets()
+	if err != nil {
+		panic(err)
+	}
 	eshares := system.SecSub(shares1, *sharesA)
 	fshares := system.SecSub(shares2, *sharesB)
 	e := system.HalfOpenFp(*eshares)
 	f := system.HalfOpenFp(*fshares)
 	ef := new(big.Int).Mul(e, f)
 	ef = ef.Mod(ef, system.Order)
-	efshares := system.Share_An_Fp(ef)
+	efshares := system.constFp(ef)
 	for i := 0; i < system.Partynum; i++ {
 		beshare := system.shareMulPlaintext((*sharesB)[i], e)
 		afshare := system.shareMulPlaintext((*sharesA)[i], f)
@@ -188,7 +231,10 @@ func (system *ShareSystem) SecMul(shares1, shares2 []Share_Fp) *[]Share_Fp {
 }
 
 func (system *ShareSystem) SecSquare(shares1 []Share_Fp) *[]Share_Fp {
-	sharesA, sharesB := system.GenSquarePair()
+	sharesA, sharesB, err := system.GenSquarePair()
+	if err != nil {
+		panic(err)
+	}
 	eshares := system.SecSub(shares1, *sharesA)
 	e := system.HalfOpenFp(*eshares)
 	e2 := new(big.Int).Mul(e, two)
@@ -200,12 +246,31 @@ func (system *ShareSystem) SecSquare(shares1 []Share_Fp) *[]Share_Fp {
 	return shares
 }
 
+// SecInv returns a sharing of the inverse of x. x is multiplied by a random
+// r and the product opened; it reveals nothing about a nonzero x, and r times
+// its inverse is the inverse of x. It returns ErrNoInverse when x is zero, a
+// *MacCheckError when the opening fails and the error of the random value.
+func (system *ShareSystem) SecInv(shares1 []Share_Fp) (*[]Share_Fp, error) {
+	r, err := system.freshRandomShareFp()
+	if err != nil {
+		return nil, err
+	}
+	c, chk := system.OpenFp(*system.SecMul(shares1, *r))
+	if !chk {
+		return nil, &MacCheckError{Op: "SecInv", Party: -1, Element: -1, Cheater: -1}
+	}
+	if c.Sign() == 0 {
+		return nil, ErrNoInverse
+	}
+	return system.SecMulPlaintext(*r, new(big.Int).ModInverse(c, system.Order)), nil
+}
+
 func (system *ShareSystem) HalfOpenFp(shares []Share_Fp) *big.Int {
 	var wg sync.WaitGroup
 	ori_value := big.NewInt(0)
 	for i := 0; i < system.Partynum; i++ {
 		wg.Add(1)
-		go system.Broadcast(&wg, shares[i].Share.Bytes())
+		go system.Broadcast(&wg, i, shares[i].Share.Bytes())
 		ori_value = ori_value.Add(ori_value, shares[i].Share)
 	}
 	ori_value = ori_value.Mod(ori_value, system.Order)
@@ -213,20 +278,26 @@ func (system *ShareSystem) HalfOpenFp(shares []Share_Fp) *big.Int {
 	return ori_value
 }
 
+// macDiffFp is what the party holding share and the MAC-key share alpha
+// contributes to the MAC check of an opened value: Gama_i - alpha*(v + Delta).
+// Party runs it on its own share, the ShareSystem for every party.
+func (system *ShareSystem) macDiffFp(alpha *big.Int, share Share_Fp, value *big.Int) *big.Int {
+	delta := new(big.Int).Add(value, share.Delta)
+	delta = delta.Mul(delta, alpha)
+	delta = delta.Sub(share.Gama, delta)
+	return delta.Mod(delta, system.Order)
+}
+
 func (system *ShareSystem) MacCheckFp(shares []Share_Fp, res_value *big.Int) bool {
 	var wg sync.WaitGroup
 	chk := big.NewInt(0)
-	delta := new(big.Int)
-	t := new(big.Int).Add(res_value, shares[0].Delta)
-	t = t.Mod(t, system.Order)
 	for i := 0; i < system.Partynum; i++ {
-		delta = delta.Mul(system.Alphas[i], t)
-		delta = delta.Sub(shares[i].Gama, delta)
+		delta := system.macDiffFp(system.Alphas[i], shares[i], res_value)
 		commit, r := Com(delta.Bytes())
 		wg.Add(3)
-		go system.Broadcast(&wg, commit)
-		go system.Broadcast(&wg, r.Bytes())
-		go system.Broadcast(&wg, delta.Bytes())
+		go system.Broadcast(&wg, i, commit)
+		go system.Broadcast(&wg, i, r.Bytes())
+		go system.Broadcast(&wg, i, delta.Bytes())
 		opencommit := OpenComit(delta.Bytes(), commit, r)
 		if !opencommit {
 			return false
@@ -243,7 +314,7 @@ func (system *ShareSystem) OpenFp(shares []Share_Fp) (*big.Int, bool) {
 	var wg sync.WaitGroup
 	for i := 0; i < system.Partynum; i++ {
 		wg.Add(1)
-		go system.Broadcast(&wg, shares[i].Share.Bytes())
+		go system.Broadcast(&wg, i, shares[i].Share.Bytes())
 		ori_value = ori_value.Add(ori_value, shares[i].Share)
 	}
 	ori_value = ori_value.Mod(ori_value, system.Order)
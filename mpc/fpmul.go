@@ -8,12 +8,24 @@ import (
 	"github.com/Oryx/curve"
 )
 
-func (system *ShareSystem) Share_An_Fp_Mul(element *big.Int) *[]Share_Fp {
+// mulGen generates the multiplicative group modulo curve.Order, whose order
+// is 2^2 * 3 * 7 * 11 * 29 * 1548931712415341 * q for a 190-bit prime q.
+var mulGen = big.NewInt(2)
+
+// Share_An_Fp_Mul has the dealer share element multiplicatively, with the MAC
+// (x*Delta)^alpha. Only the dealer can compute it, so with a distributed MAC
+// key this and the other dealt shares of this file return ErrDistributedKey;
+// inputs are then shared with Share_An_Fp_Mul_Owner and
+// Share_An_Fp_for_EXP_Owner.
+func (system *ShareSystem) Share_An_Fp_Mul(element *big.Int) (*[]Share_Fp, error) {
+	if system.alpha == nil {
+		return nil, ErrDistributedKey
+	}
 	var wg sync.WaitGroup
 	ori_value := new(big.Int).Set(element)
 	Delta, _ := rand.Int(rand.Reader, system.Order)
 	wg.Add(2)
-	go system.Send(&wg, ori_value.Bytes())
+	go system.Send(&wg, system.Partynum, ori_value.Bytes())
 	go system.BroadcastN(&wg, Delta.Bytes())
 	Gama := new(big.Int).Mul(ori_value, Delta)
 	Gama = Gama.Mod(Gama, system.Order)
@@ -36,14 +48,17 @@ func (system *ShareSystem) Share_An_Fp_Mul(element *big.Int) *[]Share_Fp {
 			shares[i].Gama = Gama
 		}
 		wg.Add(2)
-		go system.Send(&wg, shares[i].Share.Bytes())
-		go system.Send(&wg, shares[i].Gama.Bytes())
+		go system.Send(&wg, i, shares[i].Share.Bytes())
+		go system.Send(&wg, i, shares[i].Gama.Bytes())
 	}
 	wg.Wait()
-	return &shares
+	return &shares, nil
 }
 
-func (system *ShareSystem) Share_An_Fp_Mul_Offline(element *big.Int) *[]Share_Fp {
+func (system *ShareSystem) Share_An_Fp_Mul_Offline(element *big.Int) (*[]Share_Fp, error) {
+	if system.alpha == nil {
+		return nil, ErrDistributedKey
+	}
 	var wg sync.WaitGroup
 	ori_value := new(big.Int).Set(element)
 	Delta, _ := rand.Int(rand.Reader, system.Order)
@@ -69,19 +84,22 @@ func (system *ShareSystem) Share_An_Fp_Mul_Offline(element *big.Int) *[]Share_Fp
 			shares[i].Gama = Gama
 		}
 		wg.Add(2)
-		go system.OfflineSend(&wg, shares[i].Share.Bytes())
-		go system.OfflineSend(&wg, shares[i].Gama.Bytes())
+		go system.OfflineSend(&wg, i, shares[i].Share.Bytes())
+		go system.OfflineSend(&wg, i, shares[i].Gama.Bytes())
 	}
 	wg.Wait()
-	return &shares
+	return &shares, nil
 }
 
-func (system *ShareSystem) Share_An_Fp_for_EXP(element *big.Int) *[]Share_Fp {
+func (system *ShareSystem) Share_An_Fp_for_EXP(element *big.Int) (*[]Share_Fp, error) {
+	if system.alpha == nil {
+		return nil, ErrDistributedKey
+	}
 	var wg sync.WaitGroup
 	ori_value := new(big.Int).Set(element)
 	Delta, _ := curve.RandomK(rand.Reader)
 	wg.Add(2)
-	go system.Send(&wg, ori_value.Bytes())
+	go system.Send(&wg, system.Partynum, ori_value.Bytes())
 	go system.BroadcastN(&wg, Delta.Bytes())
 	Gama := new(big.Int).Add(ori_value, Delta)
 	Gama = Gama.Mul(system.alpha, Gama)
@@ -102,14 +120,17 @@ func (system *ShareSystem) Share_An_Fp_for_EXP(element *big.Int) *[]Share_Fp {
 			shares[i].Gama = Gama
 		}
 		wg.Add(2)
-		go system.Send(&wg, shares[i].Share.Bytes())
-		go system.Send(&wg, shares[i].Gama.Bytes())
+		go system.Send(&wg, i, shares[i].Share.Bytes())
+		go system.Send(&wg, i, shares[i].Gama.Bytes())
 	}
 	wg.Wait()
-	return &shares
+	return &shares, nil
 }
 
-func (system *ShareSystem) Share_An_Fp_for_EXP_Offline(element *big.Int) *[]Share_Fp {
+func (system *ShareSystem) Share_An_Fp_for_EXP_Offline(element *big.Int) (*[]Share_Fp, error) {
+	if system.alpha == nil {
+		return nil, ErrDistributedKey
+	}
 	var wg sync.WaitGroup
 	ori_value := new(big.Int).Set(element)
 	Delta, _ := curve.RandomK(rand.Reader)
@@ -134,17 +155,245 @@ func (system *ShareSystem) Share_An_Fp_for_EXP_Offline(element *big.Int) *[]Shar
 			shares[i].Gama = Gama
 		}
 		wg.Add(2)
-		go system.OfflineSend(&wg, shares[i].Share.Bytes())
-		go system.OfflineSend(&wg, shares[i].Gama.Bytes())
+		go system.OfflineSend(&wg, i, shares[i].Share.Bytes())
+		go system.OfflineSend(&wg, i, shares[i].Gama.Bytes())
 	}
 	wg.Wait()
-	return &shares
+	return &shares, nil
 }
 
-func (system *ShareSystem) RandomShareFp_Mul() *[]Share_Fp {
+// RandomShareFp_Mul shares a random nonzero value multiplicatively. With a
+// distributed key it is mulGen^e for a random e shared for the exponent, as
+// EXP_P_Fp_1 turns the MAC of e into the MAC of the power.
+func (system *ShareSystem) RandomShareFp_Mul() (*[]Share_Fp, error) {
+	if system.alpha == nil {
+		eshares, err := system.randomShareFp_for_EXP()
+		if err != nil {
+			return nil, err
+		}
+		return system.EXP_P_Fp_1(mulGen, *eshares), nil
+	}
 	r, _ := curve.RandomK(rand.Reader)
-	rshares := system.Share_An_Fp_Mul_Offline(r)
-	return rshares
+	return system.Share_An_Fp_Mul_Offline(r)
+}
+
+// randomShareFp_for_EXP shares a random value modulo OrderMul that every
+// party picks its own share of, MACed by OfflineMul.
+func (system *ShareSystem) randomShareFp_for_EXP() (*[]Share_Fp, error) {
+	if system.OfflineMul == nil {
+		return nil, ErrNoOfflinePhase
+	}
+	values, err := system.OfflineMul.random()
+	if err != nil {
+		return nil, err
+	}
+	shares, err := system.OfflineMul.authenticate(values)
+	if err != nil {
+		return nil, err
+	}
+	return &shares, nil
+}
+
+// Share_An_Fp_for_EXP_Owner shares an exponent of party owner without the
+// dealer, as Share_An_Fp_Owner does modulo OrderMul with the OfflineMul. It
+// returns ErrNoOfflinePhase before SetupOfflinePhase.
+func (system *ShareSystem) Share_An_Fp_for_EXP_Owner(owner int, element *big.Int) (*[]Share_Fp, error) {
+	if system.OfflineMul == nil {
+		return nil, ErrNoOfflinePhase
+	}
+	values := make([]*big.Int, system.Partynum)
+	rest := new(big.Int).Set(element)
+	for i := 0; i < system.Partynum; i++ {
+		if i == owner {
+			continue
+		}
+		values[i], _ = rand.Int(rand.Reader, system.OrderMul)
+		rest = rest.Sub(rest, values[i])
+		system.transfer(owner, i, values[i].Bytes())
+	}
+	values[owner] = rest.Mod(rest, system.OrderMul)
+	shares, err := system.OfflineMul.authenticate(values)
+	if err != nil {
+		return nil, err
+	}
+	return &shares, nil
+}
+
+// Share_An_Fp_Mul_Owner shares a nonzero input of party owner
+// multiplicatively, the way Share_A_G1_Owner shares a point: the parties send
+// the owner their shares of a random r from RandomShareFp_Mul, the owner
+// broadcasts element/r, and the shares of r times it are the shares of
+// element. It returns ErrNoInverse when element is zero and the errors of
+// RandomShareFp_Mul.
+func (system *ShareSystem) Share_An_Fp_Mul_Owner(owner int, element *big.Int) (*[]Share_Fp, error) {
+	x := new(big.Int).Mod(element, system.Order)
+	if x.Sign() == 0 {
+		return nil, ErrNoInverse
+	}
+	rshares, err := system.RandomShareFp_Mul()
+	if err != nil {
+		return nil, err
+	}
+	r := new(big.Int).Set((*rshares)[owner].Share)
+	for i := 0; i < system.Partynum; i++ {
+		if i != owner {
+			r = r.Mul(r, (*rshares)[i].Share)
+			r = r.Mod(r, system.Order)
+			system.transfer(i, owner, (*rshares)[i].Share.Bytes())
+		}
+	}
+	c := x.Mul(x, new(big.Int).ModInverse(r, system.Order))
+	c = c.Mod(c, system.Order)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go system.Broadcast(&wg, owner, c.Bytes())
+	wg.Wait()
+	return system.SecMulPlaintext_Mul(*rshares, c), nil
+}
+
+// constMul shares a public constant multiplicatively, as constFp does
+// additively. With a distributed key party 0 takes the constant as its share
+// and the others 1, Delta is 1 and every party takes the constant to the
+// power AlphasMul[i] as its MAC share.
+func (system *ShareSystem) constMul(scalar *big.Int) *[]Share_Fp {
+	if system.alpha != nil {
+		// only fails without the dealer's key
+		shares, _ := system.Share_An_Fp_Mul(scalar)
+		return shares
+	}
+	c := new(big.Int).Mod(scalar, system.Order)
+	shares := make([]Share_Fp, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i].Index = i
+		shares[i].Share = big.NewInt(1)
+		if i == 0 {
+			shares[i].Share = c
+		}
+		shares[i].Gama = new(big.Int).Exp(c, system.AlphasMul[i], system.Order)
+		shares[i].Delta = big.NewInt(1)
+	}
+	return &shares
+}
+
+// AddToMul turns an additive sharing of a nonzero x into a multiplicative
+// one. The dealer hands out an additive sharing of a random s and a
+// multiplicative sharing of its inverse; x*s is opened, and the inverse of s
+// times it is x. With a distributed key the pair comes from expPair. It
+// returns ErrNoInverse when x is zero, a *MacCheckError when an opening fails
+// and the errors of expPair.
+func (system *ShareSystem) AddToMul(shares1 []Share_Fp) (*[]Share_Fp, error) {
+	var sshares, sinvshares *[]Share_Fp
+	var err error
+	if system.alpha == nil {
+		sinvshares, sshares, err = system.expPair()
+	} else {
+		s, _ := curve.RandomK(rand.Reader)
+		sshares = system.Share_An_Fp_Offline(s)
+		sinvshares, err = system.Share_An_Fp_Mul_Offline(new(big.Int).ModInverse(s, system.Order))
+	}
+	if err != nil {
+		return nil, err
+	}
+	c, chk := system.OpenFp(*system.SecMul(shares1, *sshares))
+	if !chk {
+		return nil, &MacCheckError{Op: "AddToMul", Party: -1, Element: -1, Cheater: -1}
+	}
+	if c.Sign() == 0 {
+		return nil, ErrNoInverse
+	}
+	return system.SecMulPlaintext_Mul(*sinvshares, c), nil
+}
+
+// MulToAdd turns a multiplicative sharing of x into an additive one, the
+// other way round from AddToMul: x/s is opened from the multiplicative
+// shares, and x is it times an additive sharing of s. It returns the errors
+// of AddToMul except ErrNoInverse.
+func (system *ShareSystem) MulToAdd(shares1 []Share_Fp) (*[]Share_Fp, error) {
+	var sshares, sinvshares *[]Share_Fp
+	var err error
+	if system.alpha == nil {
+		sinvshares, sshares, err = system.expPair()
+	} else {
+		s, _ := curve.RandomK(rand.Reader)
+		sshares = system.Share_An_Fp_Offline(s)
+		sinvshares, err = system.Share_An_Fp_Mul_Offline(new(big.Int).ModInverse(s, system.Order))
+	}
+	if err != nil {
+		return nil, err
+	}
+	c, chk := system.OpenFp_Mul(*system.SecMul_Mul(shares1, *sinvshares))
+	if !chk {
+		return nil, &MacCheckError{Op: "MulToAdd", Party: -1, Element: -1, Cheater: -1}
+	}
+	return system.SecMulPlaintext(*sshares, c), nil
+}
+
+// expPair returns a multiplicative sharing of mulGen^-e and an additive
+// sharing of mulGen^e for a random e, without the dealer. Every party i
+// inputs mulGen^e_i for its own share e_i with Share_An_Fp_Owner, and the
+// product of the inputs is mulGen^e. Nothing binds an input to the share of
+// e, so the pair is checked against statsec more pairs: for each a public
+// coin either opens the other pair and compares, or opens the sum of the two
+// exponents and compares mulGen to it with the product of the two powers. A
+// party whose input is off by a factor passes each check with probability at
+// most 1/2. It returns ErrSacrifice when a check fails and a *MacCheckError
+// when an opening does.
+func (system *ShareSystem) expPair() (*[]Share_Fp, *[]Share_Fp, error) {
+	eshares, pshares, err := system.rawExpPair()
+	if err != nil {
+		return nil, nil, err
+	}
+	for k := 0; k < statsec; k++ {
+		eshares2, pshares2, err := system.rawExpPair()
+		if err != nil {
+			return nil, nil, err
+		}
+		var d *big.Int
+		var chk bool
+		if system.coin().Bit(0) == 0 {
+			d, chk = system.OfflineMul.open(*eshares2)
+		} else {
+			d, chk = system.OfflineMul.open(system.OfflineMul.combine([]*big.Int{one, one}, *eshares, *eshares2))
+			pshares2 = system.SecMul(*pshares, *pshares2)
+		}
+		if !chk {
+			return nil, nil, &MacCheckError{Op: "expPair", Party: -1, Element: -1, Cheater: -1}
+		}
+		c, chk := system.OpenFp(*system.SecSubPlaintext(*pshares2, new(big.Int).Exp(mulGen, d, system.Order)))
+		if !chk {
+			return nil, nil, &MacCheckError{Op: "expPair", Party: -1, Element: -1, Cheater: -1}
+		}
+		if c.Sign() != 0 {
+			return nil, nil, ErrSacrifice
+		}
+	}
+	ginv := new(big.Int).ModInverse(mulGen, system.Order)
+	return system.EXP_P_Fp_1(ginv, *eshares), pshares, nil
+}
+
+// rawExpPair returns the unchecked shares of e and of mulGen^e for expPair.
+func (system *ShareSystem) rawExpPair() (*[]Share_Fp, *[]Share_Fp, error) {
+	if system.Offline == nil || system.OfflineMul == nil {
+		return nil, nil, ErrNoOfflinePhase
+	}
+	values, err := system.OfflineMul.random()
+	if err != nil {
+		return nil, nil, err
+	}
+	eshares, err := system.OfflineMul.authenticate(values)
+	if err != nil {
+		return nil, nil, err
+	}
+	var pshares *[]Share_Fp
+	for i := 0; i < system.Partynum; i++ {
+		input := system.Share_An_Fp_Owner(i, new(big.Int).Exp(mulGen, values[i], system.Order))
+		if pshares == nil {
+			pshares = input
+		} else {
+			pshares = system.SecMul(*pshares, *input)
+		}
+	}
+	return &eshares, pshares, nil
 }
 
 func (system *ShareSystem) shareMul(shares1, shares2 Share_Fp) Share_Fp {
@@ -162,7 +411,7 @@ func (system *ShareSystem) shareMul(shares1, shares2 Share_Fp) Share_Fp {
 
 func (system *ShareSystem) SecMulPlaintext_Mul(shares1 []Share_Fp, scalar *big.Int) *[]Share_Fp {
 	shares := make([]Share_Fp, system.Partynum)
-	shares2 := system.Share_An_Fp_Mul(scalar)
+	shares2 := system.constMul(scalar)
 	for i := 0; i < system.Partynum; i++ {
 		shares[i] = system.shareMul(shares1[i], (*shares2)[i])
 	}
@@ -192,7 +441,7 @@ func (system *ShareSystem) shareDiv(shares1, shares2 Share_Fp) Share_Fp {
 
 func (system *ShareSystem) SecDiv_Plaintext_1(shares1 []Share_Fp, scalar *big.Int) *[]Share_Fp {
 	shares := make([]Share_Fp, system.Partynum)
-	shares2 := system.Share_An_Fp_Mul(scalar)
+	shares2 := system.constMul(scalar)
 	for i := 0; i < system.Partynum; i++ {
 		shares[i] = system.shareDiv(shares1[i], (*shares2)[i])
 	}
@@ -201,7 +450,7 @@ func (system *ShareSystem) SecDiv_Plaintext_1(shares1 []Share_Fp, scalar *big.In
 
 func (system *ShareSystem) SecDiv_Plaintext_2(scalar *big.Int, shares1 []Share_Fp) *[]Share_Fp {
 	shares := make([]Share_Fp, system.Partynum)
-	shares2 := system.Share_An_Fp_Mul(scalar)
+	shares2 := system.constMul(scalar)
 	for i := 0; i < system.Partynum; i++ {
 		shares[i] = system.shareDiv((*shares2)[i], shares1[i])
 	}
@@ -238,32 +487,37 @@ func (system *ShareSystem) EXP_P_Fp_2(shares1 []Share_Fp, scalar *big.Int) *[]Sh
 	return &shares
 }
 
-/*
-func (system *ShareSystem) EXP_S_Fp(hshares, xshares []Share_Fp) *[]Share_Fp {
-	sharesA, sharesB, sharesC := system.GenTriplets_for_Exp()
-	G, _ := curve.RandomK(rand.Reader)
-	sharesgB := system.EXP_P_Fp_1(G, *sharesB)
-	sharesgC := system.EXP_P_Fp_1(G, *sharesC)
-	XsubAshares := system.SecSub__for_EXP(xshares, *sharesA)
-	xsuba := system.HalfOpenFp_for_Exp(*XsubAshares)
-	tshares := system.SecDiv(hshares, *sharesgB)
-	t := system.HalfOpenFp_Mul(*tshares)
-	t_exp_xsuba_shares := system.EXP_P_Fp_1(t, *XsubAshares)
-	t_exp_a_shares := system.EXP_P_Fp_1(t, *sharesA)
-	gb_exp_xsuba_shares := system.EXP_P_Fp_2(*sharesgB, xsuba)
-	shares := system.SecMul_Mul(*sharesgC, *gb_exp_xsuba_shares)
-	shares = system.SecMul_Mul(*shares, *t_exp_a_shares)
-	shares = system.SecMul_Mul(*shares, *t_exp_xsuba_shares)
+// EXP_S_Fp returns a sharing of h^x for a shared base h and a shared
+// exponent x below 2^bits, both additive shares. x is split into bits with
+// BitDecompose, and h^x is the product of 1 + x_i*(h^(2^i) - 1) over the bits,
+// with h^(2^i) from repeated squaring: bits square pairs and 2*bits - 1
+// triples on top of BitDecompose. Every value stays MACed; like
+// BitDecompose it panics when bits is too large or an opening fails.
+func (system *ShareSystem) EXP_S_Fp(hshares, xshares []Share_Fp, bits int) *[]Share_Fp {
+	xbits := system.BitDecompose(xshares, bits)
+	pow := &hshares
+	var shares *[]Share_Fp
+	for i := 0; i < bits; i++ {
+		if i > 0 {
+			pow = system.SecSquare(*pow)
+		}
+		term := system.SecMul(*xbits[i], *system.SecSubPlaintext(*pow, one))
+		term = system.SecAddPlaintext(*term, one)
+		if shares == nil {
+			shares = term
+		} else {
+			shares = system.SecMul(*shares, *term)
+		}
+	}
 	return shares
 }
-*/
 
 func (system *ShareSystem) HalfOpenFp_for_Exp(shares []Share_Fp) *big.Int {
 	var wg sync.WaitGroup
 	ori_value := big.NewInt(0)
 	for i := 0; i < system.Partynum; i++ {
 		wg.Add(1)
-		go system.Broadcast(&wg, shares[i].Share.Bytes())
+		go system.Broadcast(&wg, i, shares[i].Share.Bytes())
 		ori_value = ori_value.Add(ori_value, shares[i].Share)
 	}
 	ori_value = ori_value.Mod(ori_value, system.OrderMul)
@@ -276,7 +530,7 @@ func (system *ShareSystem) HalfOpenFp_Mul(shares []Share_Fp) *big.Int {
 	ori_value := big.NewInt(1)
 	for i := 0; i < system.Partynum; i++ {
 		wg.Add(1)
-		go system.Broadcast(&wg, shares[i].Share.Bytes())
+		go system.Broadcast(&wg, i, shares[i].Share.Bytes())
 		ori_value = ori_value.Mul(ori_value, shares[i].Share)
 	}
 	ori_value = ori_value.Mod(ori_value, system.Order)
@@ -297,9 +551,9 @@ func (system *ShareSystem) MacCheckFp_Mul(shares []Share_Fp, res_value *big.Int)
 		delta = delta.Mod(delta, system.Order)
 		commit, r := Com(delta.Bytes())
 		wg.Add(3)
-		go system.Broadcast(&wg, commit)
-		go system.Broadcast(&wg, r.Bytes())
-		go system.Broadcast(&wg, delta.Bytes())
+		go system.Broadcast(&wg, i, commit)
+		go system.Broadcast(&wg, i, r.Bytes())
+		go system.Broadcast(&wg, i, delta.Bytes())
 		opencommit := OpenComit(delta.Bytes(), commit, r)
 		if !opencommit {
 			return false
@@ -317,7 +571,7 @@ func (system *ShareSystem) OpenFp_Mul(shares []Share_Fp) (*big.Int, bool) {
 	ori_value := big.NewInt(1)
 	for i := 0; i < system.Partynum; i++ {
 		wg.Add(1)
-		go system.Broadcast(&wg, shares[i].Share.Bytes())
+		go system.Broadcast(&wg, i, shares[i].Share.Bytes())
 		ori_value = ori_value.Mul(ori_value, shares[i].Share)
 	}
 	ori_value = ori_value.Mod(ori_value, system.Order)
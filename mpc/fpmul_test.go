@@ -0,0 +1,43 @@
+package mpc
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestAddToMul(t *testing.T) {
+	system := SystemInit(3)
+	pminus1 := new(big.Int).Sub(system.Order, one)
+	for _, x := range []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(987654321), pminus1} {
+		mshares, err := system.AddToMul(*system.Share_An_Fp(x))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, chk := system.OpenFp_Mul(*mshares)
+		if !chk {
+			t.Fatalf("MAC check failed for the multiplicative sharing of %v", x)
+		}
+		if got.Cmp(x) != 0 {
+			t.Fatalf("got %v from AddToMul, want %v", got, x)
+		}
+		ashares, err := system.MulToAdd(*mshares)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, chk = system.OpenFp(*ashares)
+		if !chk {
+			t.Fatalf("MAC check failed for the additive sharing of %v", x)
+		}
+		if got.Cmp(x) != 0 {
+			t.Fatalf("got %v from MulToAdd, want %v", got, x)
+		}
+	}
+}
+
+func TestAddToMulZero(t *testing.T) {
+	system := SystemInit(3)
+	if _, err := system.AddToMul(*system.Share_An_Fp(big.NewInt(0))); !errors.Is(err, ErrNoInverse) {
+		t.Fatalf("got %v, want %v", err, ErrNoInverse)
+	}
+}
@@ -0,0 +1,471 @@
+package mpc
+
+import (
+	"crypto/rand"
+	"math/big"
+	"sync"
+
+	curve "github.com/Oryx/curve"
+	"github.com/Oryx/network"
+)
+
+// In the identifiable-abort mode every party j holds a global MAC key
+// keys[j], and every share x_i of party i carries, for each j, a MAC
+// Macs[j] = keys[j]*x_i + Keys[j] kept by party i and a local key Keys[j]
+// kept by party j. The same is done for Gama, and for the alpha shares, so
+// that after a failed MAC check each party can be made to reveal its share
+// and its check contribution Gama_i - Alphas[i]*t together with MACs that the
+// others verify with their keys. A party whose MACs do not verify is the one
+// that deviated.
+
+type Tag_Fp struct {
+	Macs     []*big.Int
+	Keys     []*big.Int
+	GamaMacs []*big.Int
+	GamaKeys []*big.Int
+}
+
+// Tag holds the MACs of a Share in a curve.Group like Tag_Fp.
+type Tag[E any] struct {
+	Macs     []*E
+	Keys     []*E
+	GamaMacs []*E
+	GamaKeys []*E
+}
+
+type Tag_G1 = Tag[curve.G1]
+type Tag_G2 = Tag[curve.G2]
+type Tag_GT = Tag[curve.GT]
+
+type identifier struct {
+	keys      []*big.Int
+	alphaMacs [][]*big.Int
+	alphaKeys [][]*big.Int
+}
+
+// EnableIdentifiableAbort switches on the identifiable-abort mode. From then
+// on every share handed out by the dealer or the OfflinePhase carries
+// pairwise MACs, the linear operations carry them along, and IdentifyFp,
+// IdentifyG1, IdentifyG2 and IdentifyGT name the party behind a failed MAC
+// check. Shares from a Pool or the Party API carry no MACs, nor does anything
+// computed from them; such failures cannot be attributed. Call it before
+// sharing any input.
+func (system *ShareSystem) EnableIdentifiableAbort() {
+	n := system.Partynum
+	ia := new(identifier)
+	ia.keys = make([]*big.Int, n)
+	for j := 0; j < n; j++ {
+		ia.keys[j], _ = curve.RandomK(rand.Reader)
+	}
+	system.ia = ia
+	if system.Offline != nil {
+		if err := system.tagOffline(); err != nil {
+			panic(err)
+		}
+		return
+	}
+	var wg sync.WaitGroup
+	ia.alphaMacs = make([][]*big.Int, n)
+	ia.alphaKeys = make([][]*big.Int, n)
+	msgs := make([][][]byte, n)
+	for i := 0; i < n; i++ {
+		ia.alphaMacs[i] = make([]*big.Int, n)
+		ia.alphaKeys[i] = make([]*big.Int, n)
+		for j := 0; j < n; j++ {
+			ia.alphaKeys[i][j], _ = curve.RandomK(rand.Reader)
+			ia.alphaMacs[i][j] = system.macFp(ia.keys[j], system.Alphas[i], ia.alphaKeys[i][j])
+			msgs[i] = append(msgs[i], ia.alphaMacs[i][j].Bytes())
+			msgs[j] = append(msgs[j], ia.alphaKeys[i][j].Bytes())
+		}
+	}
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go system.OfflineSend(&wg, i, network.Pack(msgs[i]...))
+	}
+	wg.Wait()
+}
+
+// tagOffline has the OfflinePhase tag the shares it produces and MAC the
+// alpha shares with its pairwise products, so no dealer sees Alphas.
+func (system *ShareSystem) tagOffline() error {
+	if err := system.Offline.enableTags(system.ia.keys); err != nil {
+		return err
+	}
+	macs, keys, err := system.Offline.pairMacs(system.Alphas)
+	if err != nil {
+		return err
+	}
+	system.ia.alphaMacs = macs
+	system.ia.alphaKeys = keys
+	return nil
+}
+
+// IdentifiableAbort reports whether EnableIdentifiableAbort has been called.
+func (system *ShareSystem) IdentifiableAbort() bool {
+	return system.ia != nil
+}
+
+func (system *ShareSystem) macFp(key, x, k *big.Int) *big.Int {
+	mac := new(big.Int).Mul(key, x)
+	mac = mac.Add(mac, k)
+	return mac.Mod(mac, system.Order)
+}
+
+func groupMac[E any, P curve.Group[E]](key *big.Int, x, k *E) *E {
+	mac := P(new(E)).ScalarMult(x, key)
+	return P(mac).Add(mac, k)
+}
+
+// tagFp attaches MACs to freshly dealt shares. send is Send for online and
+// OfflineSend for offline sharings; party j receives the MACs on its own
+// share and its keys for the shares of the others.
+func (system *ShareSystem) tagFp(shares []Share_Fp, send func(*sync.WaitGroup, int, []byte)) {
+	ia := system.ia
+	if ia == nil {
+		return
+	}
+	var wg sync.WaitGroup
+	n := system.Partynum
+	msgs := make([][][]byte, n)
+	for i := 0; i < n; i++ {
+		tag := &Tag_Fp{make([]*big.Int, n), make([]*big.Int, n), make([]*big.Int, n), make([]*big.Int, n)}
+		for j := 0; j < n; j++ {
+			tag.Keys[j], _ = curve.RandomK(rand.Reader)
+			tag.GamaKeys[j], _ = curve.RandomK(rand.Reader)
+			tag.Macs[j] = system.macFp(ia.keys[j], shares[i].Share, tag.Keys[j])
+			tag.GamaMacs[j] = system.macFp(ia.keys[j], shares[i].Gama, tag.GamaKeys[j])
+			msgs[i] = append(msgs[i], tag.Macs[j].Bytes(), tag.GamaMacs[j].Bytes())
+			msgs[j] = append(msgs[j], tag.Keys[j].Bytes(), tag.GamaKeys[j].Bytes())
+		}
+		shares[i].Tag = tag
+	}
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go send(&wg, i, network.Pack(msgs[i]...))
+	}
+	wg.Wait()
+}
+
+func groupTag[E any, P curve.Group[E]](system *ShareSystem, shares []Share[E], send func(*sync.WaitGroup, int, []byte)) {
+	ia := system.ia
+	if ia == nil {
+		return
+	}
+	var wg sync.WaitGroup
+	n := system.Partynum
+	msgs := make([][][]byte, n)
+	for i := 0; i < n; i++ {
+		tag := newTag[E](n)
+		for j := 0; j < n; j++ {
+			tag.Keys[j] = curve.RandomElement[E, P]()
+			tag.GamaKeys[j] = curve.RandomElement[E, P]()
+			tag.Macs[j] = groupMac[E, P](ia.keys[j], shares[i].Share, tag.Keys[j])
+			tag.GamaMacs[j] = groupMac[E, P](ia.keys[j], shares[i].Gama, tag.GamaKeys[j])
+			msgs[i] = append(msgs[i], P(tag.Macs[j]).Marshal(), P(tag.GamaMacs[j]).Marshal())
+			msgs[j] = append(msgs[j], P(tag.Keys[j]).Marshal(), P(tag.GamaKeys[j]).Marshal())
+		}
+		shares[i].Tag = tag
+	}
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go send(&wg, i, network.Pack(msgs[i]...))
+	}
+	wg.Wait()
+}
+
+// constTag is the tag of the share of party i of a public constant c as
+// constFp builds it: party 0 holds c under the MACs zero and every party j
+// takes -keys[j]*c as its key, while the MACs on Gama_i = Alphas[i]*c follow
+// from those on Alphas[i].
+func (system *ShareSystem) constTag(i int, c *big.Int) *Tag_Fp {
+	ia := system.ia
+	if ia == nil {
+		return nil
+	}
+	n := system.Partynum
+	tag := &Tag_Fp{make([]*big.Int, n), make([]*big.Int, n), make([]*big.Int, n), make([]*big.Int, n)}
+	for j := 0; j < n; j++ {
+		tag.Macs[j] = big.NewInt(0)
+		tag.Keys[j] = big.NewInt(0)
+		if i == 0 {
+			tag.Keys[j] = system.macFp(ia.keys[j], new(big.Int).Neg(c), tag.Keys[j])
+		}
+		tag.GamaMacs[j] = system.macFp(ia.alphaMacs[i][j], c, big.NewInt(0))
+		tag.GamaKeys[j] = system.macFp(ia.alphaKeys[i][j], c, big.NewInt(0))
+	}
+	return tag
+}
+
+// tagShift is the tag of a share that party 0 raised by the public d: the
+// MACs stay and every party j lowers its key by keys[j]*d.
+func (system *ShareSystem) tagShift(tag1 *Tag_Fp, d *big.Int) *Tag_Fp {
+	if tag1 == nil {
+		return nil
+	}
+	n := len(tag1.Macs)
+	tag := &Tag_Fp{tag1.Macs, make([]*big.Int, n), tag1.GamaMacs, tag1.GamaKeys}
+	for j := 0; j < n; j++ {
+		tag.Keys[j] = system.macFp(system.ia.keys[j], new(big.Int).Neg(d), tag1.Keys[j])
+	}
+	return tag
+}
+
+// The MACs are linear in the share, so every local operation on shares is
+// applied to the MACs and keys as well. A result computed from an untagged
+// share is untagged.
+
+func (system *ShareSystem) tagAdd(tag1, tag2 *Tag_Fp) *Tag_Fp {
+	if tag1 == nil || tag2 == nil {
+		return nil
+	}
+	n := len(tag1.Macs)
+	tag := &Tag_Fp{make([]*big.Int, n), make([]*big.Int, n), make([]*big.Int, n), make([]*big.Int, n)}
+	for j := 0; j < n; j++ {
+		tag.Macs[j] = new(big.Int).Add(tag1.Macs[j], tag2.Macs[j])
+		tag.Macs[j] = tag.Macs[j].Mod(tag.Macs[j], system.Order)
+		tag.Keys[j] = new(big.Int).Add(tag1.Keys[j], tag2.Keys[j])
+		tag.Keys[j] = tag.Keys[j].Mod(tag.Keys[j], system.Order)
+		tag.GamaMacs[j] = new(big.Int).Add(tag1.GamaMacs[j], tag2.GamaMacs[j])
+		tag.GamaMacs[j] = tag.GamaMacs[j].Mod(tag.GamaMacs[j], system.Order)
+		tag.GamaKeys[j] = new(big.Int).Add(tag1.GamaKeys[j], tag2.GamaKeys[j])
+		tag.GamaKeys[j] = tag.GamaKeys[j].Mod(tag.GamaKeys[j], system.Order)
+	}
+	return tag
+}
+
+func (system *ShareSystem) tagSub(tag1, tag2 *Tag_Fp) *Tag_Fp {
+	if tag1 == nil || tag2 == nil {
+		return nil
+	}
+	n := len(tag1.Macs)
+	tag := &Tag_Fp{make([]*big.Int, n), make([]*big.Int, n), make([]*big.Int, n), make([]*big.Int, n)}
+	for j := 0; j < n; j++ {
+		tag.Macs[j] = new(big.Int).Sub(tag1.Macs[j], tag2.Macs[j])
+		tag.Macs[j] = tag.Macs[j].Mod(tag.Macs[j], system.Order)
+		tag.Keys[j] = new(big.Int).Sub(tag1.Keys[j], tag2.Keys[j])
+		tag.Keys[j] = tag.Keys[j].Mod(tag.Keys[j], system.Order)
+		tag.GamaMacs[j] = new(big.Int).Sub(tag1.GamaMacs[j], tag2.GamaMacs[j])
+		tag.GamaMacs[j] = tag.GamaMacs[j].Mod(tag.GamaMacs[j], system.Order)
+		tag.GamaKeys[j] = new(big.Int).Sub(tag1.GamaKeys[j], tag2.GamaKeys[j])
+		tag.GamaKeys[j] = tag.GamaKeys[j].Mod(tag.GamaKeys[j], system.Order)
+	}
+	return tag
+}
+
+func (system *ShareSystem) tagMulPlaintext(tag1 *Tag_Fp, scalar *big.Int) *Tag_Fp {
+	if tag1 == nil {
+		return nil
+	}
+	n := len(tag1.Macs)
+	tag := &Tag_Fp{make([]*big.Int, n), make([]*big.Int, n), make([]*big.Int, n), make([]*big.Int, n)}
+	for j := 0; j < n; j++ {
+		tag.Macs[j] = new(big.Int).Mul(tag1.Macs[j], scalar)
+		tag.Macs[j] = tag.Macs[j].Mod(tag.Macs[j], system.Order)
+		tag.Keys[j] = new(big.Int).Mul(tag1.Keys[j], scalar)
+		tag.Keys[j] = tag.Keys[j].Mod(tag.Keys[j], system.Order)
+		tag.GamaMacs[j] = new(big.Int).Mul(tag1.GamaMacs[j], scalar)
+		tag.GamaMacs[j] = tag.GamaMacs[j].Mod(tag.GamaMacs[j], system.Order)
+		tag.GamaKeys[j] = new(big.Int).Mul(tag1.GamaKeys[j], scalar)
+		tag.GamaKeys[j] = tag.GamaKeys[j].Mod(tag.GamaKeys[j], system.Order)
+	}
+	return tag
+}
+
+func newTag[E any](n int) *Tag[E] {
+	return &Tag[E]{make([]*E, n), make([]*E, n), make([]*E, n), make([]*E, n)}
+}
+
+// groupTagMap applies f to every MAC and key of tag1.
+func groupTagMap[E, F any](tag1 *Tag[F], f func(*F) *E) *Tag[E] {
+	if tag1 == nil {
+		return nil
+	}
+	n := len(tag1.Macs)
+	tag := newTag[E](n)
+	for j := 0; j < n; j++ {
+		tag.Macs[j] = f(tag1.Macs[j])
+		tag.Keys[j] = f(tag1.Keys[j])
+		tag.GamaMacs[j] = f(tag1.GamaMacs[j])
+		tag.GamaKeys[j] = f(tag1.GamaKeys[j])
+	}
+	return tag
+}
+
+func groupTagAdd[E any, P curve.Group[E]](tag1, tag2 *Tag[E]) *Tag[E] {
+	if tag1 == nil || tag2 == nil {
+		return nil
+	}
+	n := len(tag1.Macs)
+	tag := newTag[E](n)
+	for j := 0; j < n; j++ {
+		tag.Macs[j] = P(new(E)).Add(tag1.Macs[j], tag2.Macs[j])
+		tag.Keys[j] = P(new(E)).Add(tag1.Keys[j], tag2.Keys[j])
+		tag.GamaMacs[j] = P(new(E)).Add(tag1.GamaMacs[j], tag2.GamaMacs[j])
+		tag.GamaKeys[j] = P(new(E)).Add(tag1.GamaKeys[j], tag2.GamaKeys[j])
+	}
+	return tag
+}
+
+func groupTagSub[E any, P curve.Group[E]](tag1, tag2 *Tag[E]) *Tag[E] {
+	return groupTagAdd[E, P](tag1, groupTagMap(tag2, func(x *E) *E { return P(new(E)).Neg(x) }))
+}
+
+func groupTag_EXP_P_1[E any, P curve.Group[E]](element *E, tag1 *Tag_Fp) *Tag[E] {
+	if tag1 == nil {
+		return nil
+	}
+	n := len(tag1.Macs)
+	tag := newTag[E](n)
+	for j := 0; j < n; j++ {
+		tag.Macs[j] = P(new(E)).ScalarMult(element, tag1.Macs[j])
+		tag.Keys[j] = P(new(E)).ScalarMult(element, tag1.Keys[j])
+		tag.GamaMacs[j] = P(new(E)).ScalarMult(element, tag1.GamaMacs[j])
+		tag.GamaKeys[j] = P(new(E)).ScalarMult(element, tag1.GamaKeys[j])
+	}
+	return tag
+}
+
+func groupTag_EXP_P_2[E any, P curve.Group[E]](tag1 *Tag[E], x *big.Int) *Tag[E] {
+	return groupTagMap(tag1, func(e *E) *E { return P(new(E)).ScalarMult(e, x) })
+}
+
+func tag_Pair_P_1(tag1 *Tag_G1, g2 *curve.G2) *Tag_GT {
+	return groupTagMap(tag1, func(g1 *curve.G1) *curve.GT { return curve.Pair(g1, g2) })
+}
+
+func tag_Pair_P_2(g1 *curve.G1, tag1 *Tag_G2) *Tag_GT {
+	return groupTagMap(tag1, func(g2 *curve.G2) *curve.GT { return curve.Pair(g1, g2) })
+}
+
+// revealTags broadcasts what party i reveals during identification: its
+// share, its check contribution and the MACs on both.
+func (system *ShareSystem) revealTags(wg *sync.WaitGroup, i int, parts ...[]byte) {
+	wg.Add(1)
+	go system.Broadcast(wg, i, network.Pack(parts...))
+}
+
+// IdentifyFp is run after shares failed their MAC check. Every party reveals
+// its share and its contribution Gama_i - Alphas[i]*(v + Delta) with their
+// MACs, and the others verify them with their keys. It returns the first
+// party whose MACs do not verify, or -1 if the shares are untagged or all
+// MACs verify.
+func (system *ShareSystem) IdentifyFp(shares []Share_Fp) int {
+	ia := system.ia
+	if ia == nil {
+		return -1
+	}
+	for i := 0; i < system.Partynum; i++ {
+		if shares[i].Tag == nil {
+			return -1
+		}
+	}
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	value := big.NewInt(0)
+	for i := 0; i < system.Partynum; i++ {
+		value = value.Add(value, shares[i].Share)
+	}
+	t := new(big.Int).Add(value, shares[0].Delta)
+	t = t.Mod(t, system.Order)
+	for i := 0; i < system.Partynum; i++ {
+		tag := shares[i].Tag
+		delta := new(big.Int).Mul(system.Alphas[i], t)
+		delta = delta.Sub(shares[i].Gama, delta)
+		delta = delta.Mod(delta, system.Order)
+		parts := [][]byte{shares[i].Share.Bytes(), delta.Bytes()}
+		for j := 0; j < system.Partynum; j++ {
+			if j == i {
+				continue
+			}
+			dmac := new(big.Int).Mul(t, ia.alphaMacs[i][j])
+			dmac = dmac.Sub(tag.GamaMacs[j], dmac)
+			dmac = dmac.Mod(dmac, system.Order)
+			dkey := new(big.Int).Mul(t, ia.alphaKeys[i][j])
+			dkey = dkey.Sub(tag.GamaKeys[j], dkey)
+			parts = append(parts, tag.Macs[j].Bytes(), dmac.Bytes())
+			if system.macFp(ia.keys[j], shares[i].Share, tag.Keys[j]).Cmp(tag.Macs[j]) != 0 ||
+				system.macFp(ia.keys[j], delta, dkey).Cmp(dmac) != 0 {
+				system.revealTags(&wg, i, parts...)
+				return i
+			}
+		}
+		system.revealTags(&wg, i, parts...)
+	}
+	return -1
+}
+
+// groupIdentify is IdentifyFp for the shares of a curve.Group.
+func groupIdentify[E any, P curve.Group[E]](system *ShareSystem, shares []Share[E]) int {
+	ia := system.ia
+	if ia == nil {
+		return -1
+	}
+	for i := 0; i < system.Partynum; i++ {
+		if shares[i].Tag == nil {
+			return -1
+		}
+	}
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	t := P(new(E)).Set(shares[0].Delta)
+	for i := 0; i < system.Partynum; i++ {
+		t = P(t).Add(t, shares[i].Share)
+	}
+	for i := 0; i < system.Partynum; i++ {
+		tag := shares[i].Tag
+		delta := P(new(E)).ScalarMult(t, system.Alphas[i])
+		delta = P(delta).Add(shares[i].Gama, P(new(E)).Neg(delta))
+		parts := [][]byte{P(shares[i].Share).Marshal(), P(delta).Marshal()}
+		for j := 0; j < system.Partynum; j++ {
+			if j == i {
+				continue
+			}
+			dmac := P(new(E)).ScalarMult(t, ia.alphaMacs[i][j])
+			dmac = P(dmac).Add(tag.GamaMacs[j], P(new(E)).Neg(dmac))
+			dkey := P(new(E)).ScalarMult(t, ia.alphaKeys[i][j])
+			dkey = P(dkey).Add(tag.GamaKeys[j], P(new(E)).Neg(dkey))
+			parts = append(parts, P(tag.Macs[j]).Marshal(), P(dmac).Marshal())
+			if !curve.Equal[E, P](groupMac[E, P](ia.keys[j], shares[i].Share, tag.Keys[j]), tag.Macs[j]) ||
+				!curve.Equal[E, P](groupMac[E, P](ia.keys[j], delta, dkey), dmac) {
+				system.revealTags(&wg, i, parts...)
+				return i
+			}
+		}
+		system.revealTags(&wg, i, parts...)
+	}
+	return -1
+}
+
+func (system *ShareSystem) IdentifyG1(shares []Share_G1) int {
+	return groupIdentify(system, shares)
+}
+
+func (system *ShareSystem) IdentifyG2(shares []Share_G2) int {
+	return groupIdentify(system, shares)
+}
+
+func (system *ShareSystem) IdentifyGT(shares []Share_GT) int {
+	return groupIdentify(system, shares)
+}
+
+// OpenFp_IA opens shares like OpenFp but returns a *MacCheckError when the
+// MAC check fails. Its Cheater is the party found by IdentifyFp.
+func (system *ShareSystem) OpenFp_IA(shares []Share_Fp) (*big.Int, error) {
+	value, chk := system.OpenFp(shares)
+	if !chk {
+		return nil, &MacCheckError{Op: "OpenFp", Party: -1, Element: -1, Cheater: system.IdentifyFp(shares)}
+	}
+	return value, nil
+}
+
+func (system *ShareSystem) OpenG1_IA(shares []Share_G1) (*curve.G1, error) {
+	return groupOpen_IA(system, "OpenG1", shares)
+}
+
+func (system *ShareSystem) OpenG2_IA(shares []Share_G2) (*curve.G2, error) {
+	return groupOpen_IA(system, "OpenG2", shares)
+}
+
+func (system *ShareSystem) OpenGT_IA(shares []Share_GT) (*curve.GT, error) {
+	return groupOpen_IA(system, "OpenGT", shares)
+}
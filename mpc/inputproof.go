@@ -0,0 +1,179 @@
+package mpc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/big"
+	"sync"
+
+	"github.com/Oryx/curve"
+)
+
+// PedersenH is the second generator of the Pedersen commitments in G1. It is
+// mapped to the curve from a fixed string, so nobody knows its discrete
+// logarithm to Gen1.
+var PedersenH = curve.MapToG1([]byte("Oryx mpc Pedersen H"))
+
+// InputCommitment is what the owner of an input broadcasts when it shares it
+// with proof. C = x*Gen1 + rho*PedersenH commits to the input x, and
+// Shares[j] to the share x_j and blinding rho_j of party j, such that the
+// x_j add up to x and the rho_j to rho. T, Z1 and Z2 prove in zero knowledge
+// that the owner can open C, with the challenge derived by Fiat-Shamir.
+type InputCommitment struct {
+	C      *curve.G1
+	Shares []*curve.G1
+	T      *curve.G1
+	Z1     *big.Int
+	Z2     *big.Int
+}
+
+func pedersen(x, rho *big.Int) *curve.G1 {
+	c := new(curve.G1).ScalarBaseMult(x)
+	return c.Add(c, new(curve.G1).ScalarMult(PedersenH, rho))
+}
+
+// CommitInput splits element into n additive shares modulo order, commits to
+// each share and to element, and proves knowledge of the opening of the
+// latter. values[j] and blinds[j] are sent privately to party j.
+func CommitInput(owner, n int, element, order *big.Int) (com *InputCommitment, values, blinds []*big.Int) {
+	com = new(InputCommitment)
+	values = make([]*big.Int, n)
+	blinds = make([]*big.Int, n)
+	com.Shares = make([]*curve.G1, n)
+	x := new(big.Int).Mod(element, order)
+	rest := new(big.Int).Set(x)
+	rho := big.NewInt(0)
+	for j := 0; j < n; j++ {
+		if j < n-1 {
+			values[j], _ = rand.Int(rand.Reader, order)
+			rest = rest.Sub(rest, values[j])
+		} else {
+			values[j] = rest.Mod(rest, order)
+		}
+		blinds[j], _ = rand.Int(rand.Reader, order)
+		rho = rho.Add(rho, blinds[j])
+		com.Shares[j] = pedersen(values[j], blinds[j])
+	}
+	rho = rho.Mod(rho, order)
+	com.C = pedersen(x, rho)
+	a, _ := rand.Int(rand.Reader, order)
+	b, _ := rand.Int(rand.Reader, order)
+	com.T = pedersen(a, b)
+	e := com.challenge(owner, order)
+	com.Z1 = new(big.Int).Mul(e, x)
+	com.Z1 = com.Z1.Add(com.Z1, a)
+	com.Z1 = com.Z1.Mod(com.Z1, order)
+	com.Z2 = new(big.Int).Mul(e, rho)
+	com.Z2 = com.Z2.Add(com.Z2, b)
+	com.Z2 = com.Z2.Mod(com.Z2, order)
+	return com, values, blinds
+}
+
+// challenge hashes the owner and everything it committed to before the
+// response.
+func (com *InputCommitment) challenge(owner int, order *big.Int) *big.Int {
+	h := sha256.New()
+	var idx [8]byte
+	binary.BigEndian.PutUint64(idx[:], uint64(owner))
+	h.Write(idx[:])
+	h.Write(com.C.Marshal())
+	for _, s := range com.Shares {
+		h.Write(s.Marshal())
+	}
+	h.Write(com.T.Marshal())
+	e := new(big.Int).SetBytes(h.Sum(nil))
+	return e.Mod(e, order)
+}
+
+// Marshal encodes the commitment for the broadcast.
+func (com *InputCommitment) Marshal() []byte {
+	msg := com.C.Marshal()
+	for _, s := range com.Shares {
+		msg = append(msg, s.Marshal()...)
+	}
+	msg = append(msg, com.T.Marshal()...)
+	msg = append(msg, com.Z1.Bytes()...)
+	return append(msg, com.Z2.Bytes()...)
+}
+
+// Verify is the check of party party on the commitment of owner and the
+// share it received: the share opens Shares[party], the share commitments
+// add up to C, and the proof for C holds. It returns an *InputProofError
+// otherwise.
+func (com *InputCommitment) Verify(owner, party int, value, blind, order *big.Int) error {
+	fail := &InputProofError{Owner: owner, Party: party}
+	if party < 0 || party >= len(com.Shares) || com.C == nil || com.T == nil || com.Z1 == nil || com.Z2 == nil {
+		return fail
+	}
+	if value == nil || blind == nil || com.Shares[party] == nil ||
+		!equalG1(pedersen(value, blind), com.Shares[party]) {
+		return fail
+	}
+	sum := new(curve.G1).ScalarBaseMult(big.NewInt(0))
+	for _, s := range com.Shares {
+		if s == nil {
+			return fail
+		}
+		sum = sum.Add(sum, s)
+	}
+	if !equalG1(sum, com.C) {
+		return fail
+	}
+	e := com.challenge(owner, order)
+	rhs := new(curve.G1).ScalarMult(com.C, e)
+	rhs = rhs.Add(rhs, com.T)
+	if !equalG1(pedersen(com.Z1, com.Z2), rhs) {
+		return fail
+	}
+	return nil
+}
+
+func equalG1(a, b *curve.G1) bool {
+	return string(a.Marshal()) == string(b.Marshal())
+}
+
+// Share_An_Fp_Proven shares an input of party owner so that the others need
+// not trust it. The owner broadcasts an InputCommitment and sends every party
+// its share with the blinding; each party runs Verify before it accepts. The
+// verified shares are then MACed as they are, with the pairwise products of
+// the OfflinePhase once it is set up and otherwise by publishing them under a
+// MACed mask from the dealer as maskPRSS does, which hides the input from the
+// dealer only while it colludes with no party. On a failed check the
+// shares are dropped and the *InputProofError of the first party that
+// rejected is returned.
+//
+// Share_A_G1_Owner and the other group inputs need no proof: their shares
+// come from a MACed random value and one broadcast constant, so they are
+// consistent whatever the owner does.
+func (system *ShareSystem) Share_An_Fp_Proven(owner int, element *big.Int) (*[]Share_Fp, error) {
+	com, values, blinds := CommitInput(owner, system.Partynum, element, system.Order)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go system.Broadcast(&wg, owner, com.Marshal())
+	wg.Wait()
+	for j := 0; j < system.Partynum; j++ {
+		if j != owner {
+			system.transfer(owner, j, append(values[j].Bytes(), blinds[j].Bytes()...))
+		}
+	}
+	for j := 0; j < system.Partynum; j++ {
+		if err := com.Verify(owner, j, values[j], blinds[j], system.Order); err != nil {
+			return nil, err
+		}
+	}
+	if system.Offline != nil {
+		shares, err := system.Offline.authenticate(values)
+		if err != nil {
+			return nil, err
+		}
+		return &shares, nil
+	}
+	rshares, err := system.freshRandomShareFp()
+	if err != nil {
+		return nil, err
+	}
+	shares, d := maskPRSS(values, *rshares, system.Order, system.transfer)
+	shares[0].Tag = system.tagShift(shares[0].Tag, d)
+	return &shares, nil
+}
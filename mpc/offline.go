@@ -0,0 +1,634 @@
+package mpc
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/Oryx/network"
+	"github.com/Oryx/paillier"
+)
+
+// statistical security of the masks used in the pairwise products
+const statsec = 40
+
+var (
+	ErrPaillierKeySize = errors.New("mpc: paillier modulus too small for the share order")
+	ErrSacrifice       = errors.New("mpc: sacrifice check failed")
+)
+
+// OfflinePhase generates MACed triples and square pairs without a trusted
+// dealer. Every party samples its own shares and holds its own Paillier key.
+// A product x_i*y_j of values held by parties i and j is computed with
+// Gilboa's protocol: i sends Enc_i(x_i), j answers Enc_i(x_i*y_j + r) and
+// keeps -r, i decrypts. MACs are produced the same way from each party's own
+// share of alpha, so no party learns a triple or the MAC key. Every triple is
+// checked by sacrificing a second one before it is handed out.
+//
+// Proofs that ciphertexts are well formed are not implemented: a party that
+// deviates in a product is caught by the sacrifice, not before.
+type OfflinePhase struct {
+	Partynum  int
+	Order     *big.Int
+	Alphas    []*big.Int
+	keys      []*paillier.PrivateKey
+	encAlphas []*big.Int
+	mask      *big.Int
+	send      func(from, to int, msg []byte)
+
+	// set by enableTags in the identifiable-abort mode
+	tagKeys    []*big.Int
+	encTagKeys []*big.Int
+}
+
+// NewOfflinePhase runs the key setup of the offline phase: every party
+// generates a Paillier key of the given size and sends Enc(Alphas[i]) to the
+// others. send carries one message between two parties.
+func NewOfflinePhase(Partynum int, Order *big.Int, Alphas []*big.Int, bits int, send func(from, to int, msg []byte)) (*OfflinePhase, error) {
+	// x*y + r must not wrap around the Paillier modulus
+	mask := new(big.Int).Mul(Order, Order)
+	mask = mask.Lsh(mask, statsec)
+	if bits <= mask.BitLen()+1 {
+		return nil, ErrPaillierKeySize
+	}
+	phase := new(OfflinePhase)
+	phase.Partynum = Partynum
+	phase.Order = new(big.Int).Set(Order)
+	phase.Alphas = Alphas
+	phase.mask = mask
+	phase.send = send
+	phase.keys = make([]*paillier.PrivateKey, Partynum)
+	phase.encAlphas = make([]*big.Int, Partynum)
+	for i := 0; i < Partynum; i++ {
+		key, err := paillier.GenerateKey(rand.Reader, bits)
+		if err != nil {
+			return nil, err
+		}
+		phase.keys[i] = key
+		phase.broadcast(i, key.N.Bytes())
+		phase.encAlphas[i], err = key.Encrypt(Alphas[i])
+		if err != nil {
+			return nil, err
+		}
+		phase.broadcast(i, phase.encAlphas[i].Bytes())
+	}
+	return phase, nil
+}
+
+func (phase *OfflinePhase) broadcast(from int, msg []byte) {
+	for j := 0; j < phase.Partynum; j++ {
+		if j != from {
+			phase.send(from, j, msg)
+		}
+	}
+}
+
+// random lets every party sample its own share of a fresh value.
+func (phase *OfflinePhase) random() ([]*big.Int, error) {
+	values := make([]*big.Int, phase.Partynum)
+	for i := range values {
+		v, err := rand.Int(rand.Reader, phase.Order)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// cross answers a ciphertext cx = Enc_i(x) of party i with the local value y
+// of party j and returns the two parties' additive shares of x*y.
+func (phase *OfflinePhase) cross(i, j int, cx, y *big.Int) (*big.Int, *big.Int, error) {
+	key := phase.keys[i]
+	r, err := rand.Int(rand.Reader, phase.mask)
+	if err != nil {
+		return nil, nil, err
+	}
+	cr, err := key.Encrypt(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	c := key.Add(key.MulConst(cx, y), cr)
+	phase.send(j, i, c.Bytes())
+	si := key.Decrypt(c)
+	si = si.Mod(si, phase.Order)
+	sj := new(big.Int).Neg(r)
+	sj = sj.Mod(sj, phase.Order)
+	return si, sj, nil
+}
+
+// crossBatch is cross for many values y of party j at once. The values are
+// packed into slots of one plaintext, each wide enough for x*y plus its mask,
+// so that one ciphertext carries as many products as the Paillier modulus of
+// party i has room for.
+func (phase *OfflinePhase) crossBatch(i, j int, cx *big.Int, ys []*big.Int) ([]*big.Int, []*big.Int, error) {
+	key := phase.keys[i]
+	slot := uint(phase.mask.BitLen() + 1)
+	per := (key.N.BitLen() - 1) / int(slot)
+	if per < 1 {
+		per = 1
+	}
+	si := make([]*big.Int, len(ys))
+	sj := make([]*big.Int, len(ys))
+	full := new(big.Int).Lsh(one, slot)
+	full = full.Sub(full, one)
+	for start := 0; start < len(ys); start += per {
+		end := min(start+per, len(ys))
+		y := big.NewInt(0)
+		r := big.NewInt(0)
+		for k := end - 1; k >= start; k-- {
+			rk, err := rand.Int(rand.Reader, phase.mask)
+			if err != nil {
+				return nil, nil, err
+			}
+			y = y.Lsh(y, slot).Add(y, ys[k])
+			r = r.Lsh(r, slot).Add(r, rk)
+			sj[k] = new(big.Int).Neg(rk)
+			sj[k] = sj[k].Mod(sj[k], phase.Order)
+		}
+		cr, err := key.Encrypt(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		c := key.Add(key.MulConst(cx, y), cr)
+		phase.send(j, i, c.Bytes())
+		d := key.Decrypt(c)
+		for k := start; k < end; k++ {
+			si[k] = new(big.Int).And(d, full)
+			si[k] = si[k].Mod(si[k], phase.Order)
+			d = d.Rsh(d, slot)
+		}
+	}
+	return si, sj, nil
+}
+
+// product returns additive shares of (sum x)*(sum y).
+func (phase *OfflinePhase) product(x, y []*big.Int) ([]*big.Int, error) {
+	z := make([]*big.Int, phase.Partynum)
+	for i := 0; i < phase.Partynum; i++ {
+		z[i] = new(big.Int).Mul(x[i], y[i])
+	}
+	for i := 0; i < phase.Partynum; i++ {
+		cx, err := phase.keys[i].Encrypt(x[i])
+		if err != nil {
+			return nil, err
+		}
+		phase.broadcast(i, cx.Bytes())
+		for j := 0; j < phase.Partynum; j++ {
+			if j == i {
+				continue
+			}
+			si, sj, err := phase.cross(i, j, cx, y[j])
+			if err != nil {
+				return nil, err
+			}
+			z[i] = z[i].Add(z[i], si)
+			z[j] = z[j].Add(z[j], sj)
+		}
+	}
+	for i := range z {
+		z[i] = z[i].Mod(z[i], phase.Order)
+	}
+	return z, nil
+}
+
+// authenticate turns additive shares of v into MACed shares, tagged once
+// enableTags has been called.
+func (phase *OfflinePhase) authenticate(v []*big.Int) ([]Share_Fp, error) {
+	shares, err := phase.authenticateBatch([][]*big.Int{v})
+	if err != nil {
+		return nil, err
+	}
+	return shares[0], nil
+}
+
+// authenticateBatch is authenticate for many values, whose MACs are computed
+// together by macBatch.
+func (phase *OfflinePhase) authenticateBatch(vs [][]*big.Int) ([][]Share_Fp, error) {
+	shares, err := phase.macBatch(vs)
+	if err != nil {
+		return nil, err
+	}
+	if err := phase.tag(shares...); err != nil {
+		return nil, err
+	}
+	return shares, nil
+}
+
+// mac turns additive shares of v into MACed shares.
+func (phase *OfflinePhase) mac(v []*big.Int) ([]Share_Fp, error) {
+	shares, err := phase.macBatch([][]*big.Int{v})
+	if err != nil {
+		return nil, err
+	}
+	return shares[0], nil
+}
+
+// macBatch turns additive shares of the values vs into MACed shares. The
+// shares of alpha*v come from the pairwise products with Enc(Alphas[i]),
+// packed by crossBatch; party 0 picks and broadcasts the public Deltas in one
+// message.
+func (phase *OfflinePhase) macBatch(vs [][]*big.Int) ([][]Share_Fp, error) {
+	n := phase.Partynum
+	Deltas := make([]*big.Int, len(vs))
+	msgs := make([][]byte, len(vs))
+	macs := make([][]*big.Int, len(vs))
+	for k, v := range vs {
+		Delta, err := rand.Int(rand.Reader, phase.Order)
+		if err != nil {
+			return nil, err
+		}
+		Deltas[k] = Delta
+		msgs[k] = Delta.Bytes()
+		macs[k] = make([]*big.Int, n)
+		for i := 0; i < n; i++ {
+			macs[k][i] = new(big.Int).Mul(phase.Alphas[i], v[i])
+		}
+	}
+	phase.broadcast(0, network.Pack(msgs...))
+	ys := make([]*big.Int, len(vs))
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if j == i {
+				continue
+			}
+			for k, v := range vs {
+				ys[k] = v[j]
+			}
+			si, sj, err := phase.crossBatch(i, j, phase.encAlphas[i], ys)
+			if err != nil {
+				return nil, err
+			}
+			for k := range vs {
+				macs[k][i] = macs[k][i].Add(macs[k][i], si[k])
+				macs[k][j] = macs[k][j].Add(macs[k][j], sj[k])
+			}
+		}
+	}
+	shares := make([][]Share_Fp, len(vs))
+	for k, v := range vs {
+		shares[k] = make([]Share_Fp, n)
+		for i := 0; i < n; i++ {
+			Gama := new(big.Int).Mul(phase.Alphas[i], Deltas[k])
+			Gama = Gama.Add(Gama, macs[k][i])
+			shares[k][i].Index = i
+			shares[k][i].Share = v[i]
+			shares[k][i].Gama = Gama.Mod(Gama, phase.Order)
+			shares[k][i].Delta = Deltas[k]
+		}
+	}
+	return shares, nil
+}
+
+// enableTags lets authenticate, Triplet and SquarePair attach the pairwise
+// MACs of the identifiable-abort mode under the global keys of the parties.
+// Every party j sends Enc_j(keys[j]), so the MAC of a share of party i under
+// keys[j] is one more pairwise product.
+func (phase *OfflinePhase) enableTags(keys []*big.Int) error {
+	encKeys := make([]*big.Int, phase.Partynum)
+	for j := 0; j < phase.Partynum; j++ {
+		c, err := phase.keys[j].Encrypt(keys[j])
+		if err != nil {
+			return err
+		}
+		phase.broadcast(j, c.Bytes())
+		encKeys[j] = c
+	}
+	phase.tagKeys = keys
+	phase.encTagKeys = encKeys
+	return nil
+}
+
+// pairMacs returns, for the value x[i] of every party i, the MACs
+// macs[i][j] = keys[j]*x[i] + locals[i][j] held by party i and the local keys
+// locals[i][j] held by party j.
+func (phase *OfflinePhase) pairMacs(x []*big.Int) ([][]*big.Int, [][]*big.Int, error) {
+	n := phase.Partynum
+	macs := make([][]*big.Int, n)
+	locals := make([][]*big.Int, n)
+	for i := 0; i < n; i++ {
+		macs[i] = make([]*big.Int, n)
+		locals[i] = make([]*big.Int, n)
+		for j := 0; j < n; j++ {
+			if j == i {
+				k, err := rand.Int(rand.Reader, phase.Order)
+				if err != nil {
+					return nil, nil, err
+				}
+				mac := new(big.Int).Mul(phase.tagKeys[i], x[i])
+				mac = mac.Add(mac, k)
+				macs[i][i] = mac.Mod(mac, phase.Order)
+				locals[i][i] = k
+				continue
+			}
+			sj, si, err := phase.cross(j, i, phase.encTagKeys[j], x[i])
+			if err != nil {
+				return nil, nil, err
+			}
+			macs[i][j] = si
+			k := new(big.Int).Neg(sj)
+			locals[i][j] = k.Mod(k, phase.Order)
+		}
+	}
+	return macs, locals, nil
+}
+
+// tag attaches the pairwise MACs to the shares; it does nothing before
+// enableTags.
+func (phase *OfflinePhase) tag(vecs ...[]Share_Fp) error {
+	if phase.tagKeys == nil {
+		return nil
+	}
+	n := phase.Partynum
+	for _, shares := range vecs {
+		values := make([]*big.Int, n)
+		gamas := make([]*big.Int, n)
+		for i := 0; i < n; i++ {
+			values[i] = shares[i].Share
+			gamas[i] = shares[i].Gama
+		}
+		macs, keys, err := phase.pairMacs(values)
+		if err != nil {
+			return err
+		}
+		gamaMacs, gamaKeys, err := phase.pairMacs(gamas)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < n; i++ {
+			shares[i].Tag = &Tag_Fp{macs[i], keys[i], gamaMacs[i], gamaKeys[i]}
+		}
+	}
+	return nil
+}
+
+func (phase *OfflinePhase) triplet() ([]Share_Fp, []Share_Fp, []Share_Fp, error) {
+	a, err := phase.random()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	b, err := phase.random()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	c, err := phase.product(a, b)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	sharesA, err := phase.mac(a)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	sharesB, err := phase.mac(b)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	sharesC, err := phase.mac(c)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return sharesA, sharesB, sharesC, nil
+}
+
+func (phase *OfflinePhase) squarePair() ([]Share_Fp, []Share_Fp, error) {
+	a, err := phase.random()
+	if err != nil {
+		return nil, nil, err
+	}
+	b, err := phase.product(a, a)
+	if err != nil {
+		return nil, nil, err
+	}
+	sharesA, err := phase.mac(a)
+	if err != nil {
+		return nil, nil, err
+	}
+	sharesB, err := phase.mac(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sharesA, sharesB, nil
+}
+
+// Triplet returns a MACed triple (a, b, a*b). A second triple (f, g, h) is
+// generated and sacrificed: with a random public t the parties open
+// rho = t*a - f and sigma = b - g, and check that
+// t*c - h - sigma*f - rho*g - sigma*rho opens to zero.
+func (phase *OfflinePhase) Triplet() ([]Share_Fp, []Share_Fp, []Share_Fp, error) {
+	a, b, c, err := phase.triplet()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	f, g, h, err := phase.triplet()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	t, err := phase.coin()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	rho, ok := phase.open(phase.combine([]*big.Int{t, big.NewInt(-1)}, a, f))
+	if !ok {
+		return nil, nil, nil, ErrSacrifice
+	}
+	sigma, ok := phase.open(phase.combine([]*big.Int{one, big.NewInt(-1)}, b, g))
+	if !ok {
+		return nil, nil, nil, ErrSacrifice
+	}
+	coeffs := []*big.Int{t, big.NewInt(-1), new(big.Int).Neg(sigma), new(big.Int).Neg(rho)}
+	z := phase.combine(coeffs, c, h, f, g)
+	z = phase.addConst(z, new(big.Int).Neg(new(big.Int).Mul(sigma, rho)))
+	res, ok := phase.open(z)
+	if !ok || res.Sign() != 0 {
+		return nil, nil, nil, ErrSacrifice
+	}
+	if err := phase.tag(a, b, c); err != nil {
+		return nil, nil, nil, err
+	}
+	return a, b, c, nil
+}
+
+// SquarePair returns a MACed pair (a, a^2). A second pair (f, h) is
+// sacrificed: with rho = t*a - f opened, t^2*b - h - rho*(t*a + f) must open
+// to zero.
+func (phase *OfflinePhase) SquarePair() ([]Share_Fp, []Share_Fp, error) {
+	a, b, err := phase.squarePair()
+	if err != nil {
+		return nil, nil, err
+	}
+	f, h, err := phase.squarePair()
+	if err != nil {
+		return nil, nil, err
+	}
+	t, err := phase.coin()
+	if err != nil {
+		return nil, nil, err
+	}
+	rho, ok := phase.open(phase.combine([]*big.Int{t, big.NewInt(-1)}, a, f))
+	if !ok {
+		return nil, nil, ErrSacrifice
+	}
+	t2 := new(big.Int).Mul(t, t)
+	rhot := new(big.Int).Mul(rho, t)
+	coeffs := []*big.Int{t2, big.NewInt(-1), rhot.Neg(rhot), new(big.Int).Neg(rho)}
+	z := phase.combine(coeffs, b, h, a, f)
+	res, ok := phase.open(z)
+	if !ok || res.Sign() != 0 {
+		return nil, nil, ErrSacrifice
+	}
+	if err := phase.tag(a, b); err != nil {
+		return nil, nil, err
+	}
+	return a, b, nil
+}
+
+// coin samples a public random value with commitCoin.
+func (phase *OfflinePhase) coin() (*big.Int, error) {
+	return commitCoin(phase.Partynum, phase.Order, phase.broadcast)
+}
+
+// commitCoin samples a public random value modulo order: every party commits
+// to a random contribution, then all of them are opened and summed, so no
+// party can choose its contribution after seeing the others. broadcast sends
+// a message of party i to all other parties.
+func commitCoin(partynum int, order *big.Int, broadcast func(i int, msg []byte)) (*big.Int, error) {
+	t := big.NewInt(0)
+	for i := 0; i < partynum; i++ {
+		v, err := rand.Int(rand.Reader, order)
+		if err != nil {
+			return nil, err
+		}
+		commit, r := Com(v.Bytes())
+		broadcast(i, commit)
+		broadcast(i, r.Bytes())
+		broadcast(i, v.Bytes())
+		t = t.Add(t, v)
+	}
+	return t.Mod(t, order), nil
+}
+
+// combine returns the shares of sum coeffs[k]*vecs[k].
+func (phase *OfflinePhase) combine(coeffs []*big.Int, vecs ...[]Share_Fp) []Share_Fp {
+	shares := make([]Share_Fp, phase.Partynum)
+	for i := 0; i < phase.Partynum; i++ {
+		shares[i].Index = i
+		shares[i].Share = big.NewInt(0)
+		shares[i].Gama = big.NewInt(0)
+		shares[i].Delta = big.NewInt(0)
+		for k, vec := range vecs {
+			shares[i].Share.Add(shares[i].Share, new(big.Int).Mul(coeffs[k], vec[i].Share))
+			shares[i].Gama.Add(shares[i].Gama, new(big.Int).Mul(coeffs[k], vec[i].Gama))
+			shares[i].Delta.Add(shares[i].Delta, new(big.Int).Mul(coeffs[k], vec[i].Delta))
+		}
+		shares[i].Share.Mod(shares[i].Share, phase.Order)
+		shares[i].Gama.Mod(shares[i].Gama, phase.Order)
+		shares[i].Delta.Mod(shares[i].Delta, phase.Order)
+	}
+	return shares
+}
+
+// addConst adds a public constant: party 0 adds it to its share, every party
+// adds Alphas[i]*c to its MAC share.
+func (phase *OfflinePhase) addConst(shares []Share_Fp, c *big.Int) []Share_Fp {
+	for i := range shares {
+		if i == 0 {
+			shares[i].Share = shares[i].Share.Add(shares[i].Share, c)
+			shares[i].Share = shares[i].Share.Mod(shares[i].Share, phase.Order)
+		}
+		mac := new(big.Int).Mul(phase.Alphas[i], c)
+		shares[i].Gama = shares[i].Gama.Add(shares[i].Gama, mac)
+		shares[i].Gama = shares[i].Gama.Mod(shares[i].Gama, phase.Order)
+	}
+	return shares
+}
+
+// open broadcasts the shares and runs the commit-and-open MAC check.
+func (phase *OfflinePhase) open(shares []Share_Fp) (*big.Int, bool) {
+	value := big.NewInt(0)
+	for i := 0; i < phase.Partynum; i++ {
+		phase.broadcast(i, shares[i].Share.Bytes())
+		value = value.Add(value, shares[i].Share)
+	}
+	value = value.Mod(value, phase.Order)
+	t := new(big.Int).Add(value, shares[0].Delta)
+	chk := big.NewInt(0)
+	for i := 0; i < phase.Partynum; i++ {
+		delta := new(big.Int).Mul(phase.Alphas[i], t)
+		delta = delta.Sub(shares[i].Gama, delta)
+		delta = delta.Mod(delta, phase.Order)
+		commit, r := Com(delta.Bytes())
+		phase.broadcast(i, commit)
+		phase.broadcast(i, r.Bytes())
+		phase.broadcast(i, delta.Bytes())
+		if !OpenComit(delta.Bytes(), commit, r) {
+			return value, false
+		}
+		chk = chk.Add(chk, delta)
+	}
+	chk = chk.Mod(chk, phase.Order)
+	return value, chk.Sign() == 0
+}
+
+// offlineTransfer carries one offline message between two parties and
+// accounts it in OfflineCom.
+func (system *ShareSystem) offlineTransfer(from, to int, msg []byte) {
+	atomic.AddInt64(&system.OfflineCom, int64(len(msg)))
+	if system.isWAN && system.BandwidthCtrl != nil {
+		system.BandwidthCtrl.SimulateSend(msg)
+	}
+	system.failure.Record(network.Deliver(system.Net, from, to, msg))
+}
+
+func (system *ECCShareSystem) offlineTransfer(from, to int, msg []byte) {
+	atomic.AddInt64(&system.OfflineCom, int64(len(msg)))
+	if system.isWAN && system.BandwidthCtrl != nil {
+		system.BandwidthCtrl.SimulateSend(msg)
+	}
+	system.failure.Record(network.Deliver(system.Net, from, to, msg))
+}
+
+// SetupOfflinePhase switches GenTriplets, GenTriplets_for_Exp and
+// GenSquarePair from the trusted dealer to the OfflinePhase protocol. bits is
+// the Paillier modulus size, e.g. 2048.
+func (system *ShareSystem) SetupOfflinePhase(bits int) error {
+	offline, err := NewOfflinePhase(system.Partynum, system.Order, system.Alphas, bits, system.offlineTransfer)
+	if err != nil {
+		return err
+	}
+	offlineMul, err := NewOfflinePhase(system.Partynum, system.OrderMul, system.AlphasMul, bits, system.offlineTransfer)
+	if err != nil {
+		return err
+	}
+	system.Offline = offline
+	system.OfflineMul = offlineMul
+	if system.ia != nil {
+		return system.tagOffline()
+	}
+	return nil
+}
+
+func (system *ECCShareSystem) SetupOfflinePhase(bits int) error {
+	offline, err := NewOfflinePhase(system.Partynum, system.Order, system.Alphas, bits, system.offlineTransfer)
+	if err != nil {
+		return err
+	}
+	system.Offline = offline
+	return nil
+}
+
+func offlineTriplets(phase *OfflinePhase) (*[]Share_Fp, *[]Share_Fp, *[]Share_Fp, error) {
+	sharesA, sharesB, sharesC, err := phase.Triplet()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return &sharesA, &sharesB, &sharesC, nil
+}
+
+func offlineSquarePair(phase *OfflinePhase) (*[]Share_Fp, *[]Share_Fp, error) {
+	sharesA, sharesB, err := phase.SquarePair()
+	if err != nil {
+		return nil, nil, err
+	}
+	return &sharesA, &sharesB, nil
+}
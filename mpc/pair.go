@@ -10,6 +10,7 @@ func (system *ShareSystem) share_Pair_P_1(g1shares Share_G1, g2 *curve.G2) Share
 	shares.Delta = curve.Pair(g1shares.Delta, g2)
 	shares.Gama = curve.Pair(g1shares.Gama, g2)
 	shares.Share = curve.Pair(g1shares.Share, g2)
+	shares.Tag = tag_Pair_P_1(g1shares.Tag, g2)
 	return *shares
 }
 
@@ -27,6 +28,7 @@ func (system *ShareSystem) share_Pair_P_2(g1 *curve.G1, g2shares Share_G2) Share
 	shares.Delta = curve.Pair(g1, g2shares.Delta)
 	shares.Gama = curve.Pair(g1, g2shares.Gama)
 	shares.Share = curve.Pair(g1, g2shares.Share)
+	shares.Tag = tag_Pair_P_2(g1, g2shares.Tag)
 	return *shares
 }
 
@@ -39,7 +41,10 @@ func (system *ShareSystem) Pair_P_2(g1 *curve.G1, g2shares *[]Share_G2) *[]Share
 }
 
 func (system *ShareSystem) Pair_S(g1shares *[]Share_G1, g2shares *[]Share_G2) *[]Share_GT {
-	sharesA, sharesB, sharesC := system.GenTriplets()
+	sharesA, sharesB, sharesC, err := system.GenTriplets()
+	if err != nil {
+		panic(err)
+	}
 	sharesgA := system.EXP_P_G1_1(curve.Gen1, sharesA)
 	sharesgB := system.EXP_P_G2_1(curve.Gen2, sharesB)
 	sharesgC := system.EXP_P_G1_1(curve.Gen1, sharesC)
@@ -0,0 +1,415 @@
+package mpc
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/Oryx/network"
+)
+
+const (
+	poolMagic   = "OXPP"
+	PoolVersion = 1
+)
+
+var (
+	ErrPoolFormat  = errors.New("mpc: not a preprocessing pool file")
+	ErrPoolVersion = errors.New("mpc: unsupported preprocessing pool version")
+	ErrPoolParties = errors.New("mpc: preprocessing pool files do not match")
+)
+
+// MaxPoolParties bounds the party count read from a pool file.
+const MaxPoolParties = 1 << 16
+
+// PoolExhaustedError is returned when a Pool holds fewer items of a kind than
+// a computation needs.
+type PoolExhaustedError struct {
+	Kind string
+	Need int
+	Have int
+}
+
+func (e *PoolExhaustedError) Error() string {
+	return fmt.Sprintf("mpc: preprocessing pool exhausted: need %d %s, have %d", e.Need, e.Kind, e.Have)
+}
+
+// Pool holds preprocessed triples, square pairs and random shares generated
+// ahead of the online phase. Once attached to a ShareSystem, GenTriplets,
+// GenSquarePair and RandomShareFp take their material from it instead of
+// generating it on the fly. When the pool runs out they return a
+// *PoolExhaustedError, and Err reports the first item the pool could not
+// serve.
+type Pool struct {
+	Partynum    int
+	Triplets    []TripletShares
+	SquarePairs []SquarePairShares
+	Randoms     [](*[]Share_Fp)
+	err         error
+	mu          sync.Mutex
+}
+
+// PartyPool is the part of a Pool that belongs to one party.
+type PartyPool struct {
+	Index       int
+	Partynum    int
+	Triplets    [][3]Share_Fp
+	SquarePairs [][2]Share_Fp
+	Randoms     []Share_Fp
+}
+
+// GenPool generates material in bulk. It uses the same source as
+// GenTriplets, so the dealer, the offline phase and the sacrifice check all
+// apply; the traffic is accounted in OfflineCom. It returns the first error
+// of that source, e.g. a *SacrificeError.
+func (system *ShareSystem) GenPool(triplets, squarepairs, randoms int) (*Pool, error) {
+	var wg sync.WaitGroup
+	pool := new(Pool)
+	pool.Partynum = system.Partynum
+	pool.Triplets = make([]TripletShares, triplets)
+	pool.SquarePairs = make([]SquarePairShares, squarepairs)
+	pool.Randoms = make([](*[]Share_Fp), randoms)
+	errs := make([]error, triplets+squarepairs+randoms)
+	for k := 0; k < triplets; k++ {
+		wg.Add(1)
+		go func(k int) {
+			defer wg.Done()
+			t := &pool.Triplets[k]
+			t.A, t.B, t.C, errs[k] = system.freshTriplets()
+		}(k)
+	}
+	for k := 0; k < squarepairs; k++ {
+		wg.Add(1)
+		go func(k int) {
+			defer wg.Done()
+			p := &pool.SquarePairs[k]
+			p.A, p.B, errs[triplets+k] = system.freshSquarePair()
+		}(k)
+	}
+	for k := 0; k < randoms; k++ {
+		wg.Add(1)
+		go func(k int) {
+			defer wg.Done()
+			pool.Randoms[k], errs[triplets+squarepairs+k] = system.freshRandomShareFp()
+		}(k)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pool, nil
+}
+
+// UsePool attaches a pool to the system; nil detaches it.
+func (system *ShareSystem) UsePool(pool *Pool) {
+	system.Pool = pool
+}
+
+// Remaining returns the number of unused triples, square pairs and random
+// shares.
+func (pool *Pool) Remaining() (int, int, int) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	return len(pool.Triplets), len(pool.SquarePairs), len(pool.Randoms)
+}
+
+// Require returns a *PoolExhaustedError if the pool cannot serve the given
+// numbers of items.
+func (pool *Pool) Require(triplets, squarepairs, randoms int) error {
+	t, s, r := pool.Remaining()
+	if triplets > t {
+		return &PoolExhaustedError{Kind: "triples", Need: triplets, Have: t}
+	}
+	if squarepairs > s {
+		return &PoolExhaustedError{Kind: "square pairs", Need: squarepairs, Have: s}
+	}
+	if randoms > r {
+		return &PoolExhaustedError{Kind: "random shares", Need: randoms, Have: r}
+	}
+	return nil
+}
+
+// Err returns the *PoolExhaustedError of the first item the pool could not
+// serve, or nil.
+func (pool *Pool) Err() error {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	return pool.err
+}
+
+// exhausted records and returns a *PoolExhaustedError. The caller holds mu.
+func (pool *Pool) exhausted(kind string) error {
+	err := &PoolExhaustedError{Kind: kind, Need: 1}
+	if pool.err == nil {
+		pool.err = err
+	}
+	return err
+}
+
+func (pool *Pool) Triplet() (TripletShares, error) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	if len(pool.Triplets) == 0 {
+		return TripletShares{}, pool.exhausted("triples")
+	}
+	t := pool.Triplets[0]
+	pool.Triplets = pool.Triplets[1:]
+	return t, nil
+}
+
+func (pool *Pool) SquarePair() (SquarePairShares, error) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	if len(pool.SquarePairs) == 0 {
+		return SquarePairShares{}, pool.exhausted("square pairs")
+	}
+	p := pool.SquarePairs[0]
+	pool.SquarePairs = pool.SquarePairs[1:]
+	return p, nil
+}
+
+func (pool *Pool) RandomShareFp() (*[]Share_Fp, error) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	if len(pool.Randoms) == 0 {
+		return nil, pool.exhausted("random shares")
+	}
+	r := pool.Randoms[0]
+	pool.Randoms = pool.Randoms[1:]
+	return r, nil
+}
+
+// Party extracts the shares of party index.
+func (pool *Pool) Party(index int) *PartyPool {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	party := new(PartyPool)
+	party.Index = index
+	party.Partynum = pool.Partynum
+	party.Triplets = make([][3]Share_Fp, len(pool.Triplets))
+	for k, t := range pool.Triplets {
+		party.Triplets[k] = [3]Share_Fp{(*t.A)[index], (*t.B)[index], (*t.C)[index]}
+	}
+	party.SquarePairs = make([][2]Share_Fp, len(pool.SquarePairs))
+	for k, p := range pool.SquarePairs {
+		party.SquarePairs[k] = [2]Share_Fp{(*p.A)[index], (*p.B)[index]}
+	}
+	party.Randoms = make([]Share_Fp, len(pool.Randoms))
+	for k, r := range pool.Randoms {
+		party.Randoms[k] = (*r)[index]
+	}
+	return party
+}
+
+// MergePool reassembles a Pool from the parts of all parties, ordered by
+// party index.
+func MergePool(parties []*PartyPool) (*Pool, error) {
+	if len(parties) == 0 {
+		return nil, ErrPoolParties
+	}
+	first := parties[0]
+	for i, party := range parties {
+		if party.Index != i || party.Partynum != len(parties) ||
+			len(party.Triplets) != len(first.Triplets) ||
+			len(party.SquarePairs) != len(first.SquarePairs) ||
+			len(party.Randoms) != len(first.Randoms) {
+			return nil, ErrPoolParties
+		}
+	}
+	n := len(parties)
+	pool := new(Pool)
+	pool.Partynum = n
+	pool.Triplets = make([]TripletShares, len(first.Triplets))
+	for k := range pool.Triplets {
+		a, b, c := make([]Share_Fp, n), make([]Share_Fp, n), make([]Share_Fp, n)
+		for i, party := range parties {
+			a[i], b[i], c[i] = party.Triplets[k][0], party.Triplets[k][1], party.Triplets[k][2]
+		}
+		pool.Triplets[k] = TripletShares{A: &a, B: &b, C: &c}
+	}
+	pool.SquarePairs = make([]SquarePairShares, len(first.SquarePairs))
+	for k := range pool.SquarePairs {
+		a, b := make([]Share_Fp, n), make([]Share_Fp, n)
+		for i, party := range parties {
+			a[i], b[i] = party.SquarePairs[k][0], party.SquarePairs[k][1]
+		}
+		pool.SquarePairs[k] = SquarePairShares{A: &a, B: &b}
+	}
+	pool.Randoms = make([](*[]Share_Fp), len(first.Randoms))
+	for k := range pool.Randoms {
+		r := make([]Share_Fp, n)
+		for i, party := range parties {
+			r[i] = party.Randoms[k]
+		}
+		pool.Randoms[k] = &r
+	}
+	return pool, nil
+}
+
+// WriteTo writes the party's material as: the magic "OXPP", a uint16
+// version, uint32 index, party count and the three item counts, followed by
+// one length-prefixed MarshalFp frame per share. All integers are big endian.
+func (party *PartyPool) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	cw := &countWriter{w: bw}
+	header := make([]byte, 0, 26)
+	header = append(header, poolMagic...)
+	header = binary.BigEndian.AppendUint16(header, PoolVersion)
+	header = binary.BigEndian.AppendUint32(header, uint32(party.Index))
+	header = binary.BigEndian.AppendUint32(header, uint32(party.Partynum))
+	header = binary.BigEndian.AppendUint32(header, uint32(len(party.Triplets)))
+	header = binary.BigEndian.AppendUint32(header, uint32(len(party.SquarePairs)))
+	header = binary.BigEndian.AppendUint32(header, uint32(len(party.Randoms)))
+	if _, err := cw.Write(header); err != nil {
+		return cw.n, err
+	}
+	write := func(shares ...Share_Fp) error {
+		for _, share := range shares {
+			if err := network.WriteFrame(cw, MarshalFp(share)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, t := range party.Triplets {
+		if err := write(t[:]...); err != nil {
+			return cw.n, err
+		}
+	}
+	for _, p := range party.SquarePairs {
+		if err := write(p[:]...); err != nil {
+			return cw.n, err
+		}
+	}
+	if err := write(party.Randoms...); err != nil {
+		return cw.n, err
+	}
+	return cw.n, bw.Flush()
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// ReadPartyPool reads what WriteTo wrote. The counts in the header are not
+// trusted: the party count must be at most MaxPoolParties and the slices grow
+// only as shares are actually read.
+func ReadPartyPool(r io.Reader) (*PartyPool, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, 26)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, ErrPoolFormat
+	}
+	if string(header[:4]) != poolMagic {
+		return nil, ErrPoolFormat
+	}
+	if binary.BigEndian.Uint16(header[4:6]) != PoolVersion {
+		return nil, ErrPoolVersion
+	}
+	index := binary.BigEndian.Uint32(header[6:10])
+	partynum := binary.BigEndian.Uint32(header[10:14])
+	if partynum == 0 || partynum > MaxPoolParties || index >= partynum {
+		return nil, ErrPoolFormat
+	}
+	party := new(PartyPool)
+	party.Index = int(index)
+	party.Partynum = int(partynum)
+	read := func(shares []Share_Fp) error {
+		for k := range shares {
+			msg, err := network.ReadFrame(br)
+			if err != nil {
+				return err
+			}
+			shares[k], err = UnmarshalFp(msg, party.Index)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for k := binary.BigEndian.Uint32(header[14:18]); k > 0; k-- {
+		var t [3]Share_Fp
+		if err := read(t[:]); err != nil {
+			return nil, err
+		}
+		party.Triplets = append(party.Triplets, t)
+	}
+	for k := binary.BigEndian.Uint32(header[18:22]); k > 0; k-- {
+		var p [2]Share_Fp
+		if err := read(p[:]); err != nil {
+			return nil, err
+		}
+		party.SquarePairs = append(party.SquarePairs, p)
+	}
+	for k := binary.BigEndian.Uint32(header[22:26]); k > 0; k-- {
+		var r [1]Share_Fp
+		if err := read(r[:]); err != nil {
+			return nil, err
+		}
+		party.Randoms = append(party.Randoms, r[0])
+	}
+	return party, nil
+}
+
+func poolFile(dir string, index int) string {
+	return filepath.Join(dir, fmt.Sprintf("party%d.pool", index))
+}
+
+// Save writes one file per party into dir.
+func (pool *Pool) Save(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for i := 0; i < pool.Partynum; i++ {
+		f, err := os.Create(poolFile(dir, i))
+		if err != nil {
+			return err
+		}
+		_, err = pool.Party(i).WriteTo(f)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadPartyPool reads the file of one party from dir, as a distributed party
+// would.
+func LoadPartyPool(dir string, index int) (*PartyPool, error) {
+	f, err := os.Open(poolFile(dir, index))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadPartyPool(f)
+}
+
+// LoadPool reads the files of all parties from dir and merges them.
+func LoadPool(dir string, Partynum int) (*Pool, error) {
+	parties := make([]*PartyPool, Partynum)
+	for i := range parties {
+		party, err := LoadPartyPool(dir, i)
+		if err != nil {
+			return nil, err
+		}
+		parties[i] = party
+	}
+	return MergePool(parties)
+}
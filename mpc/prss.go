@@ -0,0 +1,299 @@
+package mpc
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/Oryx/curve"
+)
+
+var ErrNoPRSS = errors.New("mpc: PRSS keys are not set up")
+
+// PRSS is pseudo-random secret sharing. In a one-time setup every party picks
+// a PRF key of its own; the k-th random value is then the sum over the
+// parties of PRF(key_i, k), and party i computes its share PRF(key_i, k)
+// locally. This is replicated-key PRSS for n-1 corruptions, where every set
+// of parties outside a corrupted one is a single party, so as long as one
+// party is honest no coalition of the others knows the value. How the MACs
+// are added decides whether the dealer may learn it; see RandomShareFp_PRSS.
+type PRSS struct {
+	Partynum int
+	Order    *big.Int
+	// keys[i] is known to party i only.
+	keys [][]byte
+	next uint64
+}
+
+// NewPRSS runs the key setup. The keys never leave their owners, so no
+// message is sent. It returns the error of the random source.
+func NewPRSS(Partynum int, Order *big.Int) (*PRSS, error) {
+	prss := new(PRSS)
+	prss.Partynum = Partynum
+	prss.Order = new(big.Int).Set(Order)
+	prss.keys = make([][]byte, Partynum)
+	for i := 0; i < Partynum; i++ {
+		prss.keys[i] = make([]byte, sha256.Size)
+		if _, err := rand.Read(prss.keys[i]); err != nil {
+			return nil, err
+		}
+	}
+	return prss, nil
+}
+
+// prf expands HMAC-SHA256(key, k) to twice the bit length of Order before
+// reducing, so the result is statistically close to uniform.
+func (prss *PRSS) prf(key []byte, k uint64) *big.Int {
+	var out []byte
+	msg := make([]byte, 9)
+	binary.BigEndian.PutUint64(msg, k)
+	for block := byte(0); len(out)*8 < 2*prss.Order.BitLen(); block++ {
+		msg[8] = block
+		mac := hmac.New(sha256.New, key)
+		mac.Write(msg)
+		out = mac.Sum(out)
+	}
+	v := new(big.Int).SetBytes(out)
+	return v.Mod(v, prss.Order)
+}
+
+// Reserve returns the first of n consecutive indices that no earlier call
+// returned. The indices name the random values, so the parties only agree on
+// them if each calls Reserve in the same order; it belongs in the sequential
+// part of a protocol, before any goroutines draw from the block, and is not
+// safe for concurrent use.
+func (prss *PRSS) Reserve(n int) uint64 {
+	k := prss.next
+	prss.next += uint64(n)
+	return k
+}
+
+// Shares returns the additive shares of the random value with index k;
+// element i is what party i derives from its key.
+func (prss *PRSS) Shares(k uint64) []*big.Int {
+	values := make([]*big.Int, prss.Partynum)
+	for i := 0; i < prss.Partynum; i++ {
+		values[i] = prss.prf(prss.keys[i], k)
+	}
+	return values
+}
+
+// SetupPRSS enables RandomShareFp_PRSS and RandomShareG1_PRSS. It returns
+// the error of the random source.
+func (system *ShareSystem) SetupPRSS() error {
+	prss, err := NewPRSS(system.Partynum, system.Order)
+	if err != nil {
+		return err
+	}
+	system.PRSS = prss
+	return nil
+}
+
+func (system *ECCShareSystem) SetupPRSS() error {
+	prss, err := NewPRSS(system.Partynum, system.Order)
+	if err != nil {
+		return err
+	}
+	system.PRSS = prss
+	return nil
+}
+
+// ReservePRSS is PRSS.Reserve. It panics with ErrNoPRSS before SetupPRSS.
+func (system *ShareSystem) ReservePRSS(n int) uint64 {
+	if system.PRSS == nil {
+		panic(ErrNoPRSS)
+	}
+	return system.PRSS.Reserve(n)
+}
+
+func (system *ECCShareSystem) ReservePRSS(n int) uint64 {
+	if system.PRSS == nil {
+		panic(ErrNoPRSS)
+	}
+	return system.PRSS.Reserve(n)
+}
+
+// maskPRSS MACs PRSS shares with the help of a MACed random mask m from the
+// dealer. The parties send each other their shares of d = v - m and add d to
+// the mask locally: party 0 adds it to its share and every party subtracts
+// it from Delta, which keeps Gama = alpha*(x+Delta). d hides v from the
+// parties, who do not know m, but not from the dealer, who does: v stays
+// secret from the dealer only as long as no party tells it d. It also
+// returns d, by which the tag of party 0 has to be shifted.
+func maskPRSS(values []*big.Int, mshares []Share_Fp, order *big.Int, send func(from, to int, msg []byte)) ([]Share_Fp, *big.Int) {
+	n := len(values)
+	d := big.NewInt(0)
+	for i := 0; i < n; i++ {
+		di := new(big.Int).Sub(values[i], mshares[i].Share)
+		di = di.Mod(di, order)
+		for j := 0; j < n; j++ {
+			if j != i {
+				send(i, j, di.Bytes())
+			}
+		}
+		d = d.Add(d, di)
+	}
+	d = d.Mod(d, order)
+	Delta := new(big.Int).Sub(mshares[0].Delta, d)
+	Delta = Delta.Mod(Delta, order)
+	shares := make([]Share_Fp, n)
+	for i := 0; i < n; i++ {
+		shares[i].Index = i
+		shares[i].Share = mshares[i].Share
+		if i == 0 {
+			shares[i].Share = new(big.Int).Add(shares[i].Share, d)
+			shares[i].Share = shares[i].Share.Mod(shares[i].Share, order)
+		}
+		shares[i].Gama = mshares[i].Gama
+		shares[i].Delta = Delta
+		shares[i].Tag = mshares[i].Tag
+	}
+	return shares, d
+}
+
+// RandomShareFp_PRSS returns a MACed sharing of the PRSS value with index k,
+// which the parties take from ReservePRSS. The value shares cost no
+// communication, but the MACs need one exchange per value. Once
+// SetupOfflinePhase has been called they come from its pairwise products and
+// the dealer takes no part, so no entity short of all parties knows the
+// value. Otherwise the value is published under a mask from the dealer, as
+// maskPRSS does; the dealer still learns nothing from the messages it sees,
+// but it learns the value if it colludes with any party. It panics with
+// ErrNoPRSS before SetupPRSS.
+func (system *ShareSystem) RandomShareFp_PRSS(k uint64) *[]Share_Fp {
+	if system.PRSS == nil {
+		panic(ErrNoPRSS)
+	}
+	values := system.PRSS.Shares(k)
+	if system.Offline != nil {
+		shares, err := system.Offline.authenticate(values)
+		if err != nil {
+			panic(err)
+		}
+		return &shares
+	}
+	rshares, err := system.freshRandomShareFp()
+	if err != nil {
+		panic(err)
+	}
+	shares, d := maskPRSS(values, *rshares, system.Order, system.offlineTransfer)
+	shares[0].Tag = system.tagShift(shares[0].Tag, d)
+	return &shares
+}
+
+// RandomSharesFp_PRSS returns MACed sharings of the PRSS values with indices
+// k to k+n-1. With the offline phase their MACs are computed in batches,
+// which pack several of them into each Paillier ciphertext; otherwise every
+// value is masked as in RandomShareFp_PRSS. It panics with ErrNoPRSS before
+// SetupPRSS.
+func (system *ShareSystem) RandomSharesFp_PRSS(k uint64, n int) ([]*[]Share_Fp, error) {
+	if system.PRSS == nil {
+		panic(ErrNoPRSS)
+	}
+	if system.Offline != nil {
+		return authenticatePRSS(system.PRSS, system.Offline, k, n)
+	}
+	return maskPRSSBatch(system.Context(), k, n, system.RandomShareFp_PRSS)
+}
+
+// RandomShareG1_PRSS returns a sharing of r*Gen1 for the PRSS value r with
+// index k; the shares follow from those of r without communication.
+func (system *ShareSystem) RandomShareG1_PRSS(k uint64) *[]Share_G1 {
+	return system.EXP_P_G1_1(curve.Gen1, system.RandomShareFp_PRSS(k))
+}
+
+func (system *ECCShareSystem) RandomShareFp_PRSS(k uint64) *[]Share_Fp {
+	if system.PRSS == nil {
+		panic(ErrNoPRSS)
+	}
+	values := system.PRSS.Shares(k)
+	if system.Offline != nil {
+		shares, err := system.Offline.authenticate(values)
+		if err != nil {
+			panic(err)
+		}
+		return &shares
+	}
+	shares, _ := maskPRSS(values, *system.randomShareFp(), system.Order, system.offlineTransfer)
+	return &shares
+}
+
+func (system *ECCShareSystem) RandomSharesFp_PRSS(k uint64, n int) ([]*[]Share_Fp, error) {
+	if system.PRSS == nil {
+		panic(ErrNoPRSS)
+	}
+	if system.Offline != nil {
+		return authenticatePRSS(system.PRSS, system.Offline, k, n)
+	}
+	return maskPRSSBatch(system.Context(), k, n, system.RandomShareFp_PRSS)
+}
+
+// prssBatch is the number of PRSS values authenticatePRSS MACs in one batch.
+const prssBatch = 256
+
+// authenticatePRSS MACs the PRSS values with indices k to k+n-1 with the
+// offline phase, in concurrent batches of prssBatch values.
+func authenticatePRSS(prss *PRSS, phase *OfflinePhase, k uint64, n int) ([]*[]Share_Fp, error) {
+	var wg sync.WaitGroup
+	result := make([]*[]Share_Fp, n)
+	errs := make([]error, (n+prssBatch-1)/prssBatch)
+	for start := 0; start < n; start += prssBatch {
+		wg.Add(1)
+		go func(start int) {
+			defer wg.Done()
+			values := make([][]*big.Int, min(prssBatch, n-start))
+			for i := range values {
+				values[i] = prss.Shares(k + uint64(start+i))
+			}
+			shares, err := phase.authenticateBatch(values)
+			if err != nil {
+				errs[start/prssBatch] = err
+				return
+			}
+			for i := range shares {
+				result[start+i] = &shares[i]
+			}
+		}(start)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// maskPRSSBatch calls single, which masks one value with the help of the
+// dealer, for every index concurrently and returns the first error it panics
+// with, or the cause of parent.
+func maskPRSSBatch(parent context.Context, k uint64, n int, single func(uint64) *[]Share_Fp) ([]*[]Share_Fp, error) {
+	var wg sync.WaitGroup
+	ctx, cancel := context.WithCancelCause(parent)
+	defer cancel(nil)
+	result := make([]*[]Share_Fp, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer Recover(cancel)
+			result[i] = single(k + uint64(i))
+		}(i)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+	return result, nil
+}
+
+// RandomShareG_PRSS returns a sharing of r*G for the PRSS value r with index
+// k and the base point G of secp256k1.
+func (system *ECCShareSystem) RandomShareG_PRSS(k uint64) *[]Share_G {
+	return system.EXP_P_G_1(system.Curve.Gx, system.Curve.Gy, system.RandomShareFp_PRSS(k))
+}
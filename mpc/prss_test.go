@@ -0,0 +1,44 @@
+package mpc
+
+import (
+	"math/big"
+	"testing"
+)
+
+// prssValue is the value the PRSS shares with index k add up to.
+func prssValue(system *ShareSystem, k uint64) *big.Int {
+	sum := big.NewInt(0)
+	for _, v := range system.PRSS.Shares(k) {
+		sum.Add(sum, v)
+	}
+	return sum.Mod(sum, system.Order)
+}
+
+func TestRandomSharesFp_PRSS(t *testing.T) {
+	for _, n := range []int{1, 3, 8} {
+		system := SystemInit(3)
+		if err := system.SetupPRSS(); err != nil {
+			t.Fatal(err)
+		}
+		k := system.ReservePRSS(n)
+		sharings, err := system.RandomSharesFp_PRSS(k, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sharings) != n {
+			t.Fatalf("got %d sharings, want %d", len(sharings), n)
+		}
+		for j, shares := range sharings {
+			got, chk := system.OpenFp(*shares)
+			if !chk {
+				t.Fatalf("MAC check failed for index %d", k+uint64(j))
+			}
+			if want := prssValue(system, k+uint64(j)); got.Cmp(want) != 0 {
+				t.Fatalf("got %v for index %d, want %v", got, k+uint64(j), want)
+			}
+		}
+		if next := system.ReservePRSS(1); next < k+uint64(n) {
+			t.Fatalf("got index %d after reserving %d from %d", next, n, k)
+		}
+	}
+}
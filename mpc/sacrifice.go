@@ -0,0 +1,261 @@
+package mpc
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+type TripletShares struct {
+	A *[]Share_Fp
+	B *[]Share_Fp
+	C *[]Share_Fp
+}
+
+type SquarePairShares struct {
+	A *[]Share_Fp
+	B *[]Share_Fp
+}
+
+// SacrificeError reports the batch, and the position inside it, of a triple
+// or square pair that failed the sacrifice check.
+type SacrificeError struct {
+	Kind  string
+	Batch int
+	Index int
+}
+
+func (e *SacrificeError) Error() string {
+	return fmt.Sprintf("mpc: sacrifice check failed for %s %d of batch %d", e.Kind, e.Index, e.Batch)
+}
+
+// sacrificePool holds checked material. It is shared by copies of a system.
+// err is the *SacrificeError of the first batch that failed; once it is set
+// the pool hands out nothing more.
+type sacrificePool struct {
+	mu          sync.Mutex
+	size        int
+	batch       int
+	squarebatch int
+	triples     []TripletShares
+	squares     []SquarePairShares
+	err         error
+}
+
+func newSacrificePool(size int) *sacrificePool {
+	if size < 1 {
+		size = 1
+	}
+	pool := new(sacrificePool)
+	pool.size = size
+	return pool
+}
+
+// EnableSacrifice makes GenTriplets and GenSquarePair hand out only checked
+// material. Triples and square pairs are generated in batches of size; every
+// one of them is checked against a second one that is sacrificed. After a
+// batch failed, GenTriplets and GenSquarePair return its *SacrificeError.
+func (system *ShareSystem) EnableSacrifice(size int) {
+	system.sacrifice = newSacrificePool(size)
+}
+
+// fpSacrificer is the Fp arithmetic of ShareSystem and ECCShareSystem that
+// the sacrifice check runs on.
+type fpSacrificer interface {
+	SecSub(shares1, shares2 []Share_Fp) *[]Share_Fp
+	SecMulPlaintext(shares1 []Share_Fp, scalar *big.Int) *[]Share_Fp
+	HalfOpenFp(shares []Share_Fp) *big.Int
+	MacCheckFp(shares []Share_Fp, res_value *big.Int) bool
+	coin() *big.Int
+}
+
+// SacrificeTriplet checks that triple is a valid multiplication triple by
+// consuming sacrificed. With a public t from the commit-and-open coin the
+// parties open rho = t*a - f and sigma = b - g and check that
+// t*c - h - sigma*f - rho*g opens to sigma*rho. All openings are MAC checked.
+// t must not be known before the triples are fixed, or a cheating dealer
+// could prepare a bad pair that passes.
+func (system *ShareSystem) SacrificeTriplet(triple, sacrificed TripletShares) bool {
+	return sacrificeTriplet(system, system.Order, triple, sacrificed)
+}
+
+// SacrificeSquarePair checks that pair is a valid square pair by consuming
+// sacrificed: with rho = t*a - f opened, t^2*b - h - rho*(t*a + f) must open
+// to zero.
+func (system *ShareSystem) SacrificeSquarePair(pair, sacrificed SquarePairShares) bool {
+	return sacrificeSquarePair(system, system.Order, pair, sacrificed)
+}
+
+// CheckTriplets sacrifices sacrificed[k] to check triples[k] and returns a
+// *SacrificeError naming batch and k for the first triple that fails.
+func (system *ShareSystem) CheckTriplets(batch int, triples, sacrificed []TripletShares) error {
+	return checkTriplets(system, system.Order, batch, triples, sacrificed)
+}
+
+func (system *ShareSystem) CheckSquarePairs(batch int, pairs, sacrificed []SquarePairShares) error {
+	return checkSquarePairs(system, system.Order, batch, pairs, sacrificed)
+}
+
+func (system *ShareSystem) checkedTriplets() (*[]Share_Fp, *[]Share_Fp, *[]Share_Fp, error) {
+	return system.sacrifice.nextTriplet(system.CheckTriplets, system.genTriplets)
+}
+
+func (system *ShareSystem) checkedSquarePair() (*[]Share_Fp, *[]Share_Fp, error) {
+	return system.sacrifice.nextSquarePair(system.CheckSquarePairs, system.genSquarePair)
+}
+
+func (system *ECCShareSystem) EnableSacrifice(size int) {
+	system.sacrifice = newSacrificePool(size)
+}
+
+func (system *ECCShareSystem) SacrificeTriplet(triple, sacrificed TripletShares) bool {
+	return sacrificeTriplet(system, system.Order, triple, sacrificed)
+}
+
+func (system *ECCShareSystem) SacrificeSquarePair(pair, sacrificed SquarePairShares) bool {
+	return sacrificeSquarePair(system, system.Order, pair, sacrificed)
+}
+
+func (system *ECCShareSystem) CheckTriplets(batch int, triples, sacrificed []TripletShares) error {
+	return checkTriplets(system, system.Order, batch, triples, sacrificed)
+}
+
+func (system *ECCShareSystem) CheckSquarePairs(batch int, pairs, sacrificed []SquarePairShares) error {
+	return checkSquarePairs(system, system.Order, batch, pairs, sacrificed)
+}
+
+func (system *ECCShareSystem) checkedTriplets() (*[]Share_Fp, *[]Share_Fp, *[]Share_Fp, error) {
+	return system.sacrifice.nextTriplet(system.CheckTriplets, system.genTriplets)
+}
+
+func (system *ECCShareSystem) checkedSquarePair() (*[]Share_Fp, *[]Share_Fp, error) {
+	return system.sacrifice.nextSquarePair(system.CheckSquarePairs, system.genSquarePair)
+}
+
+func sacrificeTriplet(system fpSacrificer, order *big.Int, triple, sacrificed TripletShares) bool {
+	t := system.coin()
+	rhoshares := system.SecSub(*system.SecMulPlaintext(*triple.A, t), *sacrificed.A)
+	rho := system.HalfOpenFp(*rhoshares)
+	if !system.MacCheckFp(*rhoshares, rho) {
+		return false
+	}
+	sigmashares := system.SecSub(*triple.B, *sacrificed.B)
+	sigma := system.HalfOpenFp(*sigmashares)
+	if !system.MacCheckFp(*sigmashares, sigma) {
+		return false
+	}
+	zshares := system.SecSub(*system.SecMulPlaintext(*triple.C, t), *sacrificed.C)
+	zshares = system.SecSub(*zshares, *system.SecMulPlaintext(*sacrificed.A, sigma))
+	zshares = system.SecSub(*zshares, *system.SecMulPlaintext(*sacrificed.B, rho))
+	z := system.HalfOpenFp(*zshares)
+	if !system.MacCheckFp(*zshares, z) {
+		return false
+	}
+	want := new(big.Int).Mul(sigma, rho)
+	want = want.Mod(want, order)
+	return z.Cmp(want) == 0
+}
+
+func sacrificeSquarePair(system fpSacrificer, order *big.Int, pair, sacrificed SquarePairShares) bool {
+	t := system.coin()
+	rhoshares := system.SecSub(*system.SecMulPlaintext(*pair.A, t), *sacrificed.A)
+	rho := system.HalfOpenFp(*rhoshares)
+	if !system.MacCheckFp(*rhoshares, rho) {
+		return false
+	}
+	t2 := new(big.Int).Mul(t, t)
+	rhot := new(big.Int).Mul(rho, t)
+	zshares := system.SecSub(*system.SecMulPlaintext(*pair.B, t2.Mod(t2, order)), *sacrificed.B)
+	zshares = system.SecSub(*zshares, *system.SecMulPlaintext(*pair.A, rhot.Mod(rhot, order)))
+	zshares = system.SecSub(*zshares, *system.SecMulPlaintext(*sacrificed.A, rho))
+	z := system.HalfOpenFp(*zshares)
+	if !system.MacCheckFp(*zshares, z) {
+		return false
+	}
+	return z.Sign() == 0
+}
+
+func checkTriplets(system fpSacrificer, order *big.Int, batch int, triples, sacrificed []TripletShares) error {
+	for k := range triples {
+		if !sacrificeTriplet(system, order, triples[k], sacrificed[k]) {
+			return &SacrificeError{Kind: "triple", Batch: batch, Index: k}
+		}
+	}
+	return nil
+}
+
+func checkSquarePairs(system fpSacrificer, order *big.Int, batch int, pairs, sacrificed []SquarePairShares) error {
+	for k := range pairs {
+		if !sacrificeSquarePair(system, order, pairs[k], sacrificed[k]) {
+			return &SacrificeError{Kind: "square pair", Batch: batch, Index: k}
+		}
+	}
+	return nil
+}
+
+// nextTriplet hands out the next checked triple. When the pool is empty it
+// generates a batch and a batch to sacrifice with gen and checks them with
+// check. An error of gen is returned as it is.
+func (pool *sacrificePool) nextTriplet(check func(int, []TripletShares, []TripletShares) error,
+	gen func() (*[]Share_Fp, *[]Share_Fp, *[]Share_Fp, error)) (*[]Share_Fp, *[]Share_Fp, *[]Share_Fp, error) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	if pool.err != nil {
+		return nil, nil, nil, pool.err
+	}
+	if len(pool.triples) == 0 {
+		triples := make([]TripletShares, pool.size)
+		sacrificed := make([]TripletShares, pool.size)
+		for k := 0; k < pool.size; k++ {
+			var err error
+			if triples[k].A, triples[k].B, triples[k].C, err = gen(); err != nil {
+				return nil, nil, nil, err
+			}
+			if sacrificed[k].A, sacrificed[k].B, sacrificed[k].C, err = gen(); err != nil {
+				return nil, nil, nil, err
+			}
+		}
+		err := check(pool.batch, triples, sacrificed)
+		pool.batch++
+		if err != nil {
+			pool.err = err
+			return nil, nil, nil, err
+		}
+		pool.triples = triples
+	}
+	triple := pool.triples[0]
+	pool.triples = pool.triples[1:]
+	return triple.A, triple.B, triple.C, nil
+}
+
+func (pool *sacrificePool) nextSquarePair(check func(int, []SquarePairShares, []SquarePairShares) error,
+	gen func() (*[]Share_Fp, *[]Share_Fp, error)) (*[]Share_Fp, *[]Share_Fp, error) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	if pool.err != nil {
+		return nil, nil, pool.err
+	}
+	if len(pool.squares) == 0 {
+		pairs := make([]SquarePairShares, pool.size)
+		sacrificed := make([]SquarePairShares, pool.size)
+		for k := 0; k < pool.size; k++ {
+			var err error
+			if pairs[k].A, pairs[k].B, err = gen(); err != nil {
+				return nil, nil, err
+			}
+			if sacrificed[k].A, sacrificed[k].B, err = gen(); err != nil {
+				return nil, nil, err
+			}
+		}
+		err := check(pool.squarebatch, pairs, sacrificed)
+		pool.squarebatch++
+		if err != nil {
+			pool.err = err
+			return nil, nil, err
+		}
+		pool.squares = pairs
+	}
+	pair := pool.squares[0]
+	pool.squares = pool.squares[1:]
+	return pair.A, pair.B, nil
+}
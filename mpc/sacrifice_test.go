@@ -0,0 +1,87 @@
+package mpc
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func genTriplet(t *testing.T, system *ShareSystem) TripletShares {
+	a, b, c, err := system.GenTriplets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return TripletShares{A: a, B: b, C: c}
+}
+
+// corrupt adds one to the share of party 0 of c, which leaves its MAC as it
+// was.
+func corrupt(c *[]Share_Fp) *[]Share_Fp {
+	shares := make([]Share_Fp, len(*c))
+	copy(shares, *c)
+	shares[0].Share = new(big.Int).Add(shares[0].Share, one)
+	return &shares
+}
+
+func TestSacrificeTriplet(t *testing.T) {
+	system := SystemInit(3)
+	for _, tc := range []struct {
+		name               string
+		triple, sacrificed bool
+		want               bool
+	}{
+		{"valid", false, false, true},
+		{"corrupted triple", true, false, false},
+		{"corrupted sacrificed triple", false, true, false},
+	} {
+		triple, sacrificed := genTriplet(t, system), genTriplet(t, system)
+		if tc.triple {
+			triple.C = corrupt(triple.C)
+		}
+		if tc.sacrificed {
+			sacrificed.C = corrupt(sacrificed.C)
+		}
+		if got := system.SacrificeTriplet(triple, sacrificed); got != tc.want {
+			t.Fatalf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestCheckTriplets(t *testing.T) {
+	system := SystemInit(3)
+	triples := make([]TripletShares, 4)
+	sacrificed := make([]TripletShares, 4)
+	for k := range triples {
+		triples[k], sacrificed[k] = genTriplet(t, system), genTriplet(t, system)
+	}
+	if err := system.CheckTriplets(0, triples, sacrificed); err != nil {
+		t.Fatal(err)
+	}
+	triples[2].C = corrupt(triples[2].C)
+	err := system.CheckTriplets(5, triples, sacrificed)
+	var serr *SacrificeError
+	if !errors.As(err, &serr) {
+		t.Fatalf("got %v, want a *SacrificeError", err)
+	}
+	if serr.Batch != 5 || serr.Index != 2 {
+		t.Fatalf("got batch %d index %d, want batch 5 index 2", serr.Batch, serr.Index)
+	}
+}
+
+func TestEnableSacrifice(t *testing.T) {
+	system := SystemInit(3)
+	system.EnableSacrifice(4)
+	for _, tc := range [][2]int64{{0, 5}, {3, 7}, {-1, 2}} {
+		x := new(big.Int).Mod(big.NewInt(tc[0]), system.Order)
+		y := new(big.Int).Mod(big.NewInt(tc[1]), system.Order)
+		want := new(big.Int).Mul(x, y)
+		want.Mod(want, system.Order)
+		got, chk := system.OpenFp(*system.SecMul(*system.Share_An_Fp(x), *system.Share_An_Fp(y)))
+		if !chk {
+			t.Fatal("MAC check failed")
+		}
+		if got.Cmp(want) != 0 {
+			t.Fatalf("got %v for %v * %v, want %v", got, x, y, want)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package mpc
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/Oryx/curve"
+	"github.com/Oryx/network"
+)
+
+// Share is a MACed additive share of a value in a curve.Group, with
+// Gama = alpha*(x+Delta) summed over the parties. Share_G1, Share_G2 and
+// Share_GT are its instances. The functions below implement sharing, the
+// linear operations, EXP_S, the opening with MAC check and the tags of the
+// identifiable-abort mode once for every group; the methods of ShareSystem on
+// the instances wrap them.
+type Share[E any] struct {
+	Share *E
+	Gama  *E
+	Delta *E
+	Index int
+	Tag   *Tag[E]
+}
+
+type Share_G1 = Share[curve.G1]
+type Share_G2 = Share[curve.G2]
+type Share_GT = Share[curve.GT]
+
+// groupShare_A is the dealer's sharing of element. The offline variant is
+// counted as preprocessing and does not send element to the dealer.
+func groupShare_A[E any, P curve.Group[E]](system *ShareSystem, element *E, offline bool) *[]Share[E] {
+	var wg sync.WaitGroup
+	send, broadcastN := system.Send, system.BroadcastN
+	if offline {
+		send, broadcastN = system.OfflineSend, system.OfflineBroadcastN
+	}
+	ori_value := P(new(E)).Set(element)
+	Delta := curve.RandomElement[E, P]()
+	if !offline {
+		wg.Add(1)
+		go system.Send(&wg, system.Partynum, P(ori_value).Marshal())
+	}
+	wg.Add(1)
+	go broadcastN(&wg, P(Delta).Marshal())
+	Gama := P(new(E)).Add(ori_value, Delta)
+	Gama = P(Gama).ScalarMult(Gama, system.dealerKey())
+	shares := make([]Share[E], system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i].Index = i
+		shares[i].Delta = Delta
+		if i < system.Partynum-1 {
+			shares[i].Share = curve.RandomElement[E, P]()
+			shares[i].Gama = curve.RandomElement[E, P]()
+			ori_value = P(ori_value).Add(ori_value, P(new(E)).Neg(shares[i].Share))
+			Gama = P(Gama).Add(Gama, P(new(E)).Neg(shares[i].Gama))
+		} else {
+			shares[i].Share = ori_value
+			shares[i].Gama = Gama
+		}
+		wg.Add(2)
+		go send(&wg, i, P(shares[i].Share).Marshal())
+		go send(&wg, i, P(shares[i].Gama).Marshal())
+	}
+	wg.Wait()
+	groupTag[E, P](system, shares, send)
+	return &shares
+}
+
+func groupRandomShare[E any, P curve.Group[E]](system *ShareSystem) *[]Share[E] {
+	if system.alpha == nil {
+		return groupEXP_P_1[E, P](system, P(new(E)).Generator(), system.randomShareFp())
+	}
+	return groupShare_A[E, P](system, curve.RandomElement[E, P](), true)
+}
+
+// groupConst shares a public constant as constFp does.
+func groupConst[E any, P curve.Group[E]](system *ShareSystem, scalar *E) *[]Share[E] {
+	if system.alpha != nil {
+		return groupShare_A[E, P](system, scalar, false)
+	}
+	shares := make([]Share[E], system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i].Index = i
+		shares[i].Share = P(new(E)).Identity()
+		if i == 0 {
+			shares[i].Share = P(new(E)).Set(scalar)
+		}
+		shares[i].Gama = P(new(E)).ScalarMult(scalar, system.Alphas[i])
+		shares[i].Delta = P(new(E)).Identity()
+	}
+	return &shares
+}
+
+// groupShare_A_Owner shares an input of party owner without the dealer; see
+// Share_A_G1_Owner.
+func groupShare_A_Owner[E any, P curve.Group[E]](system *ShareSystem, owner int, element *E) *[]Share[E] {
+	if system.Offline == nil {
+		panic(ErrNoOfflinePhase)
+	}
+	rshares := groupEXP_P_1[E, P](system, P(new(E)).Generator(), system.randomShareFp())
+	R := P(new(E)).Set((*rshares)[owner].Share)
+	for i := 0; i < system.Partynum; i++ {
+		if i != owner {
+			R = P(R).Add(R, (*rshares)[i].Share)
+			system.transfer(i, owner, P((*rshares)[i].Share).Marshal())
+		}
+	}
+	c := P(new(E)).Add(element, P(new(E)).Neg(R))
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go system.Broadcast(&wg, owner, P(c).Marshal())
+	wg.Wait()
+	return groupSecAdd[E, P](system, *rshares, *groupConst[E, P](system, c))
+}
+
+func groupShare_EXP_P_1[E any, P curve.Group[E]](element *E, xshare Share_Fp) Share[E] {
+	return Share[E]{
+		Share: P(new(E)).ScalarMult(element, xshare.Share),
+		Gama:  P(new(E)).ScalarMult(element, xshare.Gama),
+		Delta: P(new(E)).ScalarMult(element, xshare.Delta),
+		Index: xshare.Index,
+		Tag:   groupTag_EXP_P_1[E, P](element, xshare.Tag),
+	}
+}
+
+func groupEXP_P_1[E any, P curve.Group[E]](system *ShareSystem, element *E, xshares *[]Share_Fp) *[]Share[E] {
+	shares := make([]Share[E], system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = groupShare_EXP_P_1[E, P](element, (*xshares)[i])
+	}
+	return &shares
+}
+
+func groupShare_EXP_P_2[E any, P curve.Group[E]](eshare Share[E], x *big.Int) Share[E] {
+	return Share[E]{
+		Share: P(new(E)).ScalarMult(eshare.Share, x),
+		Gama:  P(new(E)).ScalarMult(eshare.Gama, x),
+		Delta: P(new(E)).ScalarMult(eshare.Delta, x),
+		Index: eshare.Index,
+		Tag:   groupTag_EXP_P_2[E, P](eshare.Tag, x),
+	}
+}
+
+func groupEXP_P_2[E any, P curve.Group[E]](system *ShareSystem, eshares *[]Share[E], x *big.Int) *[]Share[E] {
+	shares := make([]Share[E], system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = groupShare_EXP_P_2[E, P]((*eshares)[i], x)
+	}
+	return &shares
+}
+
+func groupAdd[E any, P curve.Group[E]](shares1, shares2 Share[E]) Share[E] {
+	return Share[E]{
+		Share: P(new(E)).Add(shares1.Share, shares2.Share),
+		Gama:  P(new(E)).Add(shares1.Gama, shares2.Gama),
+		Delta: P(new(E)).Add(shares1.Delta, shares2.Delta),
+		Index: shares1.Index,
+		Tag:   groupTagAdd[E, P](shares1.Tag, shares2.Tag),
+	}
+}
+
+func groupSub[E any, P curve.Group[E]](shares1, shares2 Share[E]) Share[E] {
+	return Share[E]{
+		Share: P(new(E)).Add(shares1.Share, P(new(E)).Neg(shares2.Share)),
+		Gama:  P(new(E)).Add(shares1.Gama, P(new(E)).Neg(shares2.Gama)),
+		Delta: P(new(E)).Add(shares1.Delta, P(new(E)).Neg(shares2.Delta)),
+		Index: shares1.Index,
+		Tag:   groupTagSub[E, P](shares1.Tag, shares2.Tag),
+	}
+}
+
+func groupSecAdd[E any, P curve.Group[E]](system *ShareSystem, shares1, shares2 []Share[E]) *[]Share[E] {
+	shares := make([]Share[E], system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = groupAdd[E, P](shares1[i], shares2[i])
+	}
+	return &shares
+}
+
+func groupSecSub[E any, P curve.Group[E]](system *ShareSystem, shares1, shares2 []Share[E]) *[]Share[E] {
+	shares := make([]Share[E], system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = groupSub[E, P](shares1[i], shares2[i])
+	}
+	return &shares
+}
+
+func groupSecAddPlaintext[E any, P curve.Group[E]](system *ShareSystem, shares1 []Share[E], scalar *E) *[]Share[E] {
+	return groupSecAdd[E, P](system, shares1, *groupConst[E, P](system, scalar))
+}
+
+func groupSecSubPlaintext[E any, P curve.Group[E]](system *ShareSystem, shares1 []Share[E], scalar *E) *[]Share[E] {
+	return groupSecSub[E, P](system, shares1, *groupConst[E, P](system, scalar))
+}
+
+// groupEXP_S raises a shared element h to a shared exponent x with a triple
+// (a, b, c): h^x = g^c * (g^b)^(x-a) * t^a * t^(x-a) for t = h - g^b.
+func groupEXP_S[E any, P curve.Group[E]](system *ShareSystem, hshares []Share[E], xshares []Share_Fp) *[]Share[E] {
+	gen := P(new(E)).Generator()
+	sharesA, sharesB, sharesC, err := system.GenTriplets()
+	if err != nil {
+		panic(err)
+	}
+	sharesgB := groupEXP_P_1[E, P](system, gen, sharesB)
+	sharesgC := groupEXP_P_1[E, P](system, gen, sharesC)
+	XsubAshares := system.SecSub(xshares, *sharesA)
+	xsuba := system.HalfOpenFp(*XsubAshares)
+	tshares := groupSecSub[E, P](system, hshares, *sharesgB)
+	t := groupHalfOpen[E, P](system, *tshares)
+	t_exp_xsuba_shares := groupEXP_P_1[E, P](system, t, XsubAshares)
+	t_exp_a_shares := groupEXP_P_1[E, P](system, t, sharesA)
+	gb_exp_xsuba_shares := groupEXP_P_2[E, P](system, sharesgB, xsuba)
+	shares := groupSecAdd[E, P](system, *sharesgC, *gb_exp_xsuba_shares)
+	shares = groupSecAdd[E, P](system, *shares, *t_exp_a_shares)
+	shares = groupSecAdd[E, P](system, *shares, *t_exp_xsuba_shares)
+	return shares
+}
+
+func groupHalfOpen[E any, P curve.Group[E]](system *ShareSystem, shares []Share[E]) *E {
+	var wg sync.WaitGroup
+	ori_value := P(new(E)).Identity()
+	for i := 0; i < system.Partynum; i++ {
+		ori_value = P(ori_value).Add(ori_value, shares[i].Share)
+		wg.Add(1)
+		go system.Broadcast(&wg, i, P(shares[i].Share).Marshal())
+	}
+	wg.Wait()
+	return ori_value
+}
+
+// groupMacDiff is what the party holding share and the MAC-key share alpha
+// contributes to the MAC check of an opened value: Gama_i - alpha*(v + Delta).
+// The contributions of all parties add up to the identity.
+func groupMacDiff[E any, P curve.Group[E]](alpha *big.Int, share Share[E], value *E) *E {
+	t := P(new(E)).Add(value, share.Delta)
+	d := P(t).ScalarMult(t, alpha)
+	return P(d).Add(share.Gama, P(new(E)).Neg(d))
+}
+
+func groupDiffs[E any, P curve.Group[E]](system *ShareSystem, shares []Share[E], value *E) []*E {
+	diffs := make([]*E, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		diffs[i] = groupMacDiff[E, P](system.Alphas[i], shares[i], value)
+	}
+	return diffs
+}
+
+// groupMacCheck checks an opened value against the MACs. Every party commits
+// to its difference before opening it, and the openings must add up to the
+// identity.
+func groupMacCheck[E any, P curve.Group[E]](system *ShareSystem, shares []Share[E], res_value *E) bool {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	chk := P(new(E)).Identity()
+	for i, delta := range groupDiffs[E, P](system, shares, res_value) {
+		msg := P(delta).Marshal()
+		commit, r := Com(msg)
+		wg.Add(3)
+		go system.Broadcast(&wg, i, msg)
+		go system.Broadcast(&wg, i, r.Bytes())
+		go system.Broadcast(&wg, i, commit)
+		if !OpenComit(msg, commit, r) {
+			return false
+		}
+		chk = P(chk).Add(chk, delta)
+	}
+	return curve.Equal[E, P](chk, P(new(E)).Identity())
+}
+
+func groupOpen[E any, P curve.Group[E]](system *ShareSystem, shares []Share[E]) (*E, bool) {
+	ori_value := groupHalfOpen[E, P](system, shares)
+	return ori_value, groupMacCheck[E, P](system, shares, ori_value)
+}
+
+// groupOpen_IA opens shares like groupOpen but returns a *MacCheckError
+// naming the party found by groupIdentify when the MAC check fails.
+func groupOpen_IA[E any, P curve.Group[E]](system *ShareSystem, op string, shares []Share[E]) (*E, error) {
+	value, chk := groupOpen[E, P](system, shares)
+	if !chk {
+		return nil, &MacCheckError{Op: op, Party: -1, Element: -1, Cheater: groupIdentify[E, P](system, shares)}
+	}
+	return value, nil
+}
+
+// marshalShare encodes one party's share for sending it to another process.
+func marshalShare[E any, P curve.Group[E]](share Share[E]) []byte {
+	return network.Pack(P(share.Share).Marshal(), P(share.Gama).Marshal(), P(share.Delta).Marshal())
+}
+
+func unmarshalShare[E any, P curve.Group[E]](msg []byte, index int) (Share[E], error) {
+	var share Share[E]
+	parts, err := network.Unpack(msg)
+	if err != nil {
+		return share, err
+	}
+	if len(parts) != 3 {
+		return share, ErrBadShare
+	}
+	share.Share, share.Gama, share.Delta = new(E), new(E), new(E)
+	for k, e := range []*E{share.Share, share.Gama, share.Delta} {
+		if _, err := P(e).Unmarshal(parts[k]); err != nil {
+			return share, err
+		}
+	}
+	share.Index = index
+	return share, nil
+}
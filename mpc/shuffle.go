@@ -0,0 +1,230 @@
+package mpc
+
+import (
+	"crypto/rand"
+	"math/big"
+	"sync"
+	"sync/atomic"
+
+	curve "github.com/Oryx/curve"
+	"github.com/Oryx/network"
+)
+
+// transfer carries one online message between two parties and accounts it
+// in Com.
+func (system *ShareSystem) transfer(from, to int, msg []byte) {
+	atomic.AddInt64(&system.Com, int64(len(msg)))
+	if system.isWAN && system.BandwidthCtrl != nil {
+		system.BandwidthCtrl.SimulateSend(msg)
+	}
+	system.failure.Record(network.Deliver(system.Net, from, to, msg))
+}
+
+func (system *ECCShareSystem) transfer(from, to int, msg []byte) {
+	atomic.AddInt64(&system.Com, int64(len(msg)))
+	if system.isWAN && system.BandwidthCtrl != nil {
+		system.BandwidthCtrl.SimulateSend(msg)
+	}
+	system.failure.Record(network.Deliver(system.Net, from, to, msg))
+}
+
+// randPerm returns a uniformly random permutation of 0..n-1.
+func randPerm(n int) []int {
+	perm := make([]int, n)
+	for k := range perm {
+		perm[k] = k
+	}
+	for k := n - 1; k > 0; k-- {
+		r, _ := rand.Int(rand.Reader, big.NewInt(int64(k+1)))
+		t := int(r.Int64())
+		perm[k], perm[t] = perm[t], perm[k]
+	}
+	return perm
+}
+
+// SecShuffle_GT returns the shared elements in a permuted order. Each element
+// is first brought to a zero Delta, since the public Deltas would link the
+// positions: party i turns Gama_i into Gama_i - Alphas[i]*Delta. Then every
+// party in turn permutes the vector with a permutation correlation from the
+// dealer: the others hold random masks a_j and b_j, the permuting party holds
+// a permutation pi and pi(sum a_j) - sum b_j. The others send their shares
+// minus a_j to it, it keeps pi of the sum plus its correlation as its new
+// share, and the others keep b_j. Shuffled shares carry no
+// identifiable-abort tags.
+//
+// The dealer samples every pi and so knows the composed permutation: the
+// positions are hidden from the parties only while the dealer colludes with
+// none of them. The shuffle keeps the MACs valid but does not prove that a
+// party applied its permutation; a party that drops or repeats elements is
+// not detected.
+func (system *ShareSystem) SecShuffle_GT(shares []*[]Share_GT) []*[]Share_GT {
+	n := system.Partynum
+	size := len(shares)
+	cur := make([][]Share_GT, size)
+	for k := 0; k < size; k++ {
+		cur[k] = make([]Share_GT, n)
+		for i := 0; i < n; i++ {
+			d := new(curve.GT).ScalarMult((*shares[k])[i].Delta, system.Alphas[i])
+			cur[k][i].Index = i
+			cur[k][i].Delta = system.IdentityGT
+			cur[k][i].Share = (*shares[k])[i].Share
+			cur[k][i].Gama = new(curve.GT).Add((*shares[k])[i].Gama, new(curve.GT).Neg(d))
+		}
+	}
+	for p := 0; p < n; p++ {
+		cur = system.permute_GT(cur, p)
+	}
+	shuffled := make([]*[]Share_GT, size)
+	for k := range cur {
+		shuffled[k] = &cur[k]
+	}
+	return shuffled
+}
+
+func (system *ShareSystem) permute_GT(cur [][]Share_GT, p int) [][]Share_GT {
+	var wg sync.WaitGroup
+	n := system.Partynum
+	size := len(cur)
+	perm := randPerm(size)
+	next := make([][]Share_GT, size)
+	sumA := make([]Share_GT, size)
+	masked := make([]Share_GT, size)
+	offline := make([][][]byte, n)
+	online := make([][][]byte, n)
+	for k := 0; k < size; k++ {
+		next[k] = make([]Share_GT, n)
+		sumA[k] = Share_GT{Share: new(curve.GT).Set(system.IdentityGT), Gama: new(curve.GT).Set(system.IdentityGT)}
+		masked[k] = Share_GT{Share: new(curve.GT).Set(cur[k][p].Share), Gama: new(curve.GT).Set(cur[k][p].Gama)}
+		for j := 0; j < n; j++ {
+			next[k][j].Index = j
+			next[k][j].Delta = system.IdentityGT
+			if j == p {
+				continue
+			}
+			_, a, _ := curve.RandomGTK(rand.Reader)
+			_, ag, _ := curve.RandomGTK(rand.Reader)
+			_, next[k][j].Share, _ = curve.RandomGTK(rand.Reader)
+			_, next[k][j].Gama, _ = curve.RandomGTK(rand.Reader)
+			sumA[k].Share.Add(sumA[k].Share, a)
+			sumA[k].Gama.Add(sumA[k].Gama, ag)
+			xa := new(curve.GT).Add(cur[k][j].Share, new(curve.GT).Neg(a))
+			ga := new(curve.GT).Add(cur[k][j].Gama, new(curve.GT).Neg(ag))
+			masked[k].Share.Add(masked[k].Share, xa)
+			masked[k].Gama.Add(masked[k].Gama, ga)
+			offline[j] = append(offline[j], a.Marshal(), ag.Marshal(), next[k][j].Share.Marshal(), next[k][j].Gama.Marshal())
+			online[j] = append(online[j], xa.Marshal(), ga.Marshal())
+		}
+	}
+	for k := 0; k < size; k++ {
+		// the correlation of p is pi(sum a) - sum b
+		cs := new(curve.GT).Set(sumA[perm[k]].Share)
+		cg := new(curve.GT).Set(sumA[perm[k]].Gama)
+		for j := 0; j < n; j++ {
+			if j != p {
+				cs.Add(cs, new(curve.GT).Neg(next[k][j].Share))
+				cg.Add(cg, new(curve.GT).Neg(next[k][j].Gama))
+			}
+		}
+		offline[p] = append(offline[p], cs.Marshal(), cg.Marshal())
+		next[k][p].Share = new(curve.GT).Add(masked[perm[k]].Share, cs)
+		next[k][p].Gama = new(curve.GT).Add(masked[perm[k]].Gama, cg)
+	}
+	for j := 0; j < n; j++ {
+		wg.Add(1)
+		go system.OfflineSend(&wg, j, network.Pack(offline[j]...))
+		if j != p {
+			system.transfer(j, p, network.Pack(online[j]...))
+		}
+	}
+	wg.Wait()
+	return next
+}
+
+// SecShuffle_G is SecShuffle_GT for the points of an ECCShareSystem.
+func (system *ECCShareSystem) SecShuffle_G(shares []*[]Share_G) []*[]Share_G {
+	n := system.Partynum
+	size := len(shares)
+	cur := make([][]Share_G, size)
+	for k := 0; k < size; k++ {
+		cur[k] = make([]Share_G, n)
+		for i := 0; i < n; i++ {
+			s := (*shares[k])[i]
+			dX, dY := system.Curve.ScalarMult(s.DeltaX, s.DeltaY, system.Alphas[i].Bytes())
+			cur[k][i].Index = i
+			cur[k][i].DeltaX, cur[k][i].DeltaY = system.IdentityGx, system.IdentityGy
+			cur[k][i].ShareX, cur[k][i].ShareY = s.ShareX, s.ShareY
+			cur[k][i].GamaX, cur[k][i].GamaY = system.Curve.Add(s.GamaX, s.GamaY, dX, system.negY(dY))
+		}
+	}
+	for p := 0; p < n; p++ {
+		cur = system.permute_G(cur, p)
+	}
+	shuffled := make([]*[]Share_G, size)
+	for k := range cur {
+		shuffled[k] = &cur[k]
+	}
+	return shuffled
+}
+
+func (system *ECCShareSystem) negY(y *big.Int) *big.Int {
+	return new(big.Int).Mod(new(big.Int).Neg(y), system.Curve.P)
+}
+
+func (system *ECCShareSystem) permute_G(cur [][]Share_G, p int) [][]Share_G {
+	var wg sync.WaitGroup
+	n := system.Partynum
+	size := len(cur)
+	perm := randPerm(size)
+	next := make([][]Share_G, size)
+	sumA := make([]Share_G, size)
+	masked := make([]Share_G, size)
+	offline := make([][][]byte, n)
+	online := make([][][]byte, n)
+	for k := 0; k < size; k++ {
+		next[k] = make([]Share_G, n)
+		sumA[k] = Share_G{ShareX: system.IdentityGx, ShareY: system.IdentityGy, GamaX: system.IdentityGx, GamaY: system.IdentityGy}
+		masked[k] = Share_G{ShareX: cur[k][p].ShareX, ShareY: cur[k][p].ShareY, GamaX: cur[k][p].GamaX, GamaY: cur[k][p].GamaY}
+		for j := 0; j < n; j++ {
+			next[k][j].Index = j
+			next[k][j].DeltaX, next[k][j].DeltaY = system.IdentityGx, system.IdentityGy
+			if j == p {
+				continue
+			}
+			aX, aY := system.RandomG()
+			agX, agY := system.RandomG()
+			next[k][j].ShareX, next[k][j].ShareY = system.RandomG()
+			next[k][j].GamaX, next[k][j].GamaY = system.RandomG()
+			sumA[k].ShareX, sumA[k].ShareY = system.Curve.Add(sumA[k].ShareX, sumA[k].ShareY, aX, aY)
+			sumA[k].GamaX, sumA[k].GamaY = system.Curve.Add(sumA[k].GamaX, sumA[k].GamaY, agX, agY)
+			xaX, xaY := system.Curve.Add(cur[k][j].ShareX, cur[k][j].ShareY, aX, system.negY(aY))
+			gaX, gaY := system.Curve.Add(cur[k][j].GamaX, cur[k][j].GamaY, agX, system.negY(agY))
+			masked[k].ShareX, masked[k].ShareY = system.Curve.Add(masked[k].ShareX, masked[k].ShareY, xaX, xaY)
+			masked[k].GamaX, masked[k].GamaY = system.Curve.Add(masked[k].GamaX, masked[k].GamaY, gaX, gaY)
+			offline[j] = append(offline[j], aX.Bytes(), aY.Bytes(), agX.Bytes(), agY.Bytes(),
+				next[k][j].ShareX.Bytes(), next[k][j].ShareY.Bytes(), next[k][j].GamaX.Bytes(), next[k][j].GamaY.Bytes())
+			online[j] = append(online[j], xaX.Bytes(), xaY.Bytes(), gaX.Bytes(), gaY.Bytes())
+		}
+	}
+	for k := 0; k < size; k++ {
+		csX, csY := sumA[perm[k]].ShareX, sumA[perm[k]].ShareY
+		cgX, cgY := sumA[perm[k]].GamaX, sumA[perm[k]].GamaY
+		for j := 0; j < n; j++ {
+			if j != p {
+				csX, csY = system.Curve.Add(csX, csY, next[k][j].ShareX, system.negY(next[k][j].ShareY))
+				cgX, cgY = system.Curve.Add(cgX, cgY, next[k][j].GamaX, system.negY(next[k][j].GamaY))
+			}
+		}
+		offline[p] = append(offline[p], csX.Bytes(), csY.Bytes(), cgX.Bytes(), cgY.Bytes())
+		next[k][p].ShareX, next[k][p].ShareY = system.Curve.Add(masked[perm[k]].ShareX, masked[perm[k]].ShareY, csX, csY)
+		next[k][p].GamaX, next[k][p].GamaY = system.Curve.Add(masked[perm[k]].GamaX, masked[perm[k]].GamaY, cgX, cgY)
+	}
+	for j := 0; j < n; j++ {
+		wg.Add(1)
+		go system.OfflineSend(&wg, j, network.Pack(offline[j]...))
+		if j != p {
+			system.transfer(j, p, network.Pack(online[j]...))
+		}
+	}
+	wg.Wait()
+	return next
+}
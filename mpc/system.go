@@ -1,16 +1,16 @@
 package mpc
 
 import (
+	"context"
 	"crypto/rand"
-	"fmt"
 	"math/big"
-	"os"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	curve "github.com/Oryx/curve"
 	"github.com/Oryx/ecc"
+	"github.com/Oryx/network"
 )
 
 var zero = big.NewInt(0)
@@ -98,6 +98,21 @@ type ShareSystem struct {
 	isWAN           bool
 	//bandwidthMbps   float64
 	BandwidthCtrl *BandwidthSimulator
+	Net           []network.Transport
+	// Offline and OfflineMul replace the trusted dealer of GenTriplets and
+	// GenTriplets_for_Exp once SetupOfflinePhase has been called.
+	Offline    *OfflinePhase
+	OfflineMul *OfflinePhase
+	sacrifice  *sacrificePool
+	// Pool, if set, serves GenTriplets, GenSquarePair and RandomShareFp
+	// until it runs out; see Pool.Err.
+	Pool *Pool
+	// PRSS is set by SetupPRSS.
+	PRSS *PRSS
+	ia   *identifier
+	// failure records the first message that could not be delivered; see
+	// Context.
+	failure *network.Failure
 }
 
 type ECCShareSystem struct {
@@ -113,6 +128,11 @@ type ECCShareSystem struct {
 	isWAN      bool
 	//bandwidthMbps float64
 	BandwidthCtrl *BandwidthSimulator
+	Net           []network.Transport
+	Offline       *OfflinePhase
+	PRSS          *PRSS
+	sacrifice     *sacrificePool
+	failure       *network.Failure
 }
 
 type RSAShareSystem struct {
@@ -127,6 +147,8 @@ type RSAShareSystem struct {
 	isWAN      bool
 	//bandwidthMbps float64
 	BandwidthCtrl *BandwidthSimulator
+	Net           []network.Transport
+	failure       *network.Failure
 }
 
 type Triplets struct {
@@ -140,19 +162,33 @@ type SquarePair struct {
 	B *big.Int
 }
 
-func (system *ShareSystem) Send(wg *sync.WaitGroup, msg []byte) {
+// Context is cancelled with the error of the first message that Send,
+// Broadcast or any other transfer of the system fails to deliver. The
+// protocol runs derive their contexts from it, so such a failure stops them
+// and becomes their error. NetErr returns it.
+func (system *ShareSystem) Context() context.Context {
+	return system.failure.Context()
+}
+
+func (system *ShareSystem) NetErr() error {
+	return system.failure.Err()
+}
+
+func (system *ShareSystem) Send(wg *sync.WaitGroup, to int, msg []byte) {
 	atomic.AddInt64(&system.Com, int64(len(msg)))
 	if system.isWAN && system.BandwidthCtrl != nil {
 		system.BandwidthCtrl.SimulateSend(msg)
 	}
+	system.failure.Record(network.Deliver(system.Net, system.Partynum, to, msg))
 	wg.Done()
 }
 
-func (system *ShareSystem) Broadcast(wg *sync.WaitGroup, msg []byte) {
+func (system *ShareSystem) Broadcast(wg *sync.WaitGroup, from int, msg []byte) {
 	atomic.AddInt64(&system.Com, int64((system.Partynum-1)*len(msg)))
 	if system.isWAN && system.BandwidthCtrl != nil {
 		system.BandwidthCtrl.SimulateBroadcast(msg, system.Partynum-1)
 	}
+	system.failure.Record(network.DeliverAll(system.Net, from, msg))
 	wg.Done()
 }
 
@@ -161,22 +197,25 @@ func (system *ShareSystem) BroadcastN(wg *sync.WaitGroup, msg []byte) {
 	if system.isWAN && system.BandwidthCtrl != nil {
 		system.BandwidthCtrl.SimulateBroadcast(msg, system.Partynum)
 	}
+	system.failure.Record(network.DeliverAll(system.Net, system.Partynum, msg))
 	wg.Done()
 }
 
-func (system *ShareSystem) OfflineSend(wg *sync.WaitGroup, msg []byte) {
+func (system *ShareSystem) OfflineSend(wg *sync.WaitGroup, to int, msg []byte) {
 	atomic.AddInt64(&system.OfflineCom, int64(len(msg)))
 	if system.isWAN && system.BandwidthCtrl != nil {
 		system.BandwidthCtrl.SimulateSend(msg)
 	}
+	system.failure.Record(network.Deliver(system.Net, system.Partynum, to, msg))
 	wg.Done()
 }
 
-func (system *ShareSystem) OfflineBroadcast(wg *sync.WaitGroup, msg []byte) {
+func (system *ShareSystem) OfflineBroadcast(wg *sync.WaitGroup, from int, msg []byte) {
 	atomic.AddInt64(&system.OfflineCom, int64((system.Partynum-1)*len(msg)))
 	if system.isWAN && system.BandwidthCtrl != nil {
 		system.BandwidthCtrl.SimulateBroadcast(msg, system.Partynum-1)
 	}
+	system.failure.Record(network.DeliverAll(system.Net, from, msg))
 	wg.Done()
 }
 
@@ -185,22 +224,33 @@ func (system *ShareSystem) OfflineBroadcastN(wg *sync.WaitGroup, msg []byte) {
 	if system.isWAN && system.BandwidthCtrl != nil {
 		system.BandwidthCtrl.SimulateBroadcast(msg, system.Partynum)
 	}
+	system.failure.Record(network.DeliverAll(system.Net, system.Partynum, msg))
 	wg.Done()
 }
 
-func (system *ECCShareSystem) Send(wg *sync.WaitGroup, msg []byte) {
+func (system *ECCShareSystem) Context() context.Context {
+	return system.failure.Context()
+}
+
+func (system *ECCShareSystem) NetErr() error {
+	return system.failure.Err()
+}
+
+func (system *ECCShareSystem) Send(wg *sync.WaitGroup, to int, msg []byte) {
 	atomic.AddInt64(&system.Com, int64(len(msg)))
 	if system.isWAN && system.BandwidthCtrl != nil {
 		system.BandwidthCtrl.SimulateSend(msg)
 	}
+	system.failure.Record(network.Deliver(system.Net, system.Partynum, to, msg))
 	wg.Done()
 }
 
-func (system *ECCShareSystem) Broadcast(wg *sync.WaitGroup, msg []byte) {
+func (system *ECCShareSystem) Broadcast(wg *sync.WaitGroup, from int, msg []byte) {
 	atomic.AddInt64(&system.Com, int64((system.Partynum-1)*len(msg)))
 	if system.isWAN && system.BandwidthCtrl != nil {
 		system.BandwidthCtrl.SimulateBroadcast(msg, system.Partynum-1)
 	}
+	system.failure.Record(network.DeliverAll(system.Net, from, msg))
 	wg.Done()
 }
 
@@ -209,22 +259,25 @@ func (system *ECCShareSystem) BroadcastN(wg *sync.WaitGroup, msg []byte) {
 	if system.isWAN && system.BandwidthCtrl != nil {
 		system.BandwidthCtrl.SimulateBroadcast(msg, system.Partynum)
 	}
+	system.failure.Record(network.DeliverAll(system.Net, system.Partynum, msg))
 	wg.Done()
 }
 
-func (system *ECCShareSystem) OfflineSend(wg *sync.WaitGroup, msg []byte) {
+func (system *ECCShareSystem) OfflineSend(wg *sync.WaitGroup, to int, msg []byte) {
 	atomic.AddInt64(&system.OfflineCom, int64(len(msg)))
 	if system.isWAN && system.BandwidthCtrl != nil {
 		system.BandwidthCtrl.SimulateSend(msg)
 	}
+	system.failure.Record(network.Deliver(system.Net, system.Partynum, to, msg))
 	wg.Done()
 }
 
-func (system *ECCShareSystem) OfflineBroadcast(wg *sync.WaitGroup, msg []byte) {
+func (system *ECCShareSystem) OfflineBroadcast(wg *sync.WaitGroup, from int, msg []byte) {
 	atomic.AddInt64(&system.OfflineCom, int64((system.Partynum-1)*len(msg)))
 	if system.isWAN && system.BandwidthCtrl != nil {
 		system.BandwidthCtrl.SimulateBroadcast(msg, system.Partynum-1)
 	}
+	system.failure.Record(network.DeliverAll(system.Net, from, msg))
 	wg.Done()
 }
 
@@ -233,22 +286,33 @@ func (system *ECCShareSystem) OfflineBroadcastN(wg *sync.WaitGroup, msg []byte)
 	if system.isWAN && system.BandwidthCtrl != nil {
 		system.BandwidthCtrl.SimulateBroadcast(msg, system.Partynum)
 	}
+	system.failure.Record(network.DeliverAll(system.Net, system.Partynum, msg))
 	wg.Done()
 }
 
-func (system *RSAShareSystem) Send(wg *sync.WaitGroup, msg []byte) {
+func (system *RSAShareSystem) Context() context.Context {
+	return system.failure.Context()
+}
+
+func (system *RSAShareSystem) NetErr() error {
+	return system.failure.Err()
+}
+
+func (system *RSAShareSystem) Send(wg *sync.WaitGroup, to int, msg []byte) {
 	atomic.AddInt64(&system.Com, int64(len(msg)))
 	if system.isWAN && system.BandwidthCtrl != nil {
 		system.BandwidthCtrl.SimulateSend(msg)
 	}
+	system.failure.Record(network.Deliver(system.Net, system.Partynum, to, msg))
 	wg.Done()
 }
 
-func (system *RSAShareSystem) Broadcast(wg *sync.WaitGroup, msg []byte) {
+func (system *RSAShareSystem) Broadcast(wg *sync.WaitGroup, from int, msg []byte) {
 	atomic.AddInt64(&system.Com, int64((system.Partynum-1)*len(msg)))
 	if system.isWAN && system.BandwidthCtrl != nil {
 		system.BandwidthCtrl.SimulateBroadcast(msg, system.Partynum-1)
 	}
+	system.failure.Record(network.DeliverAll(system.Net, from, msg))
 	wg.Done()
 }
 
@@ -257,22 +321,25 @@ func (system *RSAShareSystem) BroadcastN(wg *sync.WaitGroup, msg []byte) {
 	if system.isWAN && system.BandwidthCtrl != nil {
 		system.BandwidthCtrl.SimulateBroadcast(msg, system.Partynum)
 	}
+	system.failure.Record(network.DeliverAll(system.Net, system.Partynum, msg))
 	wg.Done()
 }
 
-func (system *RSAShareSystem) OfflineSend(wg *sync.WaitGroup, msg []byte) {
+func (system *RSAShareSystem) OfflineSend(wg *sync.WaitGroup, to int, msg []byte) {
 	atomic.AddInt64(&system.OfflineCom, int64(len(msg)))
 	if system.isWAN && system.BandwidthCtrl != nil {
 		system.BandwidthCtrl.SimulateSend(msg)
 	}
+	system.failure.Record(network.Deliver(system.Net, system.Partynum, to, msg))
 	wg.Done()
 }
 
-func (system *RSAShareSystem) OfflineBroadcast(wg *sync.WaitGroup, msg []byte) {
+func (system *RSAShareSystem) OfflineBroadcast(wg *sync.WaitGroup, from int, msg []byte) {
 	atomic.AddInt64(&system.OfflineCom, int64((system.Partynum-1)*len(msg)))
 	if system.isWAN && system.BandwidthCtrl != nil {
 		system.BandwidthCtrl.SimulateBroadcast(msg, system.Partynum-1)
 	}
+	system.failure.Record(network.DeliverAll(system.Net, from, msg))
 	wg.Done()
 }
 
@@ -281,10 +348,32 @@ func (system *RSAShareSystem) OfflineBroadcastN(wg *sync.WaitGroup, msg []byte)
 	if system.isWAN && system.BandwidthCtrl != nil {
 		system.BandwidthCtrl.SimulateBroadcast(msg, system.Partynum)
 	}
+	system.failure.Record(network.DeliverAll(system.Net, system.Partynum, msg))
 	wg.Done()
 }
 
-func (system *ShareSystem) GenTriplets() (*[]Share_Fp, *[]Share_Fp, *[]Share_Fp) {
+// GenTriplets returns a multiplication triple, or the *SacrificeError of a
+// failed sacrifice check. With a pool attached the triple is taken from the
+// pool, and a *PoolExhaustedError is returned once the pool is empty.
+func (system *ShareSystem) GenTriplets() (*[]Share_Fp, *[]Share_Fp, *[]Share_Fp, error) {
+	if system.Pool != nil {
+		t, err := system.Pool.Triplet()
+		return t.A, t.B, t.C, err
+	}
+	return system.freshTriplets()
+}
+
+func (system *ShareSystem) freshTriplets() (*[]Share_Fp, *[]Share_Fp, *[]Share_Fp, error) {
+	if system.sacrifice != nil {
+		return system.checkedTriplets()
+	}
+	return system.genTriplets()
+}
+
+func (system *ShareSystem) genTriplets() (*[]Share_Fp, *[]Share_Fp, *[]Share_Fp, error) {
+	if system.Offline != nil {
+		return offlineTriplets(system.Offline)
+	}
 	A, _ := curve.RandomK(rand.Reader)
 	B, _ := curve.RandomK(rand.Reader)
 	C := new(big.Int).Mul(A, B)
@@ -292,21 +381,50 @@ func (system *ShareSystem) GenTriplets() (*[]Share_Fp, *[]Share_Fp, *[]Share_Fp)
 	sharesA := system.Share_An_Fp_Offline(A)
 	sharesB := system.Share_An_Fp_Offline(B)
 	sharesC := system.Share_An_Fp_Offline(C)
-	return sharesA, sharesB, sharesC
+	return sharesA, sharesB, sharesC, nil
 }
 
-func (system *ShareSystem) GenTriplets_for_Exp() (*[]Share_Fp, *[]Share_Fp, *[]Share_Fp) {
+// GenTriplets_for_Exp returns a triple modulo OrderMul for the _for_EXP
+// shares, from the OfflineMul once it is set up and otherwise from the
+// dealer. It returns the errors of the offline phase, and ErrNoOfflinePhase
+// when neither is there because SetupDistributedMACKey failed.
+func (system *ShareSystem) GenTriplets_for_Exp() (*[]Share_Fp, *[]Share_Fp, *[]Share_Fp, error) {
+	if system.OfflineMul != nil {
+		return offlineTriplets(system.OfflineMul)
+	}
+	if system.alpha == nil {
+		return nil, nil, nil, ErrNoOfflinePhase
+	}
 	A, _ := curve.RandomK(rand.Reader)
 	B, _ := curve.RandomK(rand.Reader)
 	C := new(big.Int).Mul(A, B)
 	C = C.Mod(C, system.OrderMul)
-	sharesA := system.Share_An_Fp_for_EXP_Offline(A)
-	sharesB := system.Share_An_Fp_for_EXP_Offline(B)
-	sharesC := system.Share_An_Fp_for_EXP_Offline(C)
-	return sharesA, sharesB, sharesC
+	sharesA, err := system.Share_An_Fp_for_EXP_Offline(A)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	sharesB, err := system.Share_An_Fp_for_EXP_Offline(B)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	sharesC, err := system.Share_An_Fp_for_EXP_Offline(C)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return sharesA, sharesB, sharesC, nil
 }
 
-func (system *ECCShareSystem) GenTriplets() (*[]Share_Fp, *[]Share_Fp, *[]Share_Fp) {
+func (system *ECCShareSystem) GenTriplets() (*[]Share_Fp, *[]Share_Fp, *[]Share_Fp, error) {
+	if system.sacrifice != nil {
+		return system.checkedTriplets()
+	}
+	return system.genTriplets()
+}
+
+func (system *ECCShareSystem) genTriplets() (*[]Share_Fp, *[]Share_Fp, *[]Share_Fp, error) {
+	if system.Offline != nil {
+		return offlineTriplets(system.Offline)
+	}
 	A, _ := rand.Int(rand.Reader, system.Order)
 	B, _ := rand.Int(rand.Reader, system.Order)
 	C := new(big.Int).Mul(A, B)
@@ -314,7 +432,7 @@ func (system *ECCShareSystem) GenTriplets() (*[]Share_Fp, *[]Share_Fp, *[]Share_
 	sharesA := system.Share_An_Fp_Offline(A)
 	sharesB := system.Share_An_Fp_Offline(B)
 	sharesC := system.Share_An_Fp_Offline(C)
-	return sharesA, sharesB, sharesC
+	return sharesA, sharesB, sharesC, nil
 }
 
 func (system *RSAShareSystem) GenTriplets() (*[]Share_Fn, *[]Share_Fn, *[]Share_Fn) {
@@ -328,22 +446,50 @@ func (system *RSAShareSystem) GenTriplets() (*[]Share_Fn, *[]Share_Fn, *[]Share_
 	return sharesA, sharesB, sharesC
 }
 
-func (system *ShareSystem) GenSquarePair() (*[]Share_Fp, *[]Share_Fp) {
+func (system *ShareSystem) GenSquarePair() (*[]Share_Fp, *[]Share_Fp, error) {
+	if system.Pool != nil {
+		p, err := system.Pool.SquarePair()
+		return p.A, p.B, err
+	}
+	return system.freshSquarePair()
+}
+
+func (system *ShareSystem) freshSquarePair() (*[]Share_Fp, *[]Share_Fp, error) {
+	if system.sacrifice != nil {
+		return system.checkedSquarePair()
+	}
+	return system.genSquarePair()
+}
+
+func (system *ShareSystem) genSquarePair() (*[]Share_Fp, *[]Share_Fp, error) {
+	if system.Offline != nil {
+		return offlineSquarePair(system.Offline)
+	}
 	A, _ := curve.RandomK(rand.Reader)
 	B := new(big.Int).Mul(A, A)
 	B = B.Mod(B, system.Order)
 	sharesA := system.Share_An_Fp_Offline(A)
 	sharesB := system.Share_An_Fp_Offline(B)
-	return sharesA, sharesB
+	return sharesA, sharesB, nil
+}
+
+func (system *ECCShareSystem) GenSquarePair() (*[]Share_Fp, *[]Share_Fp, error) {
+	if system.sacrifice != nil {
+		return system.checkedSquarePair()
+	}
+	return system.genSquarePair()
 }
 
-func (system *ECCShareSystem) GenSquarePair() (*[]Share_Fp, *[]Share_Fp) {
+func (system *ECCShareSystem) genSquarePair() (*[]Share_Fp, *[]Share_Fp, error) {
+	if system.Offline != nil {
+		return offlineSquarePair(system.Offline)
+	}
 	A, _ := rand.Int(rand.Reader, system.Order)
 	B := new(big.Int).Mul(A, A)
 	B = B.Mod(B, system.Order)
 	sharesA := system.Share_An_Fp_Offline(A)
 	sharesB := system.Share_An_Fp_Offline(B)
-	return sharesA, sharesB
+	return sharesA, sharesB, nil
 }
 
 func (system *RSAShareSystem) GenSquarePair() (*[]Share_Fn, *[]Share_Fn) {
@@ -371,7 +517,9 @@ func OrderOfElement(element, modulus *big.Int) *big.Int {
 
 func SystemInit(Partynum int) *ShareSystem {
 	system := new(ShareSystem)
+	system.failure = network.NewFailure()
 	system.Partynum = Partynum
+	system.Net = network.NewChanNetwork(Partynum + 1)
 	system.alpha, _ = curve.RandomK(rand.Reader)
 	orialpha := new(big.Int).Set(system.alpha)
 	orialphamul := new(big.Int).Set(system.alpha)
@@ -402,7 +550,9 @@ func SystemInit(Partynum int) *ShareSystem {
 
 func ECCSystemInit(Partynum int) *ECCShareSystem {
 	system := new(ECCShareSystem)
+	system.failure = network.NewFailure()
 	system.Partynum = Partynum
+	system.Net = network.NewChanNetwork(Partynum + 1)
 	s := ecc.S256()
 	system.alpha, _ = rand.Int(rand.Reader, s.N)
 	orialpha := new(big.Int).Set(system.alpha)
@@ -424,7 +574,9 @@ func ECCSystemInit(Partynum int) *ECCShareSystem {
 
 func RSASystemInit(Partynum int, Element *big.Int, Order *big.Int) *RSAShareSystem {
 	system := new(RSAShareSystem)
+	system.failure = network.NewFailure()
 	system.Partynum = Partynum
+	system.Net = network.NewChanNetwork(Partynum + 1)
 	system.OrderMul = OrderOfElement(Element, Order)
 	system.alpha, _ = rand.Int(rand.Reader, system.OrderMul)
 	orialpha := new(big.Int).Set(system.alpha)
@@ -442,9 +594,15 @@ func RSASystemInit(Partynum int, Element *big.Int, Order *big.Int) *RSAShareSyst
 	return system
 }
 
-func SystemInitWAN(Partynum int, bandwidth float64) *ShareSystem {
+// SystemInitWAN returns ErrBandwidth when bandwidth is not positive.
+func SystemInitWAN(Partynum int, bandwidth float64) (*ShareSystem, error) {
+	if bandwidth <= 0 {
+		return nil, ErrBandwidth
+	}
 	system := new(ShareSystem)
+	system.failure = network.NewFailure()
 	system.Partynum = Partynum
+	system.Net = network.NewChanNetwork(Partynum + 1)
 	system.alpha, _ = curve.RandomK(rand.Reader)
 	orialpha := new(big.Int).Set(system.alpha)
 	orialphamul := new(big.Int).Set(system.alpha)
@@ -471,18 +629,19 @@ func SystemInitWAN(Partynum int, bandwidth float64) *ShareSystem {
 	system.IdentityGTBytes = system.IdentityGT.Marshal()
 	system.Order = new(big.Int).Set(curve.Order)
 	system.isWAN = true
-	if bandwidth > 0 {
-		system.BandwidthCtrl = NewBandwidthSimulator(bandwidth)
-	} else {
-		fmt.Println("The bindwidth < 0")
-		os.Exit(1)
-	}
-	return system
+	system.BandwidthCtrl = NewBandwidthSimulator(bandwidth)
+	return system, nil
 }
 
-func ECCSystemInitWAN(Partynum int, bandwidth float64) *ECCShareSystem {
+// ECCSystemInitWAN returns ErrBandwidth when bandwidth is not positive.
+func ECCSystemInitWAN(Partynum int, bandwidth float64) (*ECCShareSystem, error) {
+	if bandwidth <= 0 {
+		return nil, ErrBandwidth
+	}
 	system := new(ECCShareSystem)
+	system.failure = network.NewFailure()
 	system.Partynum = Partynum
+	system.Net = network.NewChanNetwork(Partynum + 1)
 	s := ecc.S256()
 	system.alpha, _ = rand.Int(rand.Reader, s.N)
 	orialpha := new(big.Int).Set(system.alpha)
@@ -500,18 +659,19 @@ func ECCSystemInitWAN(Partynum int, bandwidth float64) *ECCShareSystem {
 	system.Order = new(big.Int).Set(s.N)
 	system.Curve = s
 	system.isWAN = true
-	if bandwidth > 0 {
-		system.BandwidthCtrl = NewBandwidthSimulator(bandwidth)
-	} else {
-		fmt.Println("The bindwidth < 0")
-		os.Exit(1)
-	}
-	return system
+	system.BandwidthCtrl = NewBandwidthSimulator(bandwidth)
+	return system, nil
 }
 
-func RSASystemInitWAN(Partynum int, Element *big.Int, Order *big.Int, bandwidth float64) *RSAShareSystem {
+// RSASystemInitWAN returns ErrBandwidth when bandwidth is not positive.
+func RSASystemInitWAN(Partynum int, Element *big.Int, Order *big.Int, bandwidth float64) (*RSAShareSystem, error) {
+	if bandwidth <= 0 {
+		return nil, ErrBandwidth
+	}
 	system := new(RSAShareSystem)
+	system.failure = network.NewFailure()
 	system.Partynum = Partynum
+	system.Net = network.NewChanNetwork(Partynum + 1)
 	system.OrderMul = OrderOfElement(Element, Order)
 	system.alpha, _ = rand.Int(rand.Reader, system.OrderMul)
 	orialpha := new(big.Int).Set(system.alpha)
@@ -527,11 +687,6 @@ func RSASystemInitWAN(Partynum int, Element *big.Int, Order *big.Int, bandwidth
 	}
 	system.Order = new(big.Int).Set(Order)
 	system.isWAN = true
-	if bandwidth > 0 {
-		system.BandwidthCtrl = NewBandwidthSimulator(bandwidth)
-	} else {
-		fmt.Println("The bindwidth < 0")
-		os.Exit(1)
-	}
-	return system
+	system.BandwidthCtrl = NewBandwidthSimulator(bandwidth)
+	return system, nil
 }
@@ -0,0 +1,85 @@
+package network
+
+import (
+	"sync"
+)
+
+const chanBuffer = 1024
+
+// ChanTransport is one endpoint of an in-memory mesh where every ordered pair
+// of parties, including a party and itself, is linked by a buffered channel.
+type ChanTransport struct {
+	index    int
+	partynum int
+	links    [][]chan []byte
+	done     chan struct{}
+	once     *sync.Once
+}
+
+// NewChanNetwork connects Partynum in-memory endpoints. Closing any endpoint
+// closes the whole mesh.
+func NewChanNetwork(Partynum int) []Transport {
+	links := make([][]chan []byte, Partynum)
+	for i := 0; i < Partynum; i++ {
+		links[i] = make([]chan []byte, Partynum)
+		for j := 0; j < Partynum; j++ {
+			links[i][j] = make(chan []byte, chanBuffer)
+		}
+	}
+	done := make(chan struct{})
+	once := new(sync.Once)
+	endpoints := make([]Transport, Partynum)
+	for i := 0; i < Partynum; i++ {
+		endpoints[i] = &ChanTransport{
+			index:    i,
+			partynum: Partynum,
+			links:    links,
+			done:     done,
+			once:     once,
+		}
+	}
+	return endpoints
+}
+
+func (t *ChanTransport) Index() int {
+	return t.index
+}
+
+func (t *ChanTransport) Partynum() int {
+	return t.partynum
+}
+
+func (t *ChanTransport) Send(to int, msg []byte) error {
+	if to < 0 || to >= t.partynum {
+		return ErrNoPeer
+	}
+	select {
+	case <-t.done:
+		return ErrClosed
+	case t.links[t.index][to] <- msg:
+		return nil
+	}
+}
+
+func (t *ChanTransport) Recv(from int) ([]byte, error) {
+	if from < 0 || from >= t.partynum {
+		return nil, ErrNoPeer
+	}
+	select {
+	case <-t.done:
+		return nil, ErrClosed
+	case msg := <-t.links[from][t.index]:
+		return msg, nil
+	}
+}
+
+func (t *ChanTransport) Broadcast(msg []byte) error {
+	return BroadcastAll(t, msg)
+}
+
+func (t *ChanTransport) Close() error {
+	t.once.Do(func() {
+		close(t.done)
+	})
+	return nil
+}
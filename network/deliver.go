@@ -0,0 +1,73 @@
+package network
+
+import (
+	"context"
+	"log"
+)
+
+// Deliver is how the simulators, which play every party in one process,
+// move a message: endpoint from sends it and endpoint to drains it. Their
+// send paths run in goroutines with no caller to hand an error to, so they
+// pass it to Failure.Record; a failed send or receive is logged as well.
+func Deliver(net []Transport, from, to int, msg []byte) error {
+	err := net[from].Send(to, msg)
+	if err == nil {
+		_, err = net[to].Recv(from)
+	}
+	if err != nil {
+		log.Printf("network: delivery from %d to %d: %v", from, to, err)
+	}
+	return err
+}
+
+// DeliverAll delivers msg from endpoint from to every party. The last
+// endpoint belongs to the dealer and is skipped. It returns the first error.
+func DeliverAll(net []Transport, from int, msg []byte) error {
+	var first error
+	for j := 0; j < len(net)-1; j++ {
+		if j != from {
+			if err := Deliver(net, from, j, msg); err != nil && first == nil {
+				first = err
+			}
+		}
+	}
+	return first
+}
+
+// Failure records the first failed delivery of a simulator. Its Context is
+// cancelled with that error, so runs that derive their context from it stop.
+// A nil Failure records nothing.
+type Failure struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+}
+
+// NewFailure returns a Failure with nothing recorded.
+func NewFailure() *Failure {
+	f := new(Failure)
+	f.ctx, f.cancel = context.WithCancelCause(context.Background())
+	return f
+}
+
+// Context returns the context that Record cancels.
+func (f *Failure) Context() context.Context {
+	if f == nil {
+		return context.Background()
+	}
+	return f.ctx
+}
+
+// Err returns the first recorded error, or nil.
+func (f *Failure) Err() error {
+	if f == nil {
+		return nil
+	}
+	return context.Cause(f.ctx)
+}
+
+// Record records err unless it is nil or an earlier error was recorded.
+func (f *Failure) Record(err error) {
+	if f != nil && err != nil {
+		f.cancel(err)
+	}
+}
@@ -0,0 +1,76 @@
+package network
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// MaxFrame bounds the payload of a single frame so that a corrupt length
+// prefix cannot make a reader allocate unbounded memory.
+const MaxFrame = 1 << 26
+
+var ErrFrameTooLarge = errors.New("network: frame too large")
+var ErrShortMessage = errors.New("network: truncated message")
+
+// WriteFrame writes msg prefixed with its length as a 4-byte big-endian integer.
+func WriteFrame(w io.Writer, msg []byte) error {
+	if len(msg) > MaxFrame {
+		return ErrFrameTooLarge
+	}
+	buf := make([]byte, 4+len(msg))
+	binary.BigEndian.PutUint32(buf, uint32(len(msg)))
+	copy(buf[4:], msg)
+	_, err := w.Write(buf)
+	return err
+}
+
+// ReadFrame reads one frame written by WriteFrame.
+func ReadFrame(r io.Reader) ([]byte, error) {
+	var head [4]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(head[:])
+	if size > MaxFrame {
+		return nil, ErrFrameTooLarge
+	}
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// Pack concatenates several fields into one message using the frame layout,
+// so that composite values such as a share and its MAC travel together.
+func Pack(parts ...[]byte) []byte {
+	size := 0
+	for _, part := range parts {
+		size += 4 + len(part)
+	}
+	buf := make([]byte, 0, size)
+	for _, part := range parts {
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(part)))
+		buf = append(buf, part...)
+	}
+	return buf
+}
+
+// Unpack splits a message built by Pack back into its fields.
+func Unpack(msg []byte) ([][]byte, error) {
+	parts := make([][]byte, 0)
+	for len(msg) > 0 {
+		if len(msg) < 4 {
+			return nil, ErrShortMessage
+		}
+		size := binary.BigEndian.Uint32(msg)
+		msg = msg[4:]
+		if uint32(len(msg)) < size {
+			return nil, ErrShortMessage
+		}
+		parts = append(parts, msg[:size:size])
+		msg = msg[size:]
+	}
+	return parts, nil
+}
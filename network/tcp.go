@@ -0,0 +1,207 @@
+package network
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+var ErrHandshake = errors.New("network: bad handshake")
+
+// TCPTransport is one endpoint of a full TCP mesh. Party i listens on
+// Addrs[i], dials every party with a smaller index and accepts connections
+// from every party with a larger one. Each connection starts with the
+// dialer's index as a 4-byte big-endian integer, after which messages are
+// exchanged as length-prefixed frames.
+type TCPTransport struct {
+	index    int
+	Addrs    []string
+	listener net.Listener
+	conns    []net.Conn
+	wlocks   []sync.Mutex
+	inbox    []chan []byte
+	errs     []error
+	done     chan struct{}
+	once     sync.Once
+}
+
+// NewTCPTransport starts party index of the mesh described by addrs and
+// blocks until it is connected to every peer or timeout expires.
+func NewTCPTransport(index int, addrs []string, timeout time.Duration) (*TCPTransport, error) {
+	if index < 0 || index >= len(addrs) {
+		return nil, ErrNoPeer
+	}
+	t := &TCPTransport{
+		index:  index,
+		Addrs:  addrs,
+		conns:  make([]net.Conn, len(addrs)),
+		wlocks: make([]sync.Mutex, len(addrs)),
+		inbox:  make([]chan []byte, len(addrs)),
+		errs:   make([]error, len(addrs)),
+		done:   make(chan struct{}),
+	}
+	for j := range t.inbox {
+		t.inbox[j] = make(chan []byte, chanBuffer)
+	}
+	listener, err := net.Listen("tcp", addrs[index])
+	if err != nil {
+		return nil, err
+	}
+	t.listener = listener
+	deadline := time.Now().Add(timeout)
+	accepted := make(chan error, 1)
+	go func() {
+		accepted <- t.accept(len(addrs)-index-1, deadline)
+	}()
+	for j := 0; j < index; j++ {
+		conn, err := dial(addrs[j], deadline)
+		if err != nil {
+			t.abort(accepted)
+			return nil, fmt.Errorf("network: dial party %d: %w", j, err)
+		}
+		var head [4]byte
+		binary.BigEndian.PutUint32(head[:], uint32(index))
+		if _, err := conn.Write(head[:]); err != nil {
+			conn.Close()
+			t.abort(accepted)
+			return nil, err
+		}
+		t.conns[j] = conn
+	}
+	if err := <-accepted; err != nil {
+		t.Close()
+		return nil, err
+	}
+	for j, conn := range t.conns {
+		if conn != nil {
+			go t.readLoop(j, conn)
+		}
+	}
+	return t, nil
+}
+
+// abort gives up a half-built mesh. Closing the listener ends accept, which
+// is waited for before the connections it may still be storing are closed.
+func (t *TCPTransport) abort(accepted chan error) {
+	t.listener.Close()
+	<-accepted
+	t.Close()
+}
+
+func dial(addr string, deadline time.Time) (net.Conn, error) {
+	for {
+		conn, err := net.DialTimeout("tcp", addr, time.Until(deadline))
+		if err == nil {
+			return conn, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, err
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func (t *TCPTransport) accept(count int, deadline time.Time) error {
+	if tl, ok := t.listener.(*net.TCPListener); ok {
+		tl.SetDeadline(deadline)
+	}
+	for k := 0; k < count; k++ {
+		conn, err := t.listener.Accept()
+		if err != nil {
+			return err
+		}
+		var head [4]byte
+		conn.SetReadDeadline(deadline)
+		if _, err := io.ReadFull(conn, head[:]); err != nil {
+			conn.Close()
+			return err
+		}
+		conn.SetReadDeadline(time.Time{})
+		peer := int(binary.BigEndian.Uint32(head[:]))
+		if peer <= t.index || peer >= len(t.conns) || t.conns[peer] != nil {
+			conn.Close()
+			return ErrHandshake
+		}
+		t.conns[peer] = conn
+	}
+	return nil
+}
+
+func (t *TCPTransport) readLoop(from int, conn net.Conn) {
+	for {
+		msg, err := ReadFrame(conn)
+		if err != nil {
+			t.errs[from] = err
+			close(t.inbox[from])
+			return
+		}
+		select {
+		case t.inbox[from] <- msg:
+		case <-t.done:
+			return
+		}
+	}
+}
+
+func (t *TCPTransport) Index() int {
+	return t.index
+}
+
+func (t *TCPTransport) Partynum() int {
+	return len(t.Addrs)
+}
+
+func (t *TCPTransport) Send(to int, msg []byte) error {
+	if to < 0 || to >= len(t.Addrs) {
+		return ErrNoPeer
+	}
+	if to == t.index {
+		select {
+		case <-t.done:
+			return ErrClosed
+		case t.inbox[to] <- msg:
+			return nil
+		}
+	}
+	t.wlocks[to].Lock()
+	defer t.wlocks[to].Unlock()
+	return WriteFrame(t.conns[to], msg)
+}
+
+func (t *TCPTransport) Recv(from int) ([]byte, error) {
+	if from < 0 || from >= len(t.Addrs) {
+		return nil, ErrNoPeer
+	}
+	select {
+	case <-t.done:
+		return nil, ErrClosed
+	case msg, ok := <-t.inbox[from]:
+		if !ok {
+			return nil, fmt.Errorf("network: party %d: %w", from, t.errs[from])
+		}
+		return msg, nil
+	}
+}
+
+func (t *TCPTransport) Broadcast(msg []byte) error {
+	return BroadcastAll(t, msg)
+}
+
+func (t *TCPTransport) Close() error {
+	t.once.Do(func() {
+		close(t.done)
+		if t.listener != nil {
+			t.listener.Close()
+		}
+		for _, conn := range t.conns {
+			if conn != nil {
+				conn.Close()
+			}
+		}
+	})
+	return nil
+}
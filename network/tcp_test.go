@@ -0,0 +1,97 @@
+package network
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// When TCP_PARTY is set the test binary runs as one party of the mesh that
+// TestTCPMeshProcesses starts.
+func TestMain(m *testing.M) {
+	if index, ok := os.LookupEnv("TCP_PARTY"); ok {
+		i, _ := strconv.Atoi(index)
+		if err := runParty(i, strings.Split(os.Getenv("TCP_ADDRS"), ",")); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runParty broadcasts its index and expects every peer's index back.
+func runParty(index int, addrs []string) error {
+	t, err := NewTCPTransport(index, addrs, 10*time.Second)
+	if err != nil {
+		return err
+	}
+	defer t.Close()
+	if err := t.Broadcast([]byte{byte(index)}); err != nil {
+		return err
+	}
+	msgs, err := RecvAll(t)
+	if err != nil {
+		return err
+	}
+	for j, msg := range msgs {
+		if j != index && !bytes.Equal(msg, []byte{byte(j)}) {
+			return fmt.Errorf("party %d got %v from %d", index, msg, j)
+		}
+	}
+	// Wait for the peers to read before the connections go away.
+	if err := t.Broadcast([]byte("done")); err != nil {
+		return err
+	}
+	_, err = RecvAll(t)
+	return err
+}
+
+func freeAddrs(t *testing.T, n int) []string {
+	addrs := make([]string, n)
+	for i := range addrs {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		addrs[i] = l.Addr().String()
+		l.Close()
+	}
+	return addrs
+}
+
+func TestTCPMeshProcesses(t *testing.T) {
+	const n = 4
+	addrs := freeAddrs(t, n)
+	cmds := make([]*exec.Cmd, n)
+	outs := make([]*bytes.Buffer, n)
+	for i := 0; i < n; i++ {
+		cmds[i] = exec.Command(os.Args[0], "-test.run=^$")
+		cmds[i].Env = append(os.Environ(), "TCP_PARTY="+strconv.Itoa(i), "TCP_ADDRS="+strings.Join(addrs, ","))
+		outs[i] = new(bytes.Buffer)
+		cmds[i].Stderr = outs[i]
+		if err := cmds[i].Start(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			t.Errorf("party %d: %v: %s", i, err, outs[i])
+		}
+	}
+}
+
+func TestTCPDialFailure(t *testing.T) {
+	addrs := freeAddrs(t, 3)
+	// Party 1 dials party 0, which never starts, while it accepts party 2.
+	_, err := NewTCPTransport(1, addrs, 300*time.Millisecond)
+	if err == nil {
+		t.Fatal("expected a dial error")
+	}
+}
@@ -0,0 +1,49 @@
+package network
+
+import (
+	"errors"
+)
+
+var ErrClosed = errors.New("network: transport closed")
+var ErrNoPeer = errors.New("network: unknown party")
+
+// Transport carries protocol messages between the parties of one session.
+// Each party owns one endpoint; Index is the party's position in the session.
+type Transport interface {
+	Index() int
+	Partynum() int
+	Send(to int, msg []byte) error
+	Recv(from int) ([]byte, error)
+	Broadcast(msg []byte) error
+	Close() error
+}
+
+// BroadcastAll sends msg to every other party through t, stopping at the first error.
+func BroadcastAll(t Transport, msg []byte) error {
+	for j := 0; j < t.Partynum(); j++ {
+		if j == t.Index() {
+			continue
+		}
+		if err := t.Send(j, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecvAll receives one message from every other party. The entry for the
+// caller's own index is left nil.
+func RecvAll(t Transport) ([][]byte, error) {
+	msgs := make([][]byte, t.Partynum())
+	for j := 0; j < t.Partynum(); j++ {
+		if j == t.Index() {
+			continue
+		}
+		msg, err := t.Recv(j)
+		if err != nil {
+			return nil, err
+		}
+		msgs[j] = msg
+	}
+	return msgs, nil
+}
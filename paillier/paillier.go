@@ -0,0 +1,89 @@
+package paillier
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+)
+
+var one = big.NewInt(1)
+
+var ErrMessageTooLarge = errors.New("paillier: message not smaller than N")
+
+// PublicKey uses the generator N+1, so encryption is (1+mN)·r^N mod N^2.
+type PublicKey struct {
+	N  *big.Int
+	N2 *big.Int
+}
+
+type PrivateKey struct {
+	PublicKey
+	Lambda *big.Int
+	Mu     *big.Int
+}
+
+func GenerateKey(random io.Reader, bits int) (*PrivateKey, error) {
+	for {
+		p, err := rand.Prime(random, bits/2)
+		if err != nil {
+			return nil, err
+		}
+		q, err := rand.Prime(random, bits-bits/2)
+		if err != nil {
+			return nil, err
+		}
+		if p.Cmp(q) == 0 {
+			continue
+		}
+		n := new(big.Int).Mul(p, q)
+		p1 := new(big.Int).Sub(p, one)
+		q1 := new(big.Int).Sub(q, one)
+		lambda := new(big.Int).Mul(p1, q1)
+		mu := new(big.Int).ModInverse(lambda, n)
+		if mu == nil {
+			continue
+		}
+		sk := new(PrivateKey)
+		sk.N = n
+		sk.N2 = new(big.Int).Mul(n, n)
+		sk.Lambda = lambda
+		sk.Mu = mu
+		return sk, nil
+	}
+}
+
+func (pk *PublicKey) Encrypt(m *big.Int) (*big.Int, error) {
+	if m.Sign() < 0 || m.Cmp(pk.N) >= 0 {
+		return nil, ErrMessageTooLarge
+	}
+	r, err := rand.Int(rand.Reader, pk.N)
+	if err != nil {
+		return nil, err
+	}
+	gm := new(big.Int).Mul(m, pk.N)
+	gm = gm.Add(gm, one)
+	gm = gm.Mod(gm, pk.N2)
+	rn := new(big.Int).Exp(r, pk.N, pk.N2)
+	c := gm.Mul(gm, rn)
+	return c.Mod(c, pk.N2), nil
+}
+
+func (sk *PrivateKey) Decrypt(c *big.Int) *big.Int {
+	u := new(big.Int).Exp(c, sk.Lambda, sk.N2)
+	u = u.Sub(u, one)
+	u = u.Div(u, sk.N)
+	u = u.Mul(u, sk.Mu)
+	return u.Mod(u, sk.N)
+}
+
+// Add returns an encryption of the sum of the plaintexts of c1 and c2.
+func (pk *PublicKey) Add(c1, c2 *big.Int) *big.Int {
+	c := new(big.Int).Mul(c1, c2)
+	return c.Mod(c, pk.N2)
+}
+
+// MulConst returns an encryption of k times the plaintext of c.
+func (pk *PublicKey) MulConst(c, k *big.Int) *big.Int {
+	return new(big.Int).Exp(c, k, pk.N2)
+}
@@ -0,0 +1,287 @@
+package pii
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/Oryx/curve"
+	"github.com/Oryx/mpc"
+)
+
+// Backend is what Interphase and Interphase_m need from a share system whose
+// shares of Fp and GT are F and T. PIISystem runs them on mpc, pii_shamir and
+// pii_rss on their own systems.
+type Backend[F, T any] interface {
+	SecAdd(shares1, shares2 []F) *[]F
+	SecSub(shares1, shares2 []F) *[]F
+	SecMul(shares1, shares2 []F) (*[]F, error)
+	EXP_P_GT_1(element *curve.GT, xshares *[]F) *[]T
+	SecAdd_GT(shares1, shares2 []T) *[]T
+	EXP_S_GT(hshares []T, xshares []F) (*[]T, error)
+	NewBatch() Batch[F, T]
+	// Context is the context the comparisons derive theirs from; it is
+	// cancelled when the system fails to deliver a message.
+	Context() context.Context
+}
+
+// Batch collects openings whose values may only be used for outputs once
+// Check has returned nil. party and element locate an opening in the error
+// of a failed check.
+type Batch[F, T any] interface {
+	OpenFp(party, element int, shares []F) (*big.Int, error)
+	OpenGT(party, element int, shares []T) (*curve.GT, error)
+	Check(op string) error
+}
+
+// Verified holds the shared results of SecVerWithoutOpen and the shared
+// hashed identities of one party.
+type Verified[F, T any] struct {
+	Vers []*[]T
+	HIDs []*[]F
+}
+
+// Interphase compares every element of party 0 with every element of party
+// 1. g^(hid0 - hid1) times both verification results, masked with the seed
+// of the pair, is the identity exactly when the identities match and both
+// signatures are valid. The comparisons are checked together before the
+// matching identities of party 0 are opened.
+func Interphase[F, T any](backend Backend[F, T], g *curve.GT, identity []byte, versets []Verified[F, T], seeds [][]*[]F) ([]*big.Int, error) {
+	var mu sync.Mutex
+	matches := make([]int, 0)
+	ctx, cancel := context.WithCancelCause(backend.Context())
+	defer cancel(nil)
+	var wg sync.WaitGroup
+	batch := backend.NewBatch()
+	compare := func(i, j int) error {
+		v := backend.SecSub(*versets[0].HIDs[i], *versets[1].HIDs[j])
+		w := backend.EXP_P_GT_1(g, v)
+		w = backend.SecAdd_GT(*w, *versets[0].Vers[i])
+		w = backend.SecAdd_GT(*w, *versets[1].Vers[j])
+		w, err := backend.EXP_S_GT(*w, *seeds[i][j])
+		if err != nil {
+			return err
+		}
+		wvalue, err := batch.OpenGT(0, i, *w)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(wvalue.Marshal(), identity) {
+			mu.Lock()
+			matches = append(matches, i)
+			mu.Unlock()
+		}
+		return nil
+	}
+	if len(versets[0].HIDs)*len(versets[1].HIDs) <= 16384 {
+		for i := range versets[0].HIDs {
+			for j := range versets[1].HIDs {
+				wg.Add(1)
+				go func(i, j int) {
+					defer wg.Done()
+					defer mpc.Recover(cancel)
+					if ctx.Err() != nil {
+						return
+					}
+					if err := compare(i, j); err != nil {
+						cancel(err)
+					}
+				}(i, j)
+			}
+		}
+	} else {
+		for i := range versets[0].HIDs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				defer mpc.Recover(cancel)
+				for j := range versets[1].HIDs {
+					if ctx.Err() != nil {
+						return
+					}
+					if err := compare(i, j); err != nil {
+						cancel(err)
+						return
+					}
+				}
+			}(i)
+		}
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+	if err := batch.Check("interphase"); err != nil {
+		return nil, err
+	}
+	return openhids(backend, versets[0].HIDs, matches)
+}
+
+// Interphase_m is the comparison for any number of parties. The verification
+// results are opened first. For each valid element of party 0 the products of
+// its differences to the valid elements of every other party are added up;
+// the sum, masked with the seed of the element, is zero exactly when each
+// party holds the identity, except with negligible probability.
+func Interphase_m[F, T any](backend Backend[F, T], g *curve.GT, identity []byte, versets []Verified[F, T], seeds []*[]F) ([]*big.Int, error) {
+	var wg sync.WaitGroup
+	verres := make([][]bool, len(versets))
+	verbatch := backend.NewBatch()
+	ctx, cancel := context.WithCancelCause(backend.Context())
+	defer cancel(nil)
+	for k := range versets {
+		verres[k] = make([]bool, len(versets[k].Vers))
+		for j := range versets[k].Vers {
+			wg.Add(1)
+			go func(k, j int) {
+				defer wg.Done()
+				defer mpc.Recover(cancel)
+				ver, err := verbatch.OpenGT(k, j, *versets[k].Vers[j])
+				if err != nil {
+					cancel(err)
+					return
+				}
+				verres[k][j] = bytes.Equal(ver.Marshal(), identity)
+			}(k, j)
+		}
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+	if err := verbatch.Check("verphase"); err != nil {
+		return nil, err
+	}
+	var mu sync.Mutex
+	matches := make([]int, 0)
+	batch := backend.NewBatch()
+	compare := func(i int) error {
+		var v *[]F
+		for k := 1; k < len(versets); k++ {
+			var p *[]F
+			for t := range versets[k].HIDs {
+				if ctx.Err() != nil {
+					return nil
+				}
+				if !verres[k][t] {
+					continue
+				}
+				d := backend.SecSub(*versets[0].HIDs[i], *versets[k].HIDs[t])
+				if p == nil {
+					p = d
+					continue
+				}
+				var err error
+				if p, err = backend.SecMul(*p, *d); err != nil {
+					return err
+				}
+			}
+			if p == nil {
+				// no valid element, so nothing is in the intersection
+				return nil
+			}
+			if v == nil {
+				v = p
+			} else {
+				v = backend.SecAdd(*v, *p)
+			}
+		}
+		u := backend.EXP_P_GT_1(g, v)
+		u, err := backend.EXP_S_GT(*u, *seeds[i])
+		if err != nil {
+			return err
+		}
+		uvalue, err := batch.OpenGT(0, i, *u)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(uvalue.Marshal(), identity) {
+			mu.Lock()
+			matches = append(matches, i)
+			mu.Unlock()
+		}
+		return nil
+	}
+	for i := range versets[0].HIDs {
+		if !verres[0][i] {
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer mpc.Recover(cancel)
+			if err := compare(i); err != nil {
+				cancel(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+	if err := batch.Check("interphase"); err != nil {
+		return nil, err
+	}
+	return openhids(backend, versets[0].HIDs, matches)
+}
+
+// openhids opens the identities of party 0 at the matching positions, in
+// order, in a batch of its own. It is called only once the comparisons
+// passed their check, so a forged match cannot make the parties open an
+// identity outside the intersection.
+func openhids[F, T any](backend Backend[F, T], hids []*[]F, matches []int) ([]*big.Int, error) {
+	sort.Ints(matches)
+	batch := backend.NewBatch()
+	ids := make([]*big.Int, len(matches))
+	for k, i := range matches {
+		id, err := batch.OpenFp(0, i, *hids[i])
+		if err != nil {
+			return nil, err
+		}
+		ids[k] = id
+	}
+	if err := batch.Check("interphase"); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// mpcBackend runs the comparisons on the malicious system, whose openings
+// are MAC checked in deferred batches.
+type mpcBackend struct {
+	*mpc.ShareSystem
+}
+
+func (backend mpcBackend) SecMul(shares1, shares2 []mpc.Share_Fp) (*[]mpc.Share_Fp, error) {
+	return backend.ShareSystem.SecMul(shares1, shares2), nil
+}
+
+func (backend mpcBackend) EXP_S_GT(hshares []mpc.Share_GT, xshares []mpc.Share_Fp) (*[]mpc.Share_GT, error) {
+	return backend.ShareSystem.EXP_S_GT(hshares, xshares), nil
+}
+
+func (backend mpcBackend) NewBatch() Batch[mpc.Share_Fp, mpc.Share_GT] {
+	return &mpcBatch{system: backend.ShareSystem, batch: backend.NewOpenBatch()}
+}
+
+type mpcBatch struct {
+	system *mpc.ShareSystem
+	batch  *mpc.OpenBatch
+}
+
+func (batch *mpcBatch) OpenFp(party, element int, shares []mpc.Share_Fp) (*big.Int, error) {
+	return batch.batch.At(party, element).OpenFp(shares), nil
+}
+
+func (batch *mpcBatch) OpenGT(party, element int, shares []mpc.Share_GT) (*curve.GT, error) {
+	return batch.batch.At(party, element).OpenGT(shares), nil
+}
+
+// Check returns the *mpc.MacCheckError of a failed DeferredMacCheck.
+func (batch *mpcBatch) Check(op string) error {
+	if !batch.system.DeferredMacCheck(batch.batch) {
+		return batch.batch.Err(op)
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package pii
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/Oryx/curve"
+	"github.com/Oryx/hashtable"
+	"github.com/Oryx/mpc"
+)
+
+var ErrHashing = errors.New("pii: cuckoo hashing of the ids failed")
+
+// bins places the inputs of two parties into the same bins, each party
+// hashing its own ids. Party 0 cuckoo-hashes, so a bin holds at most one of
+// its elements (first[b] is -1 for an empty bin). Party 1 simple-hashes with
+// the same three hash functions, so an element of party 1 shares a bin with
+// an equal element of party 0 exactly once. Every bin of party 1 is padded
+// to binsize, so the bins reveal nothing but binsize.
+type bins struct {
+	first   []int
+	second  [][]int
+	binsize int
+}
+
+// maxRehash bounds how often hashbins retries the cuckoo hashing, each time
+// with a quarter more bins.
+const maxRehash = 8
+
+// hashbins returns no bins when either set is empty.
+func hashbins(ids0, ids1 []*big.Int) (*bins, error) {
+	b := new(bins)
+	if len(ids0) == 0 || len(ids1) == 0 {
+		return b, nil
+	}
+	cht := hashtable.NewCuckooHashTable(len(ids0))
+	for rehash := 0; !cht.Insert(ids0); rehash++ {
+		if rehash == maxRehash {
+			return nil, ErrHashing
+		}
+		_, binnum := cht.GetSize()
+		cht = hashtable.NewCuckooHashTableWithBins(len(ids0), binnum+binnum/4+1)
+	}
+	_, binnum := cht.GetSize()
+	index0 := make(map[string]int, len(ids0))
+	for q, id := range ids0 {
+		index0[id.String()] = q
+	}
+	b.first = make([]int, binnum)
+	for k, id := range cht.Table() {
+		b.first[k] = -1
+		if id != nil {
+			b.first[k] = index0[id.String()]
+		}
+	}
+	sh := hashtable.NewSimpleHashWithBuckets(binnum, 0, 3)
+	index1 := make(map[string]int, len(ids1))
+	for q, id := range ids1 {
+		index1[id.String()] = q
+		sh.Insert(id)
+	}
+	b.binsize = sh.MaxLoad()
+	b.second = make([][]int, binnum)
+	for k, bucket := range sh.Elements {
+		b.second[k] = make([]int, b.binsize)
+		for t := range b.second[k] {
+			b.second[k][t] = -1
+			if t < len(bucket) {
+				b.second[k][t] = index1[bucket[t].String()]
+			}
+		}
+	}
+	return b, nil
+}
+
+// dummy returns the shares filling an empty slot: a random hashed id and a
+// random verification result, which is never the identity, so a dummy never
+// matches.
+func (system *PIISystem) dummy() (*[]mpc.Share_Fp, *[]mpc.Share_GT) {
+	hid, _ := curve.RandomK(rand.Reader)
+	return system.PiiSystem.System.Share_An_Fp_Offline(hid), system.PiiSystem.System.RandomShareGT()
+}
+
+// binversets lays the verified inputs out bin by bin: bin k of party 0 is
+// element k of the first set, its slots of party 1 are elements
+// k*binsize .. (k+1)*binsize-1 of the second.
+func (system *PIISystem) binversets(versets []VerSet, b *bins) []VerSet {
+	var wg sync.WaitGroup
+	binnum := len(b.first)
+	binsets := make([]VerSet, 2)
+	binsets[0].Vers = make([](*[]mpc.Share_GT), binnum)
+	binsets[0].HIDs = make([](*[]mpc.Share_Fp), binnum)
+	binsets[0].Partyindex = 0
+	binsets[0].inputsize = binnum
+	binsets[1].Vers = make([](*[]mpc.Share_GT), binnum*b.binsize)
+	binsets[1].HIDs = make([](*[]mpc.Share_Fp), binnum*b.binsize)
+	binsets[1].Partyindex = 1
+	binsets[1].inputsize = binnum * b.binsize
+	for k := 0; k < binnum; k++ {
+		wg.Add(1)
+		go func(k int) {
+			defer wg.Done()
+			if q := b.first[k]; q >= 0 {
+				binsets[0].HIDs[k], binsets[0].Vers[k] = versets[0].HIDs[q], versets[0].Vers[q]
+			} else {
+				binsets[0].HIDs[k], binsets[0].Vers[k] = system.dummy()
+			}
+			for t, q := range b.second[k] {
+				if q >= 0 {
+					binsets[1].HIDs[k*b.binsize+t], binsets[1].Vers[k*b.binsize+t] = versets[1].HIDs[q], versets[1].Vers[q]
+				} else {
+					binsets[1].HIDs[k*b.binsize+t], binsets[1].Vers[k*b.binsize+t] = system.dummy()
+				}
+			}
+		}(k)
+	}
+	wg.Wait()
+	return binsets
+}
+
+// interphase_b compares each bin of party 0 with the binsize slots of party 1
+// in the same bin only; seedsets[k].Seeds[t] blinds slot t of bin k. It
+// returns the elements of party 0 that match.
+func (system *PIISystem) interphase_b(binsets []VerSet, b *bins, seedsets []SeedSet) ([]int, error) {
+	binsize := b.binsize
+	matches := make([]int, 0)
+	ctx, cancel := context.WithCancelCause(system.PiiSystem.System.Context())
+	defer cancel(nil)
+	interChan := make(chan int, 100)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	go func() {
+		for i := range interChan {
+			matches = append(matches, i)
+		}
+		close(done)
+	}()
+	batch := system.PiiSystem.System.NewOpenBatch()
+	for k := 0; k < binsets[0].inputsize; k++ {
+		wg.Add(1)
+		go func(k int) {
+			defer wg.Done()
+			defer mpc.Recover(cancel)
+			for t := 0; t < binsize; t++ {
+				if ctx.Err() != nil {
+					return
+				}
+				j := k*binsize + t
+				v := system.PiiSystem.System.SecSub(*binsets[0].HIDs[k], *binsets[1].HIDs[j])
+				w := system.PiiSystem.System.EXP_P_GT_1(system.MK.G, v)
+				w = system.PiiSystem.System.SecAdd_GT(*w, *binsets[0].Vers[k])
+				w = system.PiiSystem.System.SecAdd_GT(*w, *binsets[1].Vers[j])
+				w = system.PiiSystem.System.EXP_S_GT(*w, *seedsets[k].Seeds[t])
+				wvalue := batch.At(0, b.first[k]).OpenGT(*w)
+				if bytes.Equal(wvalue.Marshal(), system.PiiSystem.IdentityGTbytes) {
+					interChan <- b.first[k]
+				}
+			}
+		}(k)
+	}
+	wg.Wait()
+	close(interChan)
+	<-done
+	if ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+	if !system.PiiSystem.System.DeferredMacCheck(batch) {
+		return nil, batch.Err("interphase")
+	}
+	return matches, nil
+}
+
+func (system *PIISystem) bucketPiiRun(inputsets []InputSet, b *bins, seedsets []SeedSet) (*Result, error) {
+	result := new(Result)
+	vertime := time.Now()
+	versets, err := system.verphase(inputsets)
+	if err != nil {
+		return nil, err
+	}
+	result.VerTime = time.Since(vertime)
+	intertime := time.Now()
+	binsets := system.binversets(versets, b)
+	matches, err := system.interphase_b(binsets, b, seedsets)
+	if err != nil {
+		return nil, err
+	}
+	result.Intersection, err = system.openids(versets[0].HIDs, matches)
+	if err != nil {
+		return nil, err
+	}
+	result.InterTime = time.Since(intertime)
+	result.OfflineCom, result.OnlineCom = system.GetCommunication()
+	return result, nil
+}
+
+// PrepareData_b prepares the inputs of the bucketed two-party mode together
+// with one seed per slot of the bins they are hashed into.
+func (system *PIISystem) PrepareData_b(intersize int, inputsize []int) ([]InputSet, []SeedSet, error) {
+	idsets := system.prepareid(intersize, inputsize)
+	b, err := hashbins(idsets[0].IDs, idsets[1].IDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	seedsets := system.prepareseeds([]int{len(b.first), b.binsize})
+	privatesets := system.prepareinput(idsets)
+	return privatesets, seedsets, nil
+}
+
+// Run_b runs the bucketed two-party mode: the ids are hashed into bins and
+// only elements in the same bin are compared, about 1.27*n*binsize
+// comparisons instead of n*m. seedset may be nil, as for Run.
+func (system *PIISystem) Run_b(inputsets []InputSet, seedset []SeedSet) (*Result, error) {
+	return system.run_b(inputsets, seedset)
+}
+
+func (system *PIISystem) run_b(inputsets []InputSet, seedset []SeedSet) (*Result, error) {
+	if inputsets[0].inputsize == 0 || inputsets[1].inputsize == 0 {
+		return &Result{Intersection: []*big.Int{}}, nil
+	}
+	b, err := hashbins(inputsets[0].ids, inputsets[1].ids)
+	if err != nil {
+		return nil, err
+	}
+	slots := len(b.first) * b.binsize
+	if pool := system.PiiSystem.System.Pool; pool != nil {
+		randoms := slots
+		if seedset != nil {
+			randoms = 0
+		}
+		if err := pool.Require(slots, 0, randoms); err != nil {
+			return nil, err
+		}
+	}
+	if seedset == nil {
+		seedset = system.prepareseeds([]int{len(b.first), b.binsize})
+	}
+	return system.pooled(system.bucketPiiRun(inputsets, b, seedset))
+}
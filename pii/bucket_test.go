@@ -0,0 +1,71 @@
+package pii
+
+import (
+	"math/big"
+	"testing"
+
+	ibs "github.com/Oryx/IBS"
+)
+
+func TestHashbinsEmpty(t *testing.T) {
+	ids := []*big.Int{big.NewInt(1), big.NewInt(2)}
+	for _, sets := range [][2][]*big.Int{{nil, ids}, {ids, nil}, {nil, nil}} {
+		b, err := hashbins(sets[0], sets[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(b.first) != 0 || len(b.second) != 0 {
+			t.Fatalf("got %d and %d bins for an empty set", len(b.first), len(b.second))
+		}
+	}
+}
+
+func TestHashbins(t *testing.T) {
+	var ids0, ids1 []*big.Int
+	for v := int64(0); v < 300; v++ {
+		ids0 = append(ids0, big.NewInt(v))
+		ids1 = append(ids1, big.NewInt(v+150))
+	}
+	b, err := hashbins(ids0, ids1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shared := 0
+	for k, q := range b.first {
+		for _, r := range b.second[k] {
+			if q >= 0 && r >= 0 && ids0[q].Cmp(ids1[r]) == 0 {
+				shared++
+			}
+		}
+	}
+	if shared != 150 {
+		t.Fatalf("%d common ids share a bin, want 150", shared)
+	}
+}
+
+func TestIntersectBucketEmpty(t *testing.T) {
+	mk := ibs.MasterKeyGen()
+	system, err := NewPIISystem(2, &mk.MasterPubKey, false, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := big.NewInt(7)
+	uk := ibs.UserKeyGen(mk, id)
+	full, err := system.ShareInput(0, []Identity{{ID: id, Sig: ibs.Sign(uk, &mk.MasterPubKey, id.Bytes())}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	empty, err := system.ShareInput(1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sets := range [][]InputSet{{full, empty}, {empty, full}} {
+		result, err := system.Intersect(sets, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Intersection) != 0 {
+			t.Fatalf("got %d identities from an empty set", len(result.Intersection))
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package pii
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Oryx/mpc"
+)
+
+// interphase_ca computes the blinded comparison result of every pair as in
+// interphase, shuffles the results and opens them, so only the number of
+// matches is learnt.
+func (system *PIISystem) interphase_ca(versets []VerSet, seedsets []SeedSet) (int, error) {
+	ctx, cancel := context.WithCancelCause(system.PiiSystem.System.Context())
+	defer cancel(nil)
+	var wg sync.WaitGroup
+	m := versets[1].inputsize
+	ws := make([](*[]mpc.Share_GT), versets[0].inputsize*m)
+	for i := 0; i < versets[0].inputsize; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer mpc.Recover(cancel)
+			for j := 0; j < m; j++ {
+				if ctx.Err() != nil {
+					return
+				}
+				v := system.PiiSystem.System.SecSub(*versets[0].HIDs[i], *versets[1].HIDs[j])
+				w := system.PiiSystem.System.EXP_P_GT_1(system.MK.G, v)
+				w = system.PiiSystem.System.SecAdd_GT(*w, *versets[0].Vers[i])
+				w = system.PiiSystem.System.SecAdd_GT(*w, *versets[1].Vers[j])
+				ws[i*m+j] = system.PiiSystem.System.EXP_S_GT(*w, *seedsets[i].Seeds[j])
+			}
+		}(i)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return 0, context.Cause(ctx)
+	}
+	ws = system.PiiSystem.System.SecShuffle_GT(ws)
+	batch := system.PiiSystem.System.NewOpenBatch()
+	cardinality := 0
+	for _, w := range ws {
+		wvalue := batch.OpenGT(*w)
+		if bytes.Equal(wvalue.Marshal(), system.PiiSystem.IdentityGTbytes) {
+			cardinality++
+		}
+	}
+	if !system.PiiSystem.System.DeferredMacCheck(batch) {
+		return 0, batch.Err("interphase")
+	}
+	return cardinality, nil
+}
+
+func (system *PIISystem) cardinalityPiiRun(inputsets []InputSet, seedsets []SeedSet) (*Result, error) {
+	result := new(Result)
+	vertime := time.Now()
+	versets, err := system.verphase(inputsets)
+	if err != nil {
+		return nil, err
+	}
+	result.VerTime = time.Since(vertime)
+	intertime := time.Now()
+	result.Cardinality, err = system.interphase_ca(versets, seedsets)
+	if err != nil {
+		return nil, err
+	}
+	result.InterTime = time.Since(intertime)
+	result.OfflineCom, result.OnlineCom = system.GetCommunication()
+	return result, nil
+}
+
+// Run_ca runs the two-party cardinality mode on data from PrepareData;
+// Result.Cardinality holds the size of the intersection. seedset may be nil,
+// as for Run.
+func (system *PIISystem) Run_ca(inputsets []InputSet, seedset []SeedSet) (*Result, error) {
+	return system.run_ca(inputsets, seedset)
+}
+
+func (system *PIISystem) run_ca(inputsets []InputSet, seedset []SeedSet) (*Result, error) {
+	inputsize := inputsizes(inputsets)
+	if pool := system.PiiSystem.System.Pool; pool != nil {
+		triplets, randoms := poolneeds(inputsize, true)
+		if seedset != nil {
+			randoms = 0
+		}
+		if err := pool.Require(triplets, 0, randoms); err != nil {
+			return nil, err
+		}
+	}
+	if seedset == nil {
+		seedset = system.prepareseeds(inputsize)
+	}
+	return system.pooled(system.cardinalityPiiRun(inputsets, seedset))
+}
+
+// Cardinality runs PII on the input sets of two parties and returns only the
+// number of identities they share; Result.Intersection stays empty. The
+// comparison results are shuffled before they are opened, so neither the
+// matching positions nor the identities are revealed to a party that does not
+// collude with the dealer, which picks the shuffle.
+func (system *PIISystem) Cardinality(inputsets []InputSet) (*Result, error) {
+	if len(inputsets) != system.partynum || system.partynum != 2 {
+		return nil, ErrParties
+	}
+	return system.run_ca(inputsets, nil)
+}
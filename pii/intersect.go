@@ -0,0 +1,162 @@
+package pii
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	ibs "github.com/Oryx/IBS"
+)
+
+var (
+	ErrIdentity  = errors.New("pii: identity without id or signature")
+	ErrParties   = errors.New("pii: need one input set per party")
+	ErrDuplicate = errors.New("pii: duplicate identity in an input set")
+	ErrMode      = errors.New("pii: mode not supported for this number of parties")
+)
+
+// Identity is an identity together with its signature on the bytes of ID,
+// made with the user key of ID.
+type Identity struct {
+	ID  *big.Int
+	Sig *ibs.Sig
+}
+
+// Result is the outcome of one run. OfflineCom and OnlineCom are the
+// communication of the system so far in MB, including the input sharing.
+// Cardinality is only set by the cardinality mode, Sums and Payloads only by
+// the sum mode.
+type Result struct {
+	Intersection []*big.Int
+	Cardinality  int
+	Sums         []*big.Int
+	Payloads     [][]*big.Int
+	VerTime      time.Duration
+	InterTime    time.Duration
+	OfflineCom   float64
+	OnlineCom    float64
+}
+
+func (result *Result) print() {
+	fmt.Println("ver time:", result.VerTime)
+	fmt.Println("inter time:", result.InterTime)
+	fmt.Printf("Offline Communication: %f MB\n", result.OfflineCom)
+	fmt.Printf("Online Communication: %f MB\n", result.OnlineCom)
+}
+
+// NewPIISystem sets up PII for identities whose user keys were issued under
+// mpk. Unlike PiiInitSystem it does not hold the master secret key, so
+// PrepareData cannot be used with it.
+func NewPIISystem(Partynum int, mpk *ibs.MasterPubKey, isWAN bool, bandwidth float64) (*PIISystem, error) {
+	securever, err := ibs.SecureVerInit(Partynum, mpk, true, isWAN, bandwidth)
+	if err != nil {
+		return nil, err
+	}
+	piisystem := new(PIISystem)
+	piisystem.PiiSystem = *securever
+	if err := piisystem.PiiSystem.System.SetupPRSS(); err != nil {
+		return nil, err
+	}
+	piisystem.partynum = Partynum
+	piisystem.MK = &ibs.MasterKey{MasterPubKey: *mpk}
+	return piisystem, nil
+}
+
+// EnableIdentifiableAbort must be called before the inputs are shared. A
+// failed run then returns a *mpc.MacCheckError whose Cheater is the party
+// that deviated.
+func (system *PIISystem) EnableIdentifiableAbort() {
+	system.PiiSystem.EnableIdentifiableAbort()
+}
+
+// SetupOfflinePhase makes the system take its triples, and the MACs of its
+// seeds and inputs, from the offline phase of mpc instead of the dealer. bits
+// is the Paillier modulus size, e.g. 2048. Call it before the inputs are
+// shared.
+func (system *PIISystem) SetupOfflinePhase(bits int) error {
+	return system.PiiSystem.System.SetupOfflinePhase(bits)
+}
+
+// ShareInput secret-shares the identities of one party. The signatures are
+// verified inside the protocol, so an identity with an invalid signature is
+// never part of the intersection. Party partyindex commits to its inputs and
+// every party checks its shares against the commitments before they enter the
+// input set; if a check fails, the *mpc.InputProofError is returned. An ID
+// may appear only once; otherwise ErrDuplicate is returned.
+func (system *PIISystem) ShareInput(partyindex int, identities []Identity) (InputSet, error) {
+	seen := make(map[string]bool, len(identities))
+	for _, identity := range identities {
+		if identity.ID == nil || identity.Sig == nil {
+			return InputSet{}, ErrIdentity
+		}
+		if seen[identity.ID.String()] {
+			return InputSet{}, ErrDuplicate
+		}
+		seen[identity.ID.String()] = true
+	}
+	var inputset InputSet
+	inputset.Sigs = make([]*ibs.Share_Sig, len(identities))
+	inputset.ids = make([]*big.Int, len(identities))
+	for q, identity := range identities {
+		sig, err := system.PiiSystem.Share_A_Sig_Owner(partyindex, *identity.Sig, identity.ID.Bytes(), identity.ID)
+		if err != nil {
+			return InputSet{}, err
+		}
+		inputset.Sigs[q] = sig
+		inputset.ids[q] = identity.ID
+	}
+	inputset.Partyindex = partyindex
+	inputset.inputsize = len(identities)
+	return inputset, nil
+}
+
+// Intersect runs PII on the input sets of all parties, in party order, and
+// returns the identities of party 0 that are in the intersection. For input
+// sets that were not made with ShareInput the intersection holds the hashed
+// identities instead. For two parties, mode 0 compares all pairs, mode 2 only
+// those in the same hash bin, mode 3 returns only the size of the
+// intersection as Cardinality does and mode 4 only the sums of the payloads
+// as IntersectSum does with hideids. Mode 1 runs the multi-party protocol for
+// any number of parties. Any other mode or number of parties returns ErrMode.
+func (system *PIISystem) Intersect(inputsets []InputSet, mode int) (*Result, error) {
+	if len(inputsets) != system.partynum {
+		return nil, ErrParties
+	}
+	var result *Result
+	var err error
+	switch {
+	case mode == 0 && system.partynum == 2:
+		result, err = system.run(inputsets, nil)
+	case mode == 1:
+		result, err = system.run_m(inputsets, nil)
+	case mode == 2 && system.partynum == 2:
+		result, err = system.run_b(inputsets, nil)
+	case mode == 3 && system.partynum == 2:
+		return system.run_ca(inputsets, nil)
+	case mode == 4 && system.partynum == 2:
+		return system.run_s(inputsets, true)
+	default:
+		return nil, ErrMode
+	}
+	if err != nil {
+		return nil, err
+	}
+	system.lookupids(inputsets, result)
+	return result, nil
+}
+
+// lookupids replaces the hashed identities in result.Intersection with the
+// identities of party 0, when its input set was made with ShareInput.
+func (system *PIISystem) lookupids(inputsets []InputSet, result *Result) {
+	if inputsets[0].ids == nil {
+		return
+	}
+	ids := make(map[string]*big.Int, len(inputsets[0].ids))
+	for _, id := range inputsets[0].ids {
+		ids[ibs.H2(id).String()] = id
+	}
+	for k, hid := range result.Intersection {
+		result.Intersection[k] = ids[hid.String()]
+	}
+}
@@ -1,23 +1,24 @@
 package pii
 
 import (
-	"bytes"
-	"fmt"
+	"context"
 	"math/big"
-	"os"
 	"sync"
 	"time"
 
 	"github.com/Oryx/mpc"
 )
 
-func (system *PIISystem) verphase(inputsets []InputSet) []VerSet {
+func (system *PIISystem) verphase(inputsets []InputSet) ([]VerSet, error) {
 	versets := make([]VerSet, system.partynum)
+	ctx, cancel := context.WithCancelCause(system.PiiSystem.System.Context())
+	defer cancel(nil)
 	var wg sync.WaitGroup
 	blocknum := 4096
 	for i := 0; i < system.partynum; i++ {
 		versets[i].Vers = make([](*[]mpc.Share_GT), inputsets[i].inputsize)
 		versets[i].HIDs = make([](*[]mpc.Share_Fp), inputsets[i].inputsize)
+		versets[i].Payloads = inputsets[i].Payloads
 		versets[i].inputsize = inputsets[i].inputsize
 		eachblock := inputsets[i].inputsize/blocknum + 1
 		for j := 0; ; j = j + eachblock {
@@ -25,7 +26,11 @@ func (system *PIISystem) verphase(inputsets []InputSet) []VerSet {
 				wg.Add(1)
 				go func(i, j int) {
 					defer wg.Done()
+					defer mpc.Recover(cancel)
 					for q := j; q < inputsets[i].inputsize; q++ {
+						if ctx.Err() != nil {
+							return
+						}
 						versets[i].Vers[q] = system.PiiSystem.SecVerWithoutOpen(inputsets[i].Sigs[q])
 						//fmt.Println(system.PiiSystem.System.HalfOpenGT(*versets[i].Vers[q]))
 						versets[i].HIDs[q] = inputsets[i].Sigs[q].HID
@@ -36,7 +41,11 @@ func (system *PIISystem) verphase(inputsets []InputSet) []VerSet {
 				wg.Add(1)
 				go func(i, j int) {
 					defer wg.Done()
+					defer mpc.Recover(cancel)
 					for q := j; q < j+eachblock; q++ {
+						if ctx.Err() != nil {
+							return
+						}
 						versets[i].Vers[q] = system.PiiSystem.SecVerWithoutOpen(inputsets[i].Sigs[q])
 						//fmt.Println(system.PiiSystem.System.HalfOpenGT(*versets[i].Vers[q]))
 						versets[i].HIDs[q] = inputsets[i].Sigs[q].HID
@@ -47,94 +56,57 @@ func (system *PIISystem) verphase(inputsets []InputSet) []VerSet {
 		}
 	}
 	wg.Wait()
-	return versets
+	if ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+	return versets, nil
 }
 
-func (system *PIISystem) interphase(versets []VerSet, seedsets []SeedSet) []*big.Int {
-	intersection := make([]*big.Int, 0)
-	interChan := make(chan *big.Int, 100)
-	var wg sync.WaitGroup
-	go func() {
-		for inter := range interChan {
-			intersection = append(intersection, inter)
-		}
-	}()
-	if versets[0].inputsize*versets[1].inputsize <= 16384 {
-		for i := 0; i < versets[0].inputsize; i++ {
-			for j := 0; j < versets[1].inputsize; j++ {
-				wg.Add(1)
-				go func(i, j int) {
-					defer wg.Done()
-					v := system.PiiSystem.System.SecSub(*versets[0].HIDs[i], *versets[1].HIDs[j])
-					w := system.PiiSystem.System.EXP_P_GT_1(system.MK.G, v)
-					w = system.PiiSystem.System.SecAdd_GT(*w, *versets[0].Vers[i])
-					w = system.PiiSystem.System.SecAdd_GT(*w, *versets[1].Vers[j])
-					w = system.PiiSystem.System.EXP_S_GT(*w, *seedsets[i].Seeds[j])
-					wvalue, chk := system.PiiSystem.System.OpenGT(*w)
-					if chk {
-						if bytes.Equal(wvalue.Marshal(), system.PiiSystem.IdentityGTbytes) {
-							interid, chkid := system.PiiSystem.System.OpenFp(*versets[0].HIDs[i])
-							if chkid {
-								interChan <- interid
-							} else {
-								fmt.Println("chk error")
-								os.Exit(1)
-							}
-						}
-					} else {
-						fmt.Println("chk error")
-						os.Exit(1)
-					}
-				}(i, j)
+// interphase runs Interphase on mpc.
+func (system *PIISystem) interphase(versets []VerSet, seedsets []SeedSet) ([]*big.Int, error) {
+	seeds := make([][]*[]mpc.Share_Fp, len(seedsets))
+	for i := range seedsets {
+		seeds[i] = seedsets[i].Seeds
+	}
+	return Interphase(system.backend(), system.MK.G, system.PiiSystem.IdentityGTbytes, verified(versets), seeds)
+}
 
-			}
-		}
-	} else {
-		for i := 0; i < versets[0].inputsize; i++ {
-			wg.Add(1)
-			go func(i int) {
-				defer wg.Done()
-				for j := 0; j < versets[1].inputsize; j++ {
-					v := system.PiiSystem.System.SecSub(*versets[0].HIDs[i], *versets[1].HIDs[j])
-					w := system.PiiSystem.System.EXP_P_GT_1(system.MK.G, v)
-					w = system.PiiSystem.System.SecAdd_GT(*w, *versets[0].Vers[i])
-					w = system.PiiSystem.System.SecAdd_GT(*w, *versets[1].Vers[j])
-					w = system.PiiSystem.System.EXP_S_GT(*w, *seedsets[i].Seeds[j])
-					wvalue, chk := system.PiiSystem.System.OpenGT(*w)
-					if chk {
-						if bytes.Equal(wvalue.Marshal(), system.PiiSystem.IdentityGTbytes) {
-							interid, chkid := system.PiiSystem.System.OpenFp(*versets[0].HIDs[i])
-							if chkid {
-								interChan <- interid
-							} else {
-								fmt.Println("chk error")
-								os.Exit(1)
-							}
-						}
-					} else {
-						fmt.Println("chk error")
-						os.Exit(1)
-					}
-				}
-			}(i)
-		}
+// openids opens the identities of party 0 at the matching positions as
+// Interphase does.
+func (system *PIISystem) openids(hids [](*[]mpc.Share_Fp), matches []int) ([]*big.Int, error) {
+	return openhids[mpc.Share_Fp, mpc.Share_GT](system.backend(), hids, matches)
+}
+
+// backend returns the system the comparisons run on.
+func (system *PIISystem) backend() Backend[mpc.Share_Fp, mpc.Share_GT] {
+	return mpcBackend{&system.PiiSystem.System}
+}
+
+// verified returns the results of the verification phase as Interphase
+// takes them.
+func verified(versets []VerSet) []Verified[mpc.Share_Fp, mpc.Share_GT] {
+	vers := make([]Verified[mpc.Share_Fp, mpc.Share_GT], len(versets))
+	for i := range versets {
+		vers[i].Vers = versets[i].Vers
+		vers[i].HIDs = versets[i].HIDs
 	}
-	wg.Wait()
-	close(interChan) // Close the channel after all goroutines are done
-	return intersection
+	return vers
 }
 
-func (system *PIISystem) twoPartyPiiRun(inputsets []InputSet, seedsets []SeedSet) []*big.Int {
-	fmt.Println("ver phase start")
+func (system *PIISystem) twoPartyPiiRun(inputsets []InputSet, seedsets []SeedSet) (*Result, error) {
+	result := new(Result)
 	vertime := time.Now()
-	versets := system.verphase(inputsets)
-	fmt.Println("ver time:", time.Since(vertime))
-	fmt.Println("inter phase start")
+	versets, err := system.verphase(inputsets)
+	if err != nil {
+		return nil, err
+	}
+	result.VerTime = time.Since(vertime)
 	intertime := time.Now()
-	intersection := system.interphase(versets, seedsets)
-	fmt.Println("inter time:", time.Since(intertime))
-	//fmt.Println("intersection:", intersection)
-	fmt.Printf("Offline Communication: %f MB\n", float64(system.PiiSystem.System.OfflineCom)/1024/1024)
-	fmt.Printf("Online Communication: %f MB\n", float64(system.PiiSystem.System.Com)/1024/1024)
-	return intersection
+	result.Intersection, err = system.interphase(versets, seedsets)
+	if err != nil {
+		return nil, err
+	}
+	result.InterTime = time.Since(intertime)
+	result.OfflineCom, result.OnlineCom = system.GetCommunication()
+	return result, nil
 }
@@ -0,0 +1,271 @@
+package pii
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/Oryx/mpc"
+)
+
+var ErrPayloads = errors.New("pii: need one payload per identity")
+
+// maxPayload bounds the payloads made by PrepareData_s.
+var maxPayload = big.NewInt(1000)
+
+// ShareInputWithPayloads is ShareInput for identities that each carry a
+// value, e.g. the spend of a customer. The values are secret-shared along
+// with the identities and are only ever opened summed over the intersection,
+// or per identity by IntersectSum when the identities are not hidden.
+func (system *PIISystem) ShareInputWithPayloads(partyindex int, identities []Identity, payloads []*big.Int) (InputSet, error) {
+	if len(payloads) != len(identities) {
+		return InputSet{}, ErrPayloads
+	}
+	inputset, err := system.ShareInput(partyindex, identities)
+	if err != nil {
+		return InputSet{}, err
+	}
+	inputset.Payloads, err = system.sharepayloads_owner(partyindex, payloads)
+	if err != nil {
+		return InputSet{}, err
+	}
+	return inputset, nil
+}
+
+func (system *PIISystem) sharepayloads(payloads []*big.Int) [](*[]mpc.Share_Fp) {
+	var wg sync.WaitGroup
+	shares := make([](*[]mpc.Share_Fp), len(payloads))
+	for q := range payloads {
+		wg.Add(1)
+		go func(q int) {
+			defer wg.Done()
+			shares[q] = system.PiiSystem.System.Share_An_Fp(new(big.Int).Mod(payloads[q], system.PiiSystem.System.Order))
+		}(q)
+	}
+	wg.Wait()
+	return shares
+}
+
+// sharepayloads_owner shares the payloads of party partyindex with
+// Share_An_Fp_Proven, as ShareInput does for the identities.
+func (system *PIISystem) sharepayloads_owner(partyindex int, payloads []*big.Int) ([](*[]mpc.Share_Fp), error) {
+	var wg sync.WaitGroup
+	shares := make([](*[]mpc.Share_Fp), len(payloads))
+	errs := make([]error, len(payloads))
+	for q := range payloads {
+		wg.Add(1)
+		go func(q int) {
+			defer wg.Done()
+			shares[q], errs[q] = system.PiiSystem.System.Share_An_Fp_Proven(partyindex, payloads[q])
+		}(q)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return shares, nil
+}
+
+// interphase_s gives every element with a valid signature a shared bit
+// telling whether the other party holds it: as in interphase_t, the product
+// of its differences to the elements of the other party is tested with
+// SecEqZero. The payloads are multiplied with their bits and summed, and only
+// the sums are opened. Without hideids the bits are opened as well, and once
+// they passed their MAC check, the matching identities and their payloads.
+func (system *PIISystem) interphase_s(versets []VerSet, hideids bool) ([]*big.Int, [][]*big.Int, []*big.Int, error) {
+	verres, err := system.openvers(versets)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	ctx, cancel := context.WithCancelCause(system.PiiSystem.System.Context())
+	defer cancel(nil)
+	var wg sync.WaitGroup
+	bits := make([][](*[]mpc.Share_Fp), 2)
+	for p := 0; p < 2; p++ {
+		bits[p] = make([](*[]mpc.Share_Fp), versets[p].inputsize)
+		for q := 0; q < versets[p].inputsize; q++ {
+			if !verres[p][q] {
+				continue
+			}
+			wg.Add(1)
+			go func(p, q int) {
+				defer wg.Done()
+				defer mpc.Recover(cancel)
+				var v *[]mpc.Share_Fp
+				for j := 0; j < versets[1-p].inputsize; j++ {
+					if ctx.Err() != nil {
+						return
+					}
+					if !verres[1-p][j] {
+						continue
+					}
+					d := system.PiiSystem.System.SecSub(*versets[p].HIDs[q], *versets[1-p].HIDs[j])
+					if v == nil {
+						v = d
+					} else {
+						v = system.PiiSystem.System.SecMul(*v, *d)
+					}
+				}
+				if v != nil {
+					bits[p][q] = system.PiiSystem.System.SecEqZero(*v)
+				}
+			}(p, q)
+		}
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return nil, nil, nil, context.Cause(ctx)
+	}
+	batch := system.PiiSystem.System.NewOpenBatch()
+	sums := make([]*big.Int, 2)
+	for p := 0; p < 2; p++ {
+		if versets[p].Payloads == nil {
+			continue
+		}
+		sum := system.PiiSystem.System.Share_An_Fp_Offline(big.NewInt(0))
+		for q, bit := range bits[p] {
+			if bit != nil {
+				sum = system.PiiSystem.System.SecAdd(*sum, *system.PiiSystem.System.SecMul(*bit, *versets[p].Payloads[q]))
+			}
+		}
+		sums[p] = batch.OpenFp(*sum)
+	}
+	held := make([][]int, 2)
+	if !hideids {
+		for p := 0; p < 2; p++ {
+			for q, bit := range bits[p] {
+				if bit != nil && batch.At(p, q).OpenFp(*bit).Sign() != 0 {
+					held[p] = append(held[p], q)
+				}
+			}
+		}
+	}
+	if !system.PiiSystem.System.DeferredMacCheck(batch) {
+		return nil, nil, nil, batch.Err("interphase")
+	}
+	if hideids {
+		return nil, nil, sums, nil
+	}
+	intersection, payloads, err := system.openmatches(versets, held)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return intersection, payloads, sums, nil
+}
+
+// openmatches opens the identities that held[p] lists for party p, all of
+// them in the intersection, with their payloads, and pairs them up by
+// identity.
+func (system *PIISystem) openmatches(versets []VerSet, held [][]int) ([]*big.Int, [][]*big.Int, error) {
+	batch := system.PiiSystem.System.NewOpenBatch()
+	ids := make([][]*big.Int, 2)
+	pays := make([][]*big.Int, 2)
+	for p := 0; p < 2; p++ {
+		ids[p] = make([]*big.Int, len(held[p]))
+		pays[p] = make([]*big.Int, len(held[p]))
+		for k, q := range held[p] {
+			ids[p][k] = batch.At(p, q).OpenFp(*versets[p].HIDs[q])
+			if versets[p].Payloads != nil {
+				pays[p][k] = batch.At(p, q).OpenFp(*versets[p].Payloads[q])
+			}
+		}
+	}
+	if !system.PiiSystem.System.DeferredMacCheck(batch) {
+		return nil, nil, batch.Err("interphase")
+	}
+	second := make(map[string]*big.Int, len(held[1]))
+	for k, id := range ids[1] {
+		second[id.String()] = pays[1][k]
+	}
+	payloads := make([][]*big.Int, len(held[0]))
+	for k, id := range ids[0] {
+		payloads[k] = []*big.Int{pays[0][k], second[id.String()]}
+	}
+	return ids[0], payloads, nil
+}
+
+// poolneeds_s returns the triples and square pairs that interphase_s takes
+// from the pool when every signature is valid and both parties have payloads.
+func (system *PIISystem) poolneeds_s(inputsize []int) (int, int) {
+	eqtriplets, eqsquarepairs := system.PiiSystem.System.EqZeroCost()
+	triplets, squarepairs := 0, 0
+	for p := 0; p < 2; p++ {
+		if inputsize[1-p] == 0 {
+			continue
+		}
+		triplets += inputsize[p] * (inputsize[1-p] + eqtriplets)
+		squarepairs += inputsize[p] * eqsquarepairs
+	}
+	return triplets, squarepairs
+}
+
+func (system *PIISystem) sumPiiRun(inputsets []InputSet, hideids bool) (*Result, error) {
+	result := new(Result)
+	vertime := time.Now()
+	versets, err := system.verphase(inputsets)
+	if err != nil {
+		return nil, err
+	}
+	result.VerTime = time.Since(vertime)
+	intertime := time.Now()
+	result.Intersection, result.Payloads, result.Sums, err = system.interphase_s(versets, hideids)
+	if err != nil {
+		return nil, err
+	}
+	result.InterTime = time.Since(intertime)
+	result.OfflineCom, result.OnlineCom = system.GetCommunication()
+	return result, nil
+}
+
+// PrepareData_s is PrepareData with a random payload below 1000 for every
+// identity of every party. The sum mode needs no seeds.
+func (system *PIISystem) PrepareData_s(intersize int, inputsize []int) []InputSet {
+	privatesets := system.prepareinput(system.prepareid(intersize, inputsize))
+	for i := range privatesets {
+		payloads := make([]*big.Int, privatesets[i].inputsize)
+		for q := range payloads {
+			payloads[q], _ = rand.Int(rand.Reader, maxPayload)
+		}
+		privatesets[i].Payloads = system.sharepayloads(payloads)
+	}
+	return privatesets
+}
+
+// Run_s runs the two-party sum mode; Result.Sums holds the sums of the
+// payloads of each party over the intersection.
+func (system *PIISystem) Run_s(inputsets []InputSet) (*Result, error) {
+	return system.run_s(inputsets, true)
+}
+
+func (system *PIISystem) run_s(inputsets []InputSet, hideids bool) (*Result, error) {
+	if pool := system.PiiSystem.System.Pool; pool != nil {
+		triplets, squarepairs := system.poolneeds_s(inputsizes(inputsets))
+		if err := pool.Require(triplets, squarepairs, 0); err != nil {
+			return nil, err
+		}
+	}
+	return system.pooled(system.sumPiiRun(inputsets, hideids))
+}
+
+// IntersectSum runs PII on the input sets of two parties and returns in
+// Result.Sums, for each party with payloads, the sum of its payloads over the
+// intersection. With hideids only the sums are opened, and which signatures
+// are valid. Otherwise the identities of
+// party 0 in the intersection are returned as by Intersect, and
+// Result.Payloads holds the payloads of both parties for each of them.
+func (system *PIISystem) IntersectSum(inputsets []InputSet, hideids bool) (*Result, error) {
+	if len(inputsets) != system.partynum || system.partynum != 2 {
+		return nil, ErrParties
+	}
+	result, err := system.run_s(inputsets, hideids)
+	if err != nil {
+		return nil, err
+	}
+	system.lookupids(inputsets, result)
+	return result, nil
+}
@@ -15,7 +15,6 @@ type PIISystem struct {
 	PiiSystem ibs.SecureVer
 	partynum  int
 	MK        *ibs.MasterKey
-	maxID     *big.Int
 }
 
 type IDSet struct {
@@ -24,15 +23,20 @@ type IDSet struct {
 	inputsize  int
 }
 
+// InputSet holds the shared inputs of one party. Payloads is nil unless the
+// identities carry values for the sum mode.
 type InputSet struct {
 	Sigs       []*ibs.Share_Sig
+	Payloads   [](*[]mpc.Share_Fp)
 	Partyindex int
 	inputsize  int
+	ids        []*big.Int
 }
 
 type VerSet struct {
 	Vers       [](*[]mpc.Share_GT)
 	HIDs       [](*[]mpc.Share_Fp)
+	Payloads   [](*[]mpc.Share_Fp)
 	Partyindex int
 	inputsize  int
 }
@@ -47,95 +51,93 @@ type SeedSet struct {
 	Partyindex int
 }
 
-func (system *PIISystem) generateBigIntSlice(size, intersize int) []*big.Int {
-	slice := make([]*big.Int, size)
-	for i := intersize; i < size; i++ {
-		slice[i], _ = rand.Int(rand.Reader, system.maxID)
-	}
-	return slice
-}
-
-func PiiInitSystem(Partynum int, isWAN bool, bandwidth float64) *PIISystem {
+// PiiInitSystem returns mpc.ErrBandwidth for a WAN system without a positive
+// bandwidth.
+func PiiInitSystem(Partynum int, isWAN bool, bandwidth float64) (*PIISystem, error) {
 	Mk := ibs.MasterKeyGen()
 	piisystem := new(PIISystem)
-	piisystem.PiiSystem = *ibs.SecureVerInit(Partynum, &Mk.MasterPubKey, true, isWAN, bandwidth)
+	securever, err := ibs.SecureVerInit(Partynum, &Mk.MasterPubKey, true, isWAN, bandwidth)
+	if err != nil {
+		return nil, err
+	}
+	piisystem.PiiSystem = *securever
+	if err := piisystem.PiiSystem.System.SetupPRSS(); err != nil {
+		return nil, err
+	}
 	piisystem.partynum = Partynum
-	one := big.NewInt(1)
-	piisystem.maxID = new(big.Int).Lsh(one, 64)
 	piisystem.MK = Mk
-	return piisystem
+	return piisystem, nil
 }
 
 func (system *PIISystem) prepareid(intersize int, inputsize []int) []IDSet {
+	return RandomIDSets(intersize, inputsize)
+}
+
+// RandomIDSets returns random 64-bit identities for parties with inputsize
+// elements each, of which the first intersize are the same for all of them.
+func RandomIDSets(intersize int, inputsize []int) []IDSet {
 	var wg sync.WaitGroup
-	idsets := make([]IDSet, system.partynum)
-	for i := 0; i < system.partynum; i++ {
+	maxID := new(big.Int).Lsh(big.NewInt(1), 64)
+	idsets := make([]IDSet, len(inputsize))
+	for i := range idsets {
 		wg.Add(1)
 		go func(i int) {
 			defer wg.Done()
-			idsets[i].IDs = system.generateBigIntSlice(inputsize[i], intersize)
+			idsets[i].IDs = make([]*big.Int, inputsize[i])
+			for j := intersize; j < inputsize[i]; j++ {
+				idsets[i].IDs[j], _ = rand.Int(rand.Reader, maxID)
+			}
 			idsets[i].Partyindex = i
 			idsets[i].inputsize = inputsize[i]
 		}(i)
 	}
 	wg.Wait()
 	for j := 0; j < intersize; j++ {
-		wg.Add(1)
-		go func(j int) {
-			defer wg.Done()
-			commonElement, _ := rand.Int(rand.Reader, system.maxID)
-			for i := 0; i < system.partynum; i++ {
-				idsets[i].IDs[j] = commonElement
-			}
-		}(j)
+		commonElement, _ := rand.Int(rand.Reader, maxID)
+		for i := range idsets {
+			idsets[i].IDs[j] = commonElement
+		}
 	}
-	wg.Wait()
 	return idsets
 }
 
-func (system *PIISystem) prepareseeds(inputsize []int) []SeedSet {
-	var wg sync.WaitGroup
-	seedsets := make([]SeedSet, inputsize[0])
-	if inputsize[0]*inputsize[1] < 16384 {
-		for i := 0; i < inputsize[0]; i++ {
-			seedsets[i].Seeds = make([](*[]mpc.Share_Fp), inputsize[1])
-			for j := 0; j < inputsize[1]; j++ {
-				wg.Add(1)
-				go func(i, j int) {
-					defer wg.Done()
-					seedsets[i].Seeds[j] = system.PiiSystem.System.RandomShareFp()
-				}(i, j)
+// seeds takes n seeds from the attached pool, if any, and otherwise derives
+// the PRSS values with indices k to k+n-1, so that the dealer does not pick
+// them. It panics with the error of the pool or the PRSS MACs; the runs check
+// the pool before they prepare their seeds.
+func (system *PIISystem) seeds(k uint64, n int) []*[]mpc.Share_Fp {
+	if system.PiiSystem.System.Pool != nil {
+		shares := make([]*[]mpc.Share_Fp, n)
+		for i := range shares {
+			rshares, err := system.PiiSystem.System.RandomShareFp()
+			if err != nil {
+				panic(err)
 			}
+			shares[i] = rshares
 		}
-	} else {
-		for i := 0; i < inputsize[0]; i++ {
-			seedsets[i].Seeds = make([](*[]mpc.Share_Fp), inputsize[1])
-			wg.Add(1)
-			go func(i int) {
-				defer wg.Done()
-				for j := 0; j < inputsize[1]; j++ {
-					seedsets[i].Seeds[j] = system.PiiSystem.System.RandomShareFp()
-				}
-			}(i)
-		}
+		return shares
+	}
+	shares, err := system.PiiSystem.System.RandomSharesFp_PRSS(k, n)
+	if err != nil {
+		panic(err)
+	}
+	return shares
+}
+
+func (system *PIISystem) prepareseeds(inputsize []int) []SeedSet {
+	seedsets := make([]SeedSet, inputsize[0])
+	base := system.PiiSystem.System.ReservePRSS(inputsize[0] * inputsize[1])
+	seeds := system.seeds(base, inputsize[0]*inputsize[1])
+	for i := 0; i < inputsize[0]; i++ {
+		seedsets[i].Seeds = seeds[i*inputsize[1] : (i+1)*inputsize[1] : (i+1)*inputsize[1]]
 	}
-	wg.Wait()
 	return seedsets
 }
 
 func (system *PIISystem) prepareseeds_m(inputsize []int) *SeedSet {
-	var wg sync.WaitGroup
 	seedsets := new(SeedSet)
-	seedsets.Seeds = make([]*[]mpc.Share_Fp, inputsize[0])
-	//wg.Wait()
-	for i := 0; i < inputsize[0]; i++ {
-		wg.Add(1)
-		go func(i int) {
-			defer wg.Done()
-			seedsets.Seeds[i] = system.PiiSystem.System.RandomShareFp()
-		}(i)
-	}
-	wg.Wait()
+	base := system.PiiSystem.System.ReservePRSS(inputsize[0])
+	seedsets.Seeds = system.seeds(base, inputsize[0])
 	return seedsets
 }
 
@@ -146,6 +148,7 @@ func (system *PIISystem) prepareinput(idset []IDSet) []InputSet {
 	for i := 0; i < system.partynum; i++ {
 		sigsets[i].Sigs = make([]*ibs.Share_Sig, idset[i].inputsize)
 		sigsets[i].inputsize = idset[i].inputsize
+		sigsets[i].ids = idset[i].IDs
 		eachblock := idset[i].inputsize/blocknum + 1
 		for j := 0; ; j = j + eachblock {
 			if j+eachblock >= idset[i].inputsize {
@@ -195,20 +198,127 @@ func (system *PIISystem) PrepareData_m(intersize int, inputsize []int) ([]InputS
 	return privatesets, seedsets
 }
 
-func (system *PIISystem) Run(inputsets []InputSet, seedset []SeedSet) {
-	system.twoPartyPiiRun(inputsets, seedset)
+func inputsizes(inputsets []InputSet) []int {
+	inputsize := make([]int, len(inputsets))
+	for i := range inputsets {
+		inputsize[i] = inputsets[i].inputsize
+	}
+	return inputsize
 }
 
-func (system *PIISystem) Run_m(inputsets []InputSet, seedset *SeedSet) {
-	system.PartyPiiRun(inputsets, *seedset)
+// poolneeds returns the triples and random shares that Run (two-party mode)
+// or Run_m takes from the preprocessing pool for inputs of the given sizes.
+func poolneeds(inputsize []int, twoparty bool) (int, int) {
+	if twoparty {
+		return inputsize[0] * inputsize[1], inputsize[0] * inputsize[1]
+	}
+	muls := 1
+	for i := 1; i < len(inputsize); i++ {
+		muls = muls + inputsize[i] - 1
+	}
+	return inputsize[0] * muls, inputsize[0]
+}
+
+// PreparePool generates the triples and seeds for one run in bulk and
+// attaches them to the system. mode has the same meaning as in the protocol.
+// It returns the error of mpc.GenPool.
+func (system *PIISystem) PreparePool(inputsize []int, mode int) (*mpc.Pool, error) {
+	triplets, randoms := poolneeds(inputsize, mode == 0 && len(inputsize) == 2)
+	squarepairs := 0
+	if mode == 4 && len(inputsize) == 2 {
+		triplets, squarepairs = system.poolneeds_s(inputsize)
+		randoms = 0
+	}
+	pool, err := system.PiiSystem.System.GenPool(triplets, squarepairs, randoms)
+	if err != nil {
+		return nil, err
+	}
+	system.PiiSystem.System.UsePool(pool)
+	return pool, nil
+}
+
+// UsePool attaches a preprocessing pool, e.g. one read with mpc.LoadPool.
+func (system *PIISystem) UsePool(pool *mpc.Pool) {
+	system.PiiSystem.System.UsePool(pool)
+}
+
+// Run returns the result of one run without printing it. It takes its
+// triples from the attached pool, if any, and returns a
+// *mpc.PoolExhaustedError before starting when the pool is too small.
+// seedset may be nil; the seeds are then drawn from the pool, if any, or
+// generated.
+func (system *PIISystem) Run(inputsets []InputSet, seedset []SeedSet) (*Result, error) {
+	return system.run(inputsets, seedset)
+}
+
+func (system *PIISystem) Run_m(inputsets []InputSet, seedset *SeedSet) (*Result, error) {
+	return system.run_m(inputsets, seedset)
+}
+
+func (system *PIISystem) run(inputsets []InputSet, seedset []SeedSet) (*Result, error) {
+	inputsize := inputsizes(inputsets)
+	if pool := system.PiiSystem.System.Pool; pool != nil {
+		triplets, randoms := poolneeds(inputsize, true)
+		if seedset != nil {
+			randoms = 0
+		}
+		if err := pool.Require(triplets, 0, randoms); err != nil {
+			return nil, err
+		}
+	}
+	if seedset == nil {
+		seedset = system.prepareseeds(inputsize)
+	}
+	return system.pooled(system.twoPartyPiiRun(inputsets, seedset))
+}
+
+func (system *PIISystem) run_m(inputsets []InputSet, seedset *SeedSet) (*Result, error) {
+	inputsize := inputsizes(inputsets)
+	if pool := system.PiiSystem.System.Pool; pool != nil {
+		triplets, randoms := poolneeds(inputsize, false)
+		if seedset != nil {
+			randoms = 0
+		}
+		if err := pool.Require(triplets, 0, randoms); err != nil {
+			return nil, err
+		}
+	}
+	if seedset == nil {
+		seedset = system.prepareseeds_m(inputsize)
+	}
+	return system.pooled(system.PartyPiiRun(inputsets, *seedset))
+}
+
+// pooled returns the result of a run, or the *mpc.PoolExhaustedError of the
+// attached pool if the run needed more material than the pool held.
+func (system *PIISystem) pooled(result *Result, err error) (*Result, error) {
+	if err != nil {
+		return nil, err
+	}
+	if pool := system.PiiSystem.System.Pool; pool != nil {
+		if err := pool.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
 }
 
 func (system *PIISystem) GetCommunication() (float64, float64) {
 	return float64(system.PiiSystem.System.OfflineCom) / 1024 / 1024, float64(system.PiiSystem.System.Com) / 1024 / 1024
 }
 
-func PIIProtocol(intersize int, inputsize []int, mode int, isWAN bool, bandwidth float64) *PIISystem {
+// PIIProtocol returns a *mpc.MacCheckError when an opening fails its MAC
+// check, and mpc.ErrBandwidth for a WAN run without a positive bandwidth.
+// mode 0 compares all pairs of two parties, mode 2 only those in the same
+// hash bin, mode 3 reveals only the size of the intersection of two parties
+// and mode 4 only the sums of random payloads over it; any other mode, or more
+// than two parties, runs the multi-party protocol.
+func PIIProtocol(intersize int, inputsize []int, mode int, isWAN bool, bandwidth float64) (*PIISystem, error) {
 	partynum := len(inputsize)
+	piisystem, err := PiiInitSystem(partynum, isWAN, bandwidth)
+	if err != nil {
+		return nil, err
+	}
 	fmt.Printf("n = %d\n", partynum)
 	if isWAN {
 		fmt.Printf("Network Mode: WAN\n")
@@ -218,19 +328,61 @@ func PIIProtocol(intersize int, inputsize []int, mode int, isWAN bool, bandwidth
 	}
 	fmt.Printf("Input Sizes: %v\n", inputsize)
 	//fmt.Printf("Intersection Size: %d\n", intersize)
-	piisystem := PiiInitSystem(partynum, isWAN, bandwidth)
 	if mode == 0 && partynum == 2 {
 		timepoint := time.Now()
 		seedsets, privatesets := piisystem.PrepareData(intersize, inputsize)
 		timepoint1 := time.Since(timepoint)
 		fmt.Println("Data Preparation Time:", timepoint1)
-		piisystem.Run(seedsets, privatesets)
+		result, err := piisystem.Run(seedsets, privatesets)
+		if err != nil {
+			return piisystem, err
+		}
+		result.print()
+	} else if mode == 2 && partynum == 2 {
+		timepoint := time.Now()
+		privatesets, seedsets, err := piisystem.PrepareData_b(intersize, inputsize)
+		if err != nil {
+			return piisystem, err
+		}
+		timepoint1 := time.Since(timepoint)
+		fmt.Println("Data Preparation Time:", timepoint1)
+		result, err := piisystem.Run_b(privatesets, seedsets)
+		if err != nil {
+			return piisystem, err
+		}
+		result.print()
+	} else if mode == 3 && partynum == 2 {
+		timepoint := time.Now()
+		privatesets, seedsets := piisystem.PrepareData(intersize, inputsize)
+		timepoint1 := time.Since(timepoint)
+		fmt.Println("Data Preparation Time:", timepoint1)
+		result, err := piisystem.Run_ca(privatesets, seedsets)
+		if err != nil {
+			return piisystem, err
+		}
+		fmt.Println("cardinality:", result.Cardinality)
+		result.print()
+	} else if mode == 4 && partynum == 2 {
+		timepoint := time.Now()
+		privatesets := piisystem.PrepareData_s(intersize, inputsize)
+		timepoint1 := time.Since(timepoint)
+		fmt.Println("Data Preparation Time:", timepoint1)
+		result, err := piisystem.Run_s(privatesets)
+		if err != nil {
+			return piisystem, err
+		}
+		fmt.Println("sums:", result.Sums)
+		result.print()
 	} else {
 		timepoint := time.Now()
 		seedsets, privatesets := piisystem.PrepareData_m(intersize, inputsize)
 		timepoint1 := time.Since(timepoint)
 		fmt.Println("Data Preparation Time:", timepoint1)
-		piisystem.Run_m(seedsets, privatesets)
+		result, err := piisystem.Run_m(seedsets, privatesets)
+		if err != nil {
+			return piisystem, err
+		}
+		result.print()
 	}
-	return piisystem
+	return piisystem, nil
 }
@@ -0,0 +1,259 @@
+package pii
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	ibs "github.com/Oryx/IBS"
+	"github.com/Oryx/mpc"
+)
+
+var ErrThreshold = errors.New("pii: threshold must be between 1 and the number of parties")
+
+// poolneeds_t returns the triples, square pairs and random shares that
+// interphase_t takes from the pool when every signature is valid.
+func (system *PIISystem) poolneeds_t(inputsize []int, t int) (int, int, int) {
+	eqtriplets, eqsquarepairs := system.PiiSystem.System.EqZeroCost()
+	triplets, squarepairs, randoms := 0, 0, 0
+	n := len(inputsize)
+	for a := 0; a <= n-t; a++ {
+		// the product of c - s, and EXP_S_GT
+		muls := n - a - t + 1
+		squares := 0
+		rands := 1
+		if a > 0 {
+			// R*e
+			muls++
+			rands++
+		}
+		for k := 0; k < n; k++ {
+			if k == a || inputsize[k] == 0 {
+				continue
+			}
+			muls += inputsize[k] - 1 + eqtriplets
+			squares += eqsquarepairs
+		}
+		triplets += inputsize[a] * muls
+		squarepairs += inputsize[a] * squares
+		randoms += inputsize[a] * rands
+	}
+	return triplets, squarepairs, randoms
+}
+
+// openvers opens the verification results and tells for every element
+// whether its signature is valid.
+func (system *PIISystem) openvers(versets []VerSet) ([][]bool, error) {
+	var wg sync.WaitGroup
+	verres := make([][]bool, len(versets))
+	verbatch := system.PiiSystem.System.NewOpenBatch()
+	for i := range versets {
+		verres[i] = make([]bool, versets[i].inputsize)
+		for j := 0; j < versets[i].inputsize; j++ {
+			wg.Add(1)
+			go func(i, j int) {
+				defer wg.Done()
+				ver := verbatch.At(i, j).OpenGT(*versets[i].Vers[j])
+				verres[i][j] = bytes.Equal(ver.Marshal(), system.PiiSystem.IdentityGTbytes)
+			}(i, j)
+		}
+	}
+	wg.Wait()
+	if !system.PiiSystem.System.DeferredMacCheck(verbatch) {
+		return nil, verbatch.Err("verphase")
+	}
+	return verres, nil
+}
+
+// holder is the position of a candidate of interphase_t.
+type holder struct {
+	party, element int
+}
+
+// interphase_t outputs the identities held by at least t parties. Element q
+// of party a is a candidate; for every other party k the product of its
+// differences to the elements of k is tested with SecEqZero, which gives a
+// shared bit telling whether k holds it. The bits of the later parties are
+// summed into a shared count c, those of the earlier parties into e. For the
+// product f of c - s, s = t .. n-a, and a random shared R, f + R*e is zero
+// exactly when c reaches t and no earlier party holds the identity, so every
+// identity is found once, from its first holder. Only whether it is zero is
+// opened, and the identities are opened after these openings passed their
+// MAC check.
+func (system *PIISystem) interphase_t(versets []VerSet, t int) ([]*big.Int, error) {
+	ctx, cancel := context.WithCancelCause(system.PiiSystem.System.Context())
+	defer cancel(nil)
+	var wg sync.WaitGroup
+	n := system.partynum
+	verres, err := system.openvers(versets)
+	if err != nil {
+		return nil, err
+	}
+	var mu sync.Mutex
+	found := make([]holder, 0)
+	batch := system.PiiSystem.System.NewOpenBatch()
+	for a := 0; a <= n-t; a++ {
+		for q := 0; q < versets[a].inputsize; q++ {
+			if !verres[a][q] {
+				continue
+			}
+			wg.Add(1)
+			go func(a, q int) {
+				defer wg.Done()
+				defer mpc.Recover(cancel)
+				// the candidate itself is held by party a
+				c := system.PiiSystem.System.Share_An_Fp_Offline(big.NewInt(1))
+				var e *[]mpc.Share_Fp
+				for k := 0; k < n; k++ {
+					if k == a {
+						continue
+					}
+					if ctx.Err() != nil {
+						return
+					}
+					var v *[]mpc.Share_Fp
+					for j := 0; j < versets[k].inputsize; j++ {
+						if !verres[k][j] {
+							continue
+						}
+						d := system.PiiSystem.System.SecSub(*versets[a].HIDs[q], *versets[k].HIDs[j])
+						if v == nil {
+							v = d
+						} else {
+							v = system.PiiSystem.System.SecMul(*v, *d)
+						}
+					}
+					if v == nil {
+						continue
+					}
+					bit := system.PiiSystem.System.SecEqZero(*v)
+					if k > a {
+						c = system.PiiSystem.System.SecAdd(*c, *bit)
+					} else if e == nil {
+						e = bit
+					} else {
+						e = system.PiiSystem.System.SecAdd(*e, *bit)
+					}
+				}
+				z := system.PiiSystem.System.SecSubPlaintext(*c, big.NewInt(int64(t)))
+				for s := t + 1; s <= n-a; s++ {
+					z = system.PiiSystem.System.SecMul(*z, *system.PiiSystem.System.SecSubPlaintext(*c, big.NewInt(int64(s))))
+				}
+				if e != nil {
+					r, err := system.PiiSystem.System.RandomShareFp()
+					if err != nil {
+						cancel(err)
+						return
+					}
+					z = system.PiiSystem.System.SecAdd(*z, *system.PiiSystem.System.SecMul(*r, *e))
+				}
+				r, err := system.PiiSystem.System.RandomShareFp()
+				if err != nil {
+					cancel(err)
+					return
+				}
+				u := system.PiiSystem.System.EXP_P_GT_1(system.MK.G, z)
+				u = system.PiiSystem.System.EXP_S_GT(*u, *r)
+				uvalue := batch.At(a, q).OpenGT(*u)
+				if bytes.Equal(uvalue.Marshal(), system.PiiSystem.IdentityGTbytes) {
+					mu.Lock()
+					found = append(found, holder{a, q})
+					mu.Unlock()
+				}
+			}(a, q)
+		}
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+	if !system.PiiSystem.System.DeferredMacCheck(batch) {
+		return nil, batch.Err("interphase")
+	}
+	sort.Slice(found, func(x, y int) bool {
+		if found[x].party != found[y].party {
+			return found[x].party < found[y].party
+		}
+		return found[x].element < found[y].element
+	})
+	idbatch := system.PiiSystem.System.NewOpenBatch()
+	intersection := make([]*big.Int, len(found))
+	for k, h := range found {
+		intersection[k] = idbatch.At(h.party, h.element).OpenFp(*versets[h.party].HIDs[h.element])
+	}
+	if !system.PiiSystem.System.DeferredMacCheck(idbatch) {
+		return nil, idbatch.Err("interphase")
+	}
+	return intersection, nil
+}
+
+// ThresholdPiiRun is PartyPiiRun for identities held by at least t of the
+// parties instead of by all of them.
+func (system *PIISystem) ThresholdPiiRun(inputsets []InputSet, t int) (*Result, error) {
+	if t < 1 || t > system.partynum {
+		return nil, ErrThreshold
+	}
+	result := new(Result)
+	vertime := time.Now()
+	versets, err := system.verphase(inputsets)
+	if err != nil {
+		return nil, err
+	}
+	result.VerTime = time.Since(vertime)
+	intertime := time.Now()
+	result.Intersection, err = system.interphase_t(versets, t)
+	if err != nil {
+		return nil, err
+	}
+	result.InterTime = time.Since(intertime)
+	result.OfflineCom, result.OnlineCom = system.GetCommunication()
+	return result, nil
+}
+
+// Run_t runs the threshold mode on data from PrepareData_m. It takes its
+// triples, square pairs and seeds from the attached pool, if any.
+func (system *PIISystem) Run_t(inputsets []InputSet, t int) (*Result, error) {
+	return system.run_t(inputsets, t)
+}
+
+func (system *PIISystem) run_t(inputsets []InputSet, t int) (*Result, error) {
+	if t < 1 || t > system.partynum {
+		return nil, ErrThreshold
+	}
+	if pool := system.PiiSystem.System.Pool; pool != nil {
+		if err := pool.Require(system.poolneeds_t(inputsizes(inputsets), t)); err != nil {
+			return nil, err
+		}
+	}
+	return system.pooled(system.ThresholdPiiRun(inputsets, t))
+}
+
+// IntersectThreshold runs PII on the input sets of all parties and returns
+// the identities, taken from any party, that are held by at least t of them.
+// As for Intersect, input sets not made with ShareInput give hashed
+// identities.
+func (system *PIISystem) IntersectThreshold(inputsets []InputSet, t int) (*Result, error) {
+	if len(inputsets) != system.partynum {
+		return nil, ErrParties
+	}
+	result, err := system.run_t(inputsets, t)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]*big.Int)
+	for i := range inputsets {
+		for _, id := range inputsets[i].ids {
+			ids[ibs.H2(id).String()] = id
+		}
+	}
+	if len(ids) > 0 {
+		for k, hid := range result.Intersection {
+			result.Intersection[k] = ids[hid.String()]
+		}
+	}
+	return result, nil
+}
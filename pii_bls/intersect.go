@@ -0,0 +1,137 @@
+package pii_bls
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/Oryx/bls"
+	"github.com/Oryx/curve"
+	"github.com/Oryx/mpc"
+)
+
+var (
+	ErrIdentity  = errors.New("pii_bls: identity without public key or signature")
+	ErrParties   = errors.New("pii_bls: need one input set per party")
+	ErrDuplicate = errors.New("pii_bls: duplicate public key in an input set")
+	ErrMode      = errors.New("pii_bls: mode not supported for this number of parties")
+)
+
+// Identity is a public key together with a BLS signature on Msg. PrepareData
+// signs the marshalled public key.
+type Identity struct {
+	PK  *bls.PublicKey
+	Sig *bls.Sig
+	Msg []byte
+}
+
+// Result is the outcome of one run. OfflineCom and OnlineCom are the
+// communication of the system so far in MB, including the input sharing.
+// Cardinality is only set by the cardinality mode.
+type Result struct {
+	Intersection []*bls.PublicKey
+	Cardinality  int
+	VerTime      time.Duration
+	InterTime    time.Duration
+	OfflineCom   float64
+	OnlineCom    float64
+}
+
+func (result *Result) print() {
+	fmt.Println("ver time:", result.VerTime)
+	fmt.Println("inter time:", result.InterTime)
+	fmt.Printf("Offline Communication: %f MB\n", result.OfflineCom)
+	fmt.Printf("Online Communication: %f MB\n", result.OnlineCom)
+}
+
+// EnableIdentifiableAbort must be called before the inputs are shared. A
+// failed run then returns a *mpc.MacCheckError whose Cheater is the party
+// that deviated.
+func (system *PIISystem) EnableIdentifiableAbort() {
+	system.PiiSystem.System.EnableIdentifiableAbort()
+}
+
+// SetupOfflinePhase makes the system take its triples and the MACs of its
+// seeds from the offline phase of mpc instead of the dealer. bits is the
+// Paillier modulus size, e.g. 2048. Call it before the inputs are shared.
+func (system *PIISystem) SetupOfflinePhase(bits int) error {
+	return system.PiiSystem.System.SetupOfflinePhase(bits)
+}
+
+// ShareInput secret-shares the identities of one party. The signatures are
+// verified inside the protocol, so an identity with an invalid signature is
+// never part of the intersection. A public key may appear only once;
+// otherwise ErrDuplicate is returned.
+func (system *PIISystem) ShareInput(partyindex int, identities []Identity) (InputSet, error) {
+	seen := make(map[string]bool, len(identities))
+	for _, identity := range identities {
+		if identity.PK == nil || identity.Sig == nil {
+			return InputSet{}, ErrIdentity
+		}
+		pk := string(identity.PK.PK.Marshal())
+		if seen[pk] {
+			return InputSet{}, ErrDuplicate
+		}
+		seen[pk] = true
+	}
+	var inputset InputSet
+	inputset.Sigs = make([]*bls.Share_Sig, len(identities))
+	inputset.PKxshares = make([](*[]mpc.Share_Fp), len(identities))
+	inputset.pks = make([]*bls.PublicKey, len(identities))
+	for q, identity := range identities {
+		hm := curve.HashToG1(identity.Msg)
+		inputset.Sigs[q] = system.PiiSystem.Share_A_Sig(identity.Sig, hm, identity.PK)
+		pkhash := sha256.Sum256(identity.PK.PK.Marshal())
+		pkint := new(big.Int).SetBytes(pkhash[:])
+		pkint = new(big.Int).Mod(pkint, system.PiiSystem.System.Order)
+		inputset.PKxshares[q] = system.PiiSystem.System.Share_An_Fp_Offline(pkint)
+		inputset.pks[q] = identity.PK
+	}
+	inputset.Partyindex = partyindex
+	inputset.inputsize = len(identities)
+	return inputset, nil
+}
+
+// Intersect runs PII on the input sets of two parties, in party order, and
+// returns the public keys of party 0 that are in the intersection. Mode 0
+// runs the two-party protocol, mode 1 PartyPiiRun and mode 3 returns only the
+// size of the intersection as Cardinality does. Any other mode or number of
+// parties returns ErrMode.
+func (system *PIISystem) Intersect(inputsets []InputSet, mode int) (*Result, error) {
+	if len(inputsets) != system.partynum {
+		return nil, ErrParties
+	}
+	inputsize := make([]int, len(inputsets))
+	for i := range inputsets {
+		inputsize[i] = inputsets[i].inputsize
+	}
+	if system.partynum != 2 || (mode != 0 && mode != 1 && mode != 3) {
+		return nil, ErrMode
+	}
+	seedsets := system.prepareseeds(inputsize)
+	var result *Result
+	var err error
+	switch mode {
+	case 0:
+		result, err = system.twoPartyPiiRun(inputsets, seedsets)
+	case 1:
+		result, err = system.PartyPiiRun(inputsets, seedsets)
+	case 3:
+		return system.cardinalityPiiRun(inputsets, seedsets)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if inputsets[0].pks != nil {
+		pks := make(map[string]*bls.PublicKey, len(inputsets[0].pks))
+		for _, pk := range inputsets[0].pks {
+			pks[string(pk.PK.Marshal())] = pk
+		}
+		for k, pk := range result.Intersection {
+			result.Intersection[k] = pks[string(pk.PK.Marshal())]
+		}
+	}
+	return result, nil
+}
@@ -2,17 +2,19 @@ package pii_bls
 
 import (
 	"bytes"
-	"fmt"
-	"os"
+	"context"
 	"sync"
 	"time"
 
+	"github.com/Oryx/bls"
 	"github.com/Oryx/curve"
 	"github.com/Oryx/mpc"
 )
 
-func (system *PIISystem) verphase(inputsets []InputSet) []VerSet {
+func (system *PIISystem) verphase(inputsets []InputSet) ([]VerSet, error) {
 	versets := make([]VerSet, system.partynum)
+	ctx, cancel := context.WithCancelCause(system.PiiSystem.System.Context())
+	defer cancel(nil)
 	var wg sync.WaitGroup // Add missing import for "sync" package
 	blocknum := 4096
 	for i := 0; i < system.partynum; i++ {
@@ -26,7 +28,11 @@ func (system *PIISystem) verphase(inputsets []InputSet) []VerSet {
 				wg.Add(1)
 				go func(i, j int) {
 					defer wg.Done()
+					defer mpc.Recover(cancel)
 					for q := j; q < inputsets[i].inputsize; q++ {
+						if ctx.Err() != nil {
+							return
+						}
 						versets[i].Vers[q] = system.PiiSystem.SecVerWithoutOpen(inputsets[i].Sigs[q])
 						//fmt.Println(system.PiiSystem.System.HalfOpenGT(*versets[i].Vers[q]))
 						versets[i].PKshares[q] = inputsets[i].Sigs[q].Pkshare
@@ -38,7 +44,11 @@ func (system *PIISystem) verphase(inputsets []InputSet) []VerSet {
 				wg.Add(1)
 				go func(i, j int) {
 					defer wg.Done()
+					defer mpc.Recover(cancel)
 					for q := j; q < j+eachblock; q++ {
+						if ctx.Err() != nil {
+							return
+						}
 						versets[i].Vers[q] = system.PiiSystem.SecVerWithoutOpen(inputsets[i].Sigs[q])
 						//fmt.Println(system.PiiSystem.System.HalfOpenGT(*versets[i].Vers[q]))
 						versets[i].PKshares[q] = inputsets[i].Sigs[q].Pkshare
@@ -50,17 +60,24 @@ func (system *PIISystem) verphase(inputsets []InputSet) []VerSet {
 		}
 	}
 	wg.Wait()
-	return versets
+	if ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+	return versets, nil
 }
 
-func (system *PIISystem) interphase(versets []VerSet, seedsets []SeedSet) []*curve.G2 {
+func (system *PIISystem) interphase(versets []VerSet, seedsets []SeedSet) ([]*curve.G2, error) {
 	intersection := make([]*curve.G2, 0)
 	interChan := make(chan *curve.G2, 100)
+	done := make(chan struct{})
+	ctx, cancel := context.WithCancelCause(system.PiiSystem.System.Context())
+	defer cancel(nil)
 	var wg sync.WaitGroup
 	go func() {
 		for inter := range interChan {
 			intersection = append(intersection, inter)
 		}
+		close(done)
 	}()
 	if versets[0].inputsize*versets[1].inputsize <= 16384 {
 		for i := 0; i < versets[0].inputsize; i++ {
@@ -68,8 +85,12 @@ func (system *PIISystem) interphase(versets []VerSet, seedsets []SeedSet) []*cur
 				wg.Add(1)
 				go func(i, j int) {
 					defer wg.Done()
+					defer mpc.Recover(cancel)
+					if ctx.Err() != nil {
+						return
+					}
 					v := system.PiiSystem.System.SecSub_G2(*versets[0].PKshares[i], *versets[1].PKshares[j])
-					w := system.PiiSystem.System.Pair_P_2(system.PiiSystem.System.IdentityG1, v)
+					w := system.PiiSystem.System.Pair_P_2(curve.Gen1, v)
 					//w := system.PiiSystem.System.EXP_P_GT_1(system.PiiSystem.System.GenGT, v)
 					w = system.PiiSystem.System.SecAdd_GT(*w, *versets[0].Vers[i])
 					w = system.PiiSystem.System.SecAdd_GT(*w, *versets[1].Vers[j])
@@ -81,13 +102,13 @@ func (system *PIISystem) interphase(versets []VerSet, seedsets []SeedSet) []*cur
 							if chkid {
 								interChan <- interid
 							} else {
-								fmt.Println("chk error")
-								os.Exit(1)
+								cancel(&mpc.MacCheckError{Op: "OpenG2", Party: 0, Element: i, Cheater: system.PiiSystem.System.IdentifyG2(*versets[0].PKshares[i])})
+								return
 							}
 						}
 					} else {
-						fmt.Println("chk error")
-						os.Exit(1)
+						cancel(&mpc.MacCheckError{Op: "OpenGT", Party: 0, Element: i, Cheater: system.PiiSystem.System.IdentifyGT(*w)})
+						return
 					}
 				}(i, j)
 			}
@@ -97,9 +118,13 @@ func (system *PIISystem) interphase(versets []VerSet, seedsets []SeedSet) []*cur
 			wg.Add(1)
 			go func(i int) {
 				defer wg.Done()
+				defer mpc.Recover(cancel)
 				for j := 0; j < versets[1].inputsize; j++ {
+					if ctx.Err() != nil {
+						return
+					}
 					v := system.PiiSystem.System.SecSub_G2(*versets[0].PKshares[i], *versets[1].PKshares[j])
-					w := system.PiiSystem.System.Pair_P_2(system.PiiSystem.System.IdentityG1, v)
+					w := system.PiiSystem.System.Pair_P_2(curve.Gen1, v)
 					w = system.PiiSystem.System.SecAdd_GT(*w, *versets[0].Vers[i])
 					w = system.PiiSystem.System.SecAdd_GT(*w, *versets[1].Vers[j])
 					w = system.PiiSystem.System.EXP_S_GT(*w, *seedsets[i].Seeds[j])
@@ -110,13 +135,13 @@ func (system *PIISystem) interphase(versets []VerSet, seedsets []SeedSet) []*cur
 							if chkid {
 								interChan <- interid
 							} else {
-								fmt.Println("chk error")
-								os.Exit(1)
+								cancel(&mpc.MacCheckError{Op: "OpenG2", Party: 0, Element: i, Cheater: system.PiiSystem.System.IdentifyG2(*versets[0].PKshares[i])})
+								return
 							}
 						}
 					} else {
-						fmt.Println("chk error")
-						os.Exit(1)
+						cancel(&mpc.MacCheckError{Op: "OpenGT", Party: 0, Element: i, Cheater: system.PiiSystem.System.IdentifyGT(*w)})
+						return
 					}
 				}
 			}(i)
@@ -124,20 +149,31 @@ func (system *PIISystem) interphase(versets []VerSet, seedsets []SeedSet) []*cur
 	}
 	wg.Wait()
 	close(interChan) // Close the channel after all goroutines are done
-	return intersection
+	<-done
+	if ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+	return intersection, nil
 }
 
-func (system *PIISystem) twoPartyPiiRun(inputsets []InputSet, seedsets []SeedSet) []*curve.G2 {
-	fmt.Println("ver phase start")
+func (system *PIISystem) twoPartyPiiRun(inputsets []InputSet, seedsets []SeedSet) (*Result, error) {
+	result := new(Result)
 	vertime := time.Now()
-	versets := system.verphase(inputsets)
-	fmt.Println("ver time:", time.Since(vertime))
-	fmt.Println("inter phase start")
+	versets, err := system.verphase(inputsets)
+	if err != nil {
+		return nil, err
+	}
+	result.VerTime = time.Since(vertime)
 	intertime := time.Now()
-	intersection := system.interphase(versets, seedsets)
-	fmt.Println("inter time:", time.Since(intertime))
-	//fmt.Println("intersection:", intersection)
-	fmt.Printf("Offline Communication: %f MB\n", float64(system.PiiSystem.System.OfflineCom)/1024/1024)
-	fmt.Printf("Online Communication: %f MB\n", float64(system.PiiSystem.System.Com)/1024/1024)
-	return intersection
+	intersection, err := system.interphase(versets, seedsets)
+	if err != nil {
+		return nil, err
+	}
+	result.InterTime = time.Since(intertime)
+	result.OfflineCom, result.OnlineCom = system.GetCommunication()
+	result.Intersection = make([]*bls.PublicKey, len(intersection))
+	for k, pk := range intersection {
+		result.Intersection[k] = &bls.PublicKey{PK: pk}
+	}
+	return result, nil
 }
@@ -2,22 +2,27 @@ package pii_bls
 
 import (
 	"bytes"
-	"fmt"
-	"os"
+	"context"
 	"sync"
 	"time"
 
+	"github.com/Oryx/bls"
 	"github.com/Oryx/curve"
+	"github.com/Oryx/mpc"
 )
 
-func (system *PIISystem) interphase_v(versets []VerSet, seedsets []SeedSet) []*curve.G2 {
+func (system *PIISystem) interphase_v(versets []VerSet, seedsets []SeedSet) ([]*curve.G2, error) {
 	intersection := make([]*curve.G2, 0)
 	interChan := make(chan *curve.G2, 100)
+	done := make(chan struct{})
+	ctx, cancel := context.WithCancelCause(system.PiiSystem.System.Context())
+	defer cancel(nil)
 	var wg sync.WaitGroup
 	go func() {
 		for inter := range interChan {
 			intersection = append(intersection, inter)
 		}
+		close(done)
 	}()
 	if versets[0].inputsize*versets[1].inputsize <= 16384 {
 		for i := 0; i < versets[0].inputsize; i++ {
@@ -25,8 +30,12 @@ func (system *PIISystem) interphase_v(versets []VerSet, seedsets []SeedSet) []*c
 				wg.Add(1)
 				go func(i, j int) {
 					defer wg.Done()
+					defer mpc.Recover(cancel)
+					if ctx.Err() != nil {
+						return
+					}
 					v := system.PiiSystem.System.SecSub_G2(*versets[0].PKshares[i], *versets[1].PKshares[j])
-					w := system.PiiSystem.System.Pair_P_2(system.PiiSystem.System.IdentityG1, v)
+					w := system.PiiSystem.System.Pair_P_2(curve.Gen1, v)
 					//w := system.PiiSystem.System.EXP_P_GT_1(system.PiiSystem.System.GenGT, v)
 					w = system.PiiSystem.System.SecAdd_GT(*w, *versets[0].Vers[i])
 					w = system.PiiSystem.System.SecAdd_GT(*w, *versets[1].Vers[j])
@@ -38,13 +47,13 @@ func (system *PIISystem) interphase_v(versets []VerSet, seedsets []SeedSet) []*c
 							if chkid {
 								interChan <- interid
 							} else {
-								fmt.Println("chk error")
-								os.Exit(1)
+								cancel(&mpc.MacCheckError{Op: "OpenG2", Party: 0, Element: i, Cheater: system.PiiSystem.System.IdentifyG2(*versets[0].PKshares[i])})
+								return
 							}
 						}
 					} else {
-						fmt.Println("chk error")
-						os.Exit(1)
+						cancel(&mpc.MacCheckError{Op: "OpenGT", Party: 0, Element: i, Cheater: system.PiiSystem.System.IdentifyGT(*w)})
+						return
 					}
 				}(i, j)
 			}
@@ -54,9 +63,13 @@ func (system *PIISystem) interphase_v(versets []VerSet, seedsets []SeedSet) []*c
 			wg.Add(1)
 			go func(i int) {
 				defer wg.Done()
+				defer mpc.Recover(cancel)
 				for j := 0; j < versets[1].inputsize; j++ {
+					if ctx.Err() != nil {
+						return
+					}
 					v := system.PiiSystem.System.SecSub_G2(*versets[0].PKshares[i], *versets[1].PKshares[j])
-					w := system.PiiSystem.System.Pair_P_2(system.PiiSystem.System.IdentityG1, v)
+					w := system.PiiSystem.System.Pair_P_2(curve.Gen1, v)
 					w = system.PiiSystem.System.SecAdd_GT(*w, *versets[0].Vers[i])
 					w = system.PiiSystem.System.SecAdd_GT(*w, *versets[1].Vers[j])
 					w = system.PiiSystem.System.EXP_S_GT(*w, *seedsets[i].Seeds[j])
@@ -67,13 +80,13 @@ func (system *PIISystem) interphase_v(versets []VerSet, seedsets []SeedSet) []*c
 							if chkid {
 								interChan <- interid
 							} else {
-								fmt.Println("chk error")
-								os.Exit(1)
+								cancel(&mpc.MacCheckError{Op: "OpenG2", Party: 0, Element: i, Cheater: system.PiiSystem.System.IdentifyG2(*versets[0].PKshares[i])})
+								return
 							}
 						}
 					} else {
-						fmt.Println("chk error")
-						os.Exit(1)
+						cancel(&mpc.MacCheckError{Op: "OpenGT", Party: 0, Element: i, Cheater: system.PiiSystem.System.IdentifyGT(*w)})
+						return
 					}
 				}
 			}(i)
@@ -81,20 +94,31 @@ func (system *PIISystem) interphase_v(versets []VerSet, seedsets []SeedSet) []*c
 	}
 	wg.Wait()
 	close(interChan) // Close the channel after all goroutines are done
-	return intersection
+	<-done
+	if ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+	return intersection, nil
 }
 
-func (system *PIISystem) PartyPiiRun(inputsets []InputSet, seedsets []SeedSet) []*curve.G2 {
-	fmt.Println("ver phase start")
+func (system *PIISystem) PartyPiiRun(inputsets []InputSet, seedsets []SeedSet) (*Result, error) {
+	result := new(Result)
 	vertime := time.Now()
-	versets := system.verphase(inputsets)
-	fmt.Println("ver time:", time.Since(vertime))
-	fmt.Println("inter phase start")
+	versets, err := system.verphase(inputsets)
+	if err != nil {
+		return nil, err
+	}
+	result.VerTime = time.Since(vertime)
 	intertime := time.Now()
-	intersection := system.interphase_v(versets, seedsets)
-	fmt.Println("inter time:", time.Since(intertime))
-	//fmt.Println("intersection:", intersection)
-	fmt.Printf("Offline Communication: %f MB\n", float64(system.PiiSystem.System.OfflineCom)/1024/1024)
-	fmt.Printf("Online Communication: %f MB\n", float64(system.PiiSystem.System.Com)/1024/1024)
-	return intersection
+	intersection, err := system.interphase_v(versets, seedsets)
+	if err != nil {
+		return nil, err
+	}
+	result.InterTime = time.Since(intertime)
+	result.OfflineCom, result.OnlineCom = system.GetCommunication()
+	result.Intersection = make([]*bls.PublicKey, len(intersection))
+	for k, pk := range intersection {
+		result.Intersection[k] = &bls.PublicKey{PK: pk}
+	}
+	return result, nil
 }
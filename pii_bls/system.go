@@ -27,6 +27,7 @@ type InputSet struct {
 	PKxshares  [](*[]mpc.Share_Fp)
 	Partyindex int
 	inputsize  int
+	pks        []*bls.PublicKey
 }
 
 type VerSet struct {
@@ -55,11 +56,20 @@ func (system *PIISystem) generateBigIntSlice(size, intersize int) []*bls.Private
 	return slice
 }
 
-func PiiInitSystem(Partynum int, isWAN bool, bandwidth float64) *PIISystem {
+// PiiInitSystem returns mpc.ErrBandwidth for a WAN system without a positive
+// bandwidth.
+func PiiInitSystem(Partynum int, isWAN bool, bandwidth float64) (*PIISystem, error) {
 	piisystem := new(PIISystem)
-	piisystem.PiiSystem = bls.SecureVerInit(2, true, isWAN, bandwidth)
+	securever, err := bls.SecureVerInit(2, true, isWAN, bandwidth)
+	if err != nil {
+		return nil, err
+	}
+	piisystem.PiiSystem = securever
+	if err := piisystem.PiiSystem.System.SetupPRSS(); err != nil {
+		return nil, err
+	}
 	piisystem.partynum = Partynum
-	return piisystem
+	return piisystem, nil
 }
 
 func (system *PIISystem) prepareid(intersize int, inputsize []int) []IDSet {
@@ -89,49 +99,30 @@ func (system *PIISystem) prepareid(intersize int, inputsize []int) []IDSet {
 	return idsets
 }
 
+// seeds derives the PRSS values with indices k to k+n-1, so that the dealer
+// does not pick them. It panics with the error of their MACs.
+func (system *PIISystem) seeds(k uint64, n int) []*[]mpc.Share_Fp {
+	shares, err := system.PiiSystem.System.RandomSharesFp_PRSS(k, n)
+	if err != nil {
+		panic(err)
+	}
+	return shares
+}
+
 func (system *PIISystem) prepareseeds(inputsize []int) []SeedSet {
-	var wg sync.WaitGroup
 	seedsets := make([]SeedSet, inputsize[0])
-	if inputsize[0]*inputsize[1] < 16384 {
-		for i := 0; i < inputsize[0]; i++ {
-			seedsets[i].Seeds = make([](*[]mpc.Share_Fp), inputsize[1])
-			for j := 0; j < inputsize[1]; j++ {
-				wg.Add(1)
-				go func(i, j int) {
-					defer wg.Done()
-					seedsets[i].Seeds[j] = system.PiiSystem.System.RandomShareFp()
-				}(i, j)
-			}
-		}
-	} else {
-		for i := 0; i < inputsize[0]; i++ {
-			seedsets[i].Seeds = make([](*[]mpc.Share_Fp), inputsize[1])
-			wg.Add(1)
-			go func(i int) {
-				defer wg.Done()
-				for j := 0; j < inputsize[1]; j++ {
-					seedsets[i].Seeds[j] = system.PiiSystem.System.RandomShareFp()
-				}
-			}(i)
-		}
+	base := system.PiiSystem.System.ReservePRSS(inputsize[0] * inputsize[1])
+	seeds := system.seeds(base, inputsize[0]*inputsize[1])
+	for i := 0; i < inputsize[0]; i++ {
+		seedsets[i].Seeds = seeds[i*inputsize[1] : (i+1)*inputsize[1] : (i+1)*inputsize[1]]
 	}
-	wg.Wait()
 	return seedsets
 }
 
 func (system *PIISystem) prepareseeds_m(inputsize []int) *SeedSet {
-	var wg sync.WaitGroup
 	seedsets := new(SeedSet)
-	seedsets.Seeds = make([]*[]mpc.Share_Fp, inputsize[0])
-	//wg.Wait()
-	for i := 0; i < inputsize[0]; i++ {
-		wg.Add(1)
-		go func(i int) {
-			defer wg.Done()
-			seedsets.Seeds[i] = system.PiiSystem.System.RandomShareFp()
-		}(i)
-	}
-	wg.Wait()
+	base := system.PiiSystem.System.ReservePRSS(inputsize[0])
+	seedsets.Seeds = system.seeds(base, inputsize[0])
 	return seedsets
 }
 
@@ -200,20 +191,28 @@ func (system *PIISystem) PrepareData_m(intersize int, inputsize []int) ([]InputS
 	return privatesets, seedsets
 }
 
-func (system *PIISystem) Run(inputsets []InputSet, seedset []SeedSet) {
-	system.twoPartyPiiRun(inputsets, seedset)
+func (system *PIISystem) Run(inputsets []InputSet, seedset []SeedSet) (*Result, error) {
+	return system.twoPartyPiiRun(inputsets, seedset)
 }
 
-func (system *PIISystem) Run_v(inputsets []InputSet, seedset []SeedSet) {
-	system.PartyPiiRun(inputsets, seedset)
+func (system *PIISystem) Run_v(inputsets []InputSet, seedset []SeedSet) (*Result, error) {
+	return system.PartyPiiRun(inputsets, seedset)
 }
 
 func (system *PIISystem) GetCommunication() (float64, float64) {
 	return float64(system.PiiSystem.System.OfflineCom) / 1024 / 1024, float64(system.PiiSystem.System.Com) / 1024 / 1024
 }
 
-func PIIProtocol(intersize int, inputsize []int, mode int, isWAN bool, bandwidth float64) *PIISystem {
+// PIIProtocol returns a *mpc.MacCheckError when an opening fails its MAC
+// check, and mpc.ErrBandwidth for a WAN run without a positive bandwidth.
+// mode 0 runs the two-party protocol and mode 3 reveals only the size of the
+// intersection of two parties; any other mode runs the multi-party protocol.
+func PIIProtocol(intersize int, inputsize []int, mode int, isWAN bool, bandwidth float64) (*PIISystem, error) {
 	partynum := len(inputsize)
+	piisystem, err := PiiInitSystem(partynum, isWAN, bandwidth)
+	if err != nil {
+		return nil, err
+	}
 	fmt.Printf("n = %d\n", partynum)
 	if isWAN {
 		fmt.Printf("Network Mode: WAN\n")
@@ -222,15 +221,29 @@ func PIIProtocol(intersize int, inputsize []int, mode int, isWAN bool, bandwidth
 		fmt.Printf("Network Mode: LAN\n")
 	}
 	fmt.Printf("Input Sizes: %v\n", inputsize)
-	piisystem := PiiInitSystem(partynum, isWAN, bandwidth)
 	timepoint := time.Now()
 	seedsets, privatesets := piisystem.PrepareData(intersize, inputsize)
 	timepoint1 := time.Since(timepoint)
 	fmt.Println("Data Preparation Time:", timepoint1)
 	if mode == 0 {
-		piisystem.Run(seedsets, privatesets)
+		result, err := piisystem.Run(seedsets, privatesets)
+		if err != nil {
+			return piisystem, err
+		}
+		result.print()
+	} else if mode == 3 && partynum == 2 {
+		result, err := piisystem.Run_ca(seedsets, privatesets)
+		if err != nil {
+			return piisystem, err
+		}
+		fmt.Println("cardinality:", result.Cardinality)
+		result.print()
 	} else {
-		piisystem.Run_v(seedsets, privatesets)
+		result, err := piisystem.Run_v(seedsets, privatesets)
+		if err != nil {
+			return piisystem, err
+		}
+		result.print()
 	}
-	return piisystem
+	return piisystem, nil
 }
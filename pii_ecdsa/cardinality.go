@@ -0,0 +1,91 @@
+package pii_ecdsa
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Oryx/mpc"
+)
+
+// interphase_ca computes the blinded comparison result of every pair as in
+// interphase, shuffles the results and opens them, so only the number of
+// matches is learnt.
+func (system *PIISystem) interphase_ca(versets []VerSet, seedsets []SeedSet) (int, error) {
+	ctx, cancel := context.WithCancelCause(system.PiiSystem.System.Context())
+	defer cancel(nil)
+	var wg sync.WaitGroup
+	m := versets[1].inputsize
+	ws := make([](*[]mpc.Share_G), versets[0].inputsize*m)
+	for i := 0; i < versets[0].inputsize; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer mpc.Recover(cancel)
+			for j := 0; j < m; j++ {
+				if ctx.Err() != nil {
+					return
+				}
+				w := system.PiiSystem.System.SecSub_G(*versets[0].PKshares[i], *versets[1].PKshares[j])
+				w = system.PiiSystem.System.SecAdd_G(*w, *versets[0].Vers[i])
+				w = system.PiiSystem.System.SecAdd_G(*w, *versets[1].Vers[j])
+				ws[i*m+j] = system.PiiSystem.System.EXP_S_G(*w, *seedsets[i].Seeds[j])
+			}
+		}(i)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return 0, context.Cause(ctx)
+	}
+	ws = system.PiiSystem.System.SecShuffle_G(ws)
+	batch := system.PiiSystem.System.NewOpenBatch()
+	cardinality := 0
+	for _, w := range ws {
+		wvalueX, _ := batch.OpenG(*w)
+		if bytes.Equal(wvalueX.Bytes(), system.PiiSystem.System.IdentityGx.Bytes()) {
+			cardinality++
+		}
+	}
+	if !system.PiiSystem.System.DeferredMacCheck(batch) {
+		return 0, batch.Err("interphase")
+	}
+	return cardinality, nil
+}
+
+func (system *PIISystem) cardinalityPiiRun(inputsets []InputSet, seedsets []SeedSet) (*Result, error) {
+	result := new(Result)
+	vertime := time.Now()
+	versets, err := system.verphase(inputsets)
+	if err != nil {
+		return nil, err
+	}
+	result.VerTime = time.Since(vertime)
+	intertime := time.Now()
+	result.Cardinality, err = system.interphase_ca(versets, seedsets)
+	if err != nil {
+		return nil, err
+	}
+	result.InterTime = time.Since(intertime)
+	result.OfflineCom, result.OnlineCom = system.GetCommunication()
+	return result, nil
+}
+
+// Run_ca runs the two-party cardinality mode on data from PrepareData;
+// Result.Cardinality holds the size of the intersection.
+func (system *PIISystem) Run_ca(inputsets []InputSet, seedset []SeedSet) (*Result, error) {
+	return system.cardinalityPiiRun(inputsets, seedset)
+}
+
+// Cardinality runs PII on the input sets of two parties and returns only the
+// number of public keys they share; Result.Intersection stays empty. The
+// comparison results are shuffled before they are opened, so neither the
+// matching positions nor the public keys are revealed to a party that does not
+// collude with the dealer, which picks the shuffle.
+func (system *PIISystem) Cardinality(inputsets []InputSet) (*Result, error) {
+	if len(inputsets) != system.partynum || system.partynum != 2 {
+		return nil, ErrParties
+	}
+	inputsize := []int{inputsets[0].inputsize, inputsets[1].inputsize}
+	return system.cardinalityPiiRun(inputsets, system.prepareseeds(inputsize))
+}
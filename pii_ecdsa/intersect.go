@@ -0,0 +1,127 @@
+package pii_ecdsa
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/Oryx/ecdsa"
+	"github.com/Oryx/mpc"
+)
+
+var (
+	ErrIdentity  = errors.New("pii_ecdsa: identity without public key or signature")
+	ErrParties   = errors.New("pii_ecdsa: need one input set per party")
+	ErrDuplicate = errors.New("pii_ecdsa: duplicate public key in an input set")
+	ErrMode      = errors.New("pii_ecdsa: mode not supported for this number of parties")
+)
+
+// Identity is a public key together with a signature made by SignwithInv.
+// PrepareData signs the x-coordinate of the public key.
+type Identity struct {
+	PK  *ecdsa.PublicKey
+	Sig *ecdsa.SigInv
+}
+
+// Result is the outcome of one run. OfflineCom and OnlineCom are the
+// communication of the system so far in MB, including the input sharing.
+// Intersection holds the x-coordinates of the public keys, which is all the
+// protocol opens; Intersect also looks up the keys in PublicKeys.
+// Cardinality is only set by the cardinality mode.
+type Result struct {
+	Intersection []*big.Int
+	PublicKeys   []*ecdsa.PublicKey
+	Cardinality  int
+	VerTime      time.Duration
+	InterTime    time.Duration
+	OfflineCom   float64
+	OnlineCom    float64
+}
+
+func (result *Result) print() {
+	fmt.Println("ver time: ", result.VerTime)
+	fmt.Println("inter time: ", result.InterTime)
+	fmt.Printf("Offline Communication: %f MB\n", result.OfflineCom)
+	fmt.Printf("Online Communication: %f MB\n", result.OnlineCom)
+}
+
+// SetupOfflinePhase makes the system take its triples and the MACs of its
+// seeds from the offline phase of mpc instead of the dealer. bits is the
+// Paillier modulus size, e.g. 2048. Call it before the inputs are shared.
+func (system *PIISystem) SetupOfflinePhase(bits int) error {
+	return system.PiiSystem.System.SetupOfflinePhase(bits)
+}
+
+// ShareInput secret-shares the identities of one party. The signatures are
+// verified inside the protocol, so an identity with an invalid signature is
+// never part of the intersection. A public key may appear only once;
+// otherwise ErrDuplicate is returned.
+func (system *PIISystem) ShareInput(partyindex int, identities []Identity) (InputSet, error) {
+	seen := make(map[string]bool, len(identities))
+	for _, identity := range identities {
+		if identity.PK == nil || identity.Sig == nil {
+			return InputSet{}, ErrIdentity
+		}
+		pk := identity.PK.PKX.String() + "," + identity.PK.PKY.String()
+		if seen[pk] {
+			return InputSet{}, ErrDuplicate
+		}
+		seen[pk] = true
+	}
+	var inputset InputSet
+	inputset.Sigs = make([]*ecdsa.Share_Sig, len(identities))
+	inputset.PKxshares = make([](*[]mpc.Share_Fp), len(identities))
+	inputset.pks = make([]*ecdsa.PublicKey, len(identities))
+	for q, identity := range identities {
+		inputset.Sigs[q] = system.PiiSystem.Share_A_Sig(*identity.Sig, identity.PK)
+		inputset.PKxshares[q] = system.PiiSystem.System.Share_An_Fp_Offline(new(big.Int).Mod(identity.PK.PKX, system.PiiSystem.System.Order))
+		inputset.pks[q] = identity.PK
+	}
+	inputset.Partyindex = partyindex
+	inputset.inputsize = len(identities)
+	return inputset, nil
+}
+
+// Intersect runs PII on the input sets of all parties, in party order, and
+// returns in PublicKeys the keys of party 0 that are in the intersection. For
+// two parties, mode 0 runs the two-party protocol and mode 3 returns only the
+// size of the intersection as Cardinality does. Mode 1 runs the multi-party
+// protocol for any number of parties. Any other mode or number of parties
+// returns ErrMode.
+func (system *PIISystem) Intersect(inputsets []InputSet, mode int) (*Result, error) {
+	if len(inputsets) != system.partynum {
+		return nil, ErrParties
+	}
+	inputsize := make([]int, len(inputsets))
+	for i := range inputsets {
+		inputsize[i] = inputsets[i].inputsize
+	}
+	var result *Result
+	var err error
+	switch {
+	case mode == 0 && system.partynum == 2:
+		result, err = system.twoPartyPiiRun(inputsets, system.prepareseeds(inputsize))
+	case mode == 1:
+		result, err = system.PartyPiiRun(inputsets, *system.prepareseeds_m(inputsize))
+	case mode == 3 && system.partynum == 2:
+		return system.cardinalityPiiRun(inputsets, system.prepareseeds(inputsize))
+	default:
+		return nil, ErrMode
+	}
+	if err != nil {
+		return nil, err
+	}
+	if inputsets[0].pks != nil {
+		order := system.PiiSystem.System.Order
+		pks := make(map[string]*ecdsa.PublicKey, len(inputsets[0].pks))
+		for _, pk := range inputsets[0].pks {
+			pks[new(big.Int).Mod(pk.PKX, order).String()] = pk
+		}
+		result.PublicKeys = make([]*ecdsa.PublicKey, len(result.Intersection))
+		for k, x := range result.Intersection {
+			result.PublicKeys[k] = pks[new(big.Int).Mod(x, order).String()]
+		}
+	}
+	return result, nil
+}
@@ -2,17 +2,18 @@ package pii_ecdsa
 
 import (
 	"bytes"
-	"fmt"
+	"context"
 	"math/big"
-	"os"
 	"sync"
 	"time"
 
 	"github.com/Oryx/mpc"
 )
 
-func (system *PIISystem) verphase(inputsets []InputSet) []VerSet {
+func (system *PIISystem) verphase(inputsets []InputSet) ([]VerSet, error) {
 	versets := make([]VerSet, system.partynum)
+	ctx, cancel := context.WithCancelCause(system.PiiSystem.System.Context())
+	defer cancel(nil)
 	var wg sync.WaitGroup
 	blocknum := 4096
 	for i := 0; i < system.partynum; i++ {
@@ -26,7 +27,11 @@ func (system *PIISystem) verphase(inputsets []InputSet) []VerSet {
 				wg.Add(1)
 				go func(i, j int) {
 					defer wg.Done()
+					defer mpc.Recover(cancel)
 					for q := j; q < inputsets[i].inputsize; q++ {
+						if ctx.Err() != nil {
+							return
+						}
 						versets[i].Vers[q] = system.PiiSystem.SecVerWithoutOpen(inputsets[i].Sigs[q])
 						//fmt.Println(system.PiiSystem.System.HalfOpenG(*versets[i].Vers[q]))
 						versets[i].PKshares[q] = inputsets[i].Sigs[q].Pkshare
@@ -38,7 +43,11 @@ func (system *PIISystem) verphase(inputsets []InputSet) []VerSet {
 				wg.Add(1)
 				go func(i, j int) {
 					defer wg.Done()
+					defer mpc.Recover(cancel)
 					for q := j; q < j+eachblock; q++ {
+						if ctx.Err() != nil {
+							return
+						}
 						versets[i].Vers[q] = system.PiiSystem.SecVerWithoutOpen(inputsets[i].Sigs[q])
 						//fmt.Println(system.PiiSystem.System.HalfOpenG(*versets[i].Vers[q]))
 						versets[i].PKshares[q] = inputsets[i].Sigs[q].Pkshare
@@ -50,17 +59,24 @@ func (system *PIISystem) verphase(inputsets []InputSet) []VerSet {
 		}
 	}
 	wg.Wait()
-	return versets
+	if ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+	return versets, nil
 }
 
-func (system *PIISystem) interphase(versets []VerSet, seedsets []SeedSet) []*big.Int {
+func (system *PIISystem) interphase(versets []VerSet, seedsets []SeedSet) ([]*big.Int, error) {
 	intersection := make([]*big.Int, 0)
 	interChan := make(chan *big.Int, 100)
+	done := make(chan struct{})
+	ctx, cancel := context.WithCancelCause(system.PiiSystem.System.Context())
+	defer cancel(nil)
 	var wg sync.WaitGroup
 	go func() {
 		for inter := range interChan {
 			intersection = append(intersection, inter)
 		}
+		close(done)
 	}()
 	if versets[0].inputsize*versets[1].inputsize <= 16384 {
 		for i := 0; i < versets[0].inputsize; i++ {
@@ -68,6 +84,10 @@ func (system *PIISystem) interphase(versets []VerSet, seedsets []SeedSet) []*big
 				wg.Add(1)
 				go func(i, j int) {
 					defer wg.Done()
+					defer mpc.Recover(cancel)
+					if ctx.Err() != nil {
+						return
+					}
 					w := system.PiiSystem.System.SecSub_G(*versets[0].PKshares[i], *versets[1].PKshares[j])
 					w = system.PiiSystem.System.SecAdd_G(*w, *versets[0].Vers[i])
 					w = system.PiiSystem.System.SecAdd_G(*w, *versets[1].Vers[j])
@@ -79,13 +99,13 @@ func (system *PIISystem) interphase(versets []VerSet, seedsets []SeedSet) []*big
 							if chkid {
 								interChan <- interidX
 							} else {
-								fmt.Println("chk error")
-								os.Exit(1)
+								cancel(&mpc.MacCheckError{Op: "OpenG", Party: 0, Element: i, Cheater: -1})
+								return
 							}
 						}
 					} else {
-						fmt.Println("chk error")
-						os.Exit(1)
+						cancel(&mpc.MacCheckError{Op: "OpenG", Party: 0, Element: i, Cheater: -1})
+						return
 					}
 				}(i, j)
 
@@ -96,7 +116,11 @@ func (system *PIISystem) interphase(versets []VerSet, seedsets []SeedSet) []*big
 			wg.Add(1)
 			go func(i int) {
 				defer wg.Done()
+				defer mpc.Recover(cancel)
 				for j := 0; j < versets[1].inputsize; j++ {
+					if ctx.Err() != nil {
+						return
+					}
 					w := system.PiiSystem.System.SecSub_G(*versets[0].PKshares[i], *versets[1].PKshares[j])
 					w = system.PiiSystem.System.SecAdd_G(*w, *versets[0].Vers[i])
 					w = system.PiiSystem.System.SecAdd_G(*w, *versets[1].Vers[j])
@@ -108,13 +132,13 @@ func (system *PIISystem) interphase(versets []VerSet, seedsets []SeedSet) []*big
 							if chkid {
 								interChan <- interidX
 							} else {
-								fmt.Println("chk error")
-								os.Exit(1)
+								cancel(&mpc.MacCheckError{Op: "OpenG", Party: 0, Element: i, Cheater: -1})
+								return
 							}
 						}
 					} else {
-						fmt.Println("chk error")
-						os.Exit(1)
+						cancel(&mpc.MacCheckError{Op: "OpenG", Party: 0, Element: i, Cheater: -1})
+						return
 					}
 				}
 			}(i)
@@ -122,20 +146,27 @@ func (system *PIISystem) interphase(versets []VerSet, seedsets []SeedSet) []*big
 	}
 	wg.Wait()
 	close(interChan) // Close the channel after all goroutines are done
-	return intersection
+	<-done
+	if ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+	return intersection, nil
 }
 
-func (system *PIISystem) twoPartyPiiRun(inputsets []InputSet, seedsets []SeedSet) []*big.Int {
-	fmt.Println("ver phase start")
+func (system *PIISystem) twoPartyPiiRun(inputsets []InputSet, seedsets []SeedSet) (*Result, error) {
+	result := new(Result)
 	vertime := time.Now()
-	versets := system.verphase(inputsets)
-	fmt.Println("ver time: ", time.Since(vertime))
-	fmt.Println("inter phase start")
+	versets, err := system.verphase(inputsets)
+	if err != nil {
+		return nil, err
+	}
+	result.VerTime = time.Since(vertime)
 	intertime := time.Now()
-	intersection := system.interphase(versets, seedsets)
-	fmt.Println("inter time: ", time.Since(intertime))
-	//fmt.Println("intersection:", intersection)
-	fmt.Printf("Offline Communication: %f MB\n", float64(system.PiiSystem.System.OfflineCom)/1024/1024)
-	fmt.Printf("Online Communication: %f MB\n", float64(system.PiiSystem.System.Com)/1024/1024)
-	return intersection
+	result.Intersection, err = system.interphase(versets, seedsets)
+	if err != nil {
+		return nil, err
+	}
+	result.InterTime = time.Since(intertime)
+	result.OfflineCom, result.OnlineCom = system.GetCommunication()
+	return result, nil
 }
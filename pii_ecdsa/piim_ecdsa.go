@@ -2,17 +2,20 @@ package pii_ecdsa
 
 import (
 	"bytes"
-	"fmt"
+	"context"
 	"math/big"
-	"os"
 	"sync"
 	"time"
+
+	"github.com/Oryx/mpc"
 )
 
 var zero = big.NewInt(0)
 
-func (system *PIISystem) interphase_m(versets []VerSet, seedsets *SeedSet) []*big.Int {
+func (system *PIISystem) interphase_m(versets []VerSet, seedsets *SeedSet) ([]*big.Int, error) {
 	intersection := make([]*big.Int, 0)
+	ctx, cancel := context.WithCancelCause(system.PiiSystem.System.Context())
+	defer cancel(nil)
 	var wg sync.WaitGroup
 	size_except_for_one := 0
 	for i := 1; i < system.partynum; i++ {
@@ -40,6 +43,9 @@ func (system *PIISystem) interphase_m(versets []VerSet, seedsets *SeedSet) []*bi
 			wg.Add(1)
 			go func(i, j int) {
 				defer wg.Done()
+				if ctx.Err() != nil {
+					return
+				}
 				chk := chkpool.Get().(*bool)
 				verx := verxpool.Get().(*big.Int)
 				very := verypool.Get().(*big.Int)
@@ -48,14 +54,17 @@ func (system *PIISystem) interphase_m(versets []VerSet, seedsets *SeedSet) []*bi
 				defer verypool.Put(very)
 				verx, _, *chk = system.PiiSystem.System.OpenG(*versets[i].Vers[j])
 				if !*chk {
-					fmt.Println("chk error")
-					os.Exit(1)
+					cancel(&mpc.MacCheckError{Op: "OpenG", Party: i, Element: j, Cheater: -1})
+					return
 				}
 				verres[i][j] = (verx.Cmp(zero) == 0)
 			}(i, j)
 		}
 	}
 	wg.Wait()
+	if ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
 	var rwMutex sync.RWMutex
 	for i := 0; i < versets[0].inputsize; i++ {
 		if !verres[0][i] {
@@ -64,9 +73,13 @@ func (system *PIISystem) interphase_m(versets []VerSet, seedsets *SeedSet) []*bi
 		wg.Add(1)
 		go func(i int) {
 			defer wg.Done()
+			defer mpc.Recover(cancel)
 			v := system.PiiSystem.System.SecSub(*versets[0].PKxshares[i], *versets[1].PKxshares[0])
 			t := 0
 			for j := 1; j < size_except_for_one; j++ {
+				if ctx.Err() != nil {
+					return
+				}
 				t = j
 				k := 1
 				for {
@@ -95,32 +108,37 @@ func (system *PIISystem) interphase_m(versets []VerSet, seedsets *SeedSet) []*bi
 						intersection = append(intersection, interid)
 						rwMutex.Unlock()
 					} else {
-						fmt.Println("chk error")
-						os.Exit(1)
+						cancel(&mpc.MacCheckError{Op: "OpenFp", Party: 0, Element: i, Cheater: -1})
+						return
 					}
 				}
 			} else {
-				fmt.Println("chk error")
-				os.Exit(1)
+				cancel(&mpc.MacCheckError{Op: "OpenG", Party: 0, Element: i, Cheater: -1})
+				return
 			}
 		}(i)
 	}
 	wg.Wait()
-
-	return intersection
+	if ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+	return intersection, nil
 }
 
-func (system *PIISystem) PartyPiiRun(inputsets []InputSet, seedsets SeedSet) []*big.Int {
-	fmt.Println("ver phase start")
+func (system *PIISystem) PartyPiiRun(inputsets []InputSet, seedsets SeedSet) (*Result, error) {
+	result := new(Result)
 	vertime := time.Now()
-	versets := system.verphase(inputsets)
-	fmt.Println("ver time: ", time.Since(vertime))
-	fmt.Println("inter phase start")
+	versets, err := system.verphase(inputsets)
+	if err != nil {
+		return nil, err
+	}
+	result.VerTime = time.Since(vertime)
 	intertime := time.Now()
-	intersection := system.interphase_m(versets, &seedsets)
-	fmt.Println("inter time: ", time.Since(intertime))
-	//fmt.Println("intersection:", intersection)
-	fmt.Printf("Offline Communication: %f MB\n", float64(system.PiiSystem.System.OfflineCom)/1024/1024)
-	fmt.Printf("Online Communication: %f MB\n", float64(system.PiiSystem.System.Com)/1024/1024)
-	return intersection
+	result.Intersection, err = system.interphase_m(versets, &seedsets)
+	if err != nil {
+		return nil, err
+	}
+	result.InterTime = time.Since(intertime)
+	result.OfflineCom, result.OnlineCom = system.GetCommunication()
+	return result, nil
 }
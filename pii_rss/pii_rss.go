@@ -0,0 +1,127 @@
+package pii_rss
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/Oryx/curve"
+	"github.com/Oryx/pii"
+	"github.com/Oryx/rss"
+)
+
+// backend runs the comparisons of pii on the replicated system.
+type backend struct {
+	*rss.ShareSystem
+}
+
+func (b backend) SecMul(shares1, shares2 []rss.Share_Fp) (*[]rss.Share_Fp, error) {
+	return b.ShareSystem.SecMul(shares1, shares2), nil
+}
+
+// EXP_S_GT panics with a *rss.MismatchError when an opening fails, which
+// pii turns into the error of the run.
+func (b backend) EXP_S_GT(hshares []rss.Share_GT, xshares []rss.Share_Fp) (*[]rss.Share_GT, error) {
+	return b.ShareSystem.EXP_S_GT(hshares, xshares), nil
+}
+
+func (b backend) NewBatch() pii.Batch[rss.Share_Fp, rss.Share_GT] {
+	return &batch{system: b.ShareSystem}
+}
+
+// batch opens right away and remembers whether any party received two
+// different copies of a component, which Check then reports.
+type batch struct {
+	system *rss.ShareSystem
+	mu     sync.Mutex
+	failed bool
+}
+
+func (b *batch) record(chk bool) {
+	if !chk {
+		b.mu.Lock()
+		b.failed = true
+		b.mu.Unlock()
+	}
+}
+
+func (b *batch) OpenFp(party, element int, shares []rss.Share_Fp) (*big.Int, error) {
+	value, chk := b.system.OpenFp(shares)
+	b.record(chk)
+	return value, nil
+}
+
+func (b *batch) OpenGT(party, element int, shares []rss.Share_GT) (*curve.GT, error) {
+	value, chk := b.system.OpenGT(shares)
+	b.record(chk)
+	return value, nil
+}
+
+// Check returns a *rss.MismatchError if an opening failed.
+func (b *batch) Check(op string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failed {
+		return &rss.MismatchError{Op: op, Party: -1}
+	}
+	return nil
+}
+
+func (system *PIISystem) verphase(inputsets []InputSet) []VerSet {
+	var wg sync.WaitGroup
+	versets := make([]VerSet, len(inputsets))
+	for i := range inputsets {
+		versets[i].Vers = make([](*[]rss.Share_GT), inputsets[i].inputsize)
+		versets[i].HIDs = make([](*[]rss.Share_Fp), inputsets[i].inputsize)
+		for q := 0; q < inputsets[i].inputsize; q++ {
+			wg.Add(1)
+			go func(i, q int) {
+				defer wg.Done()
+				versets[i].Vers[q] = system.SecVerWithoutOpen(inputsets[i].Sigs[q])
+				versets[i].HIDs[q] = inputsets[i].Sigs[q].HID
+			}(i, q)
+		}
+	}
+	wg.Wait()
+	return versets
+}
+
+// twoPartyPiiRun compares every pair of the two input parties with
+// pii.Interphase. A party that sends a wrong copy stops the run with a
+// *rss.MismatchError before any identity is opened.
+func (system *PIISystem) twoPartyPiiRun(inputsets []InputSet, seedsets []SeedSet) (*Result, error) {
+	var err error
+	result := new(Result)
+	vertime := time.Now()
+	versets := system.verphase(inputsets)
+	result.VerTime = time.Since(vertime)
+	intertime := time.Now()
+	seeds := make([][]*[]rss.Share_Fp, len(seedsets))
+	for i := range seedsets {
+		seeds[i] = seedsets[i].Seeds
+	}
+	result.Intersection, err = pii.Interphase[rss.Share_Fp, rss.Share_GT](backend{system.System}, system.MK.G, system.IdentityGTbytes, versets, seeds)
+	if err != nil {
+		return nil, err
+	}
+	result.InterTime = time.Since(intertime)
+	result.OfflineCom, result.OnlineCom = system.GetCommunication()
+	return result, nil
+}
+
+// PartyPiiRun runs pii.Interphase_m for all three parties.
+func (system *PIISystem) PartyPiiRun(inputsets []InputSet, seedsets SeedSet) (*Result, error) {
+	var err error
+	result := new(Result)
+	vertime := time.Now()
+	versets := system.verphase(inputsets)
+	result.VerTime = time.Since(vertime)
+	intertime := time.Now()
+	result.Intersection, err = pii.Interphase_m[rss.Share_Fp, rss.Share_GT](backend{system.System}, system.MK.G, system.IdentityGTbytes, versets, seedsets.Seeds)
+	if err != nil {
+		return nil, err
+	}
+	result.InterTime = time.Since(intertime)
+	result.OfflineCom, result.OnlineCom = system.GetCommunication()
+	return result, nil
+}
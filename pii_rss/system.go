@@ -0,0 +1,225 @@
+package pii_rss
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	ibs "github.com/Oryx/IBS"
+	"github.com/Oryx/curve"
+	"github.com/Oryx/pii"
+	"github.com/Oryx/rss"
+)
+
+var ErrParties = errors.New("pii_rss: need two or three input parties")
+
+// PIISystem runs PII on the 3-party replicated system. The three parties
+// compute; two or three of them bring inputs.
+type PIISystem struct {
+	System          *rss.ShareSystem
+	MK              *ibs.MasterKey
+	IdentityGTbytes []byte
+	mpkshare        *[]rss.Share_G2
+	partynum        int
+}
+
+// Share_Sig is an IBS signature whose hashes are shared; S stays public as
+// in ibs.Share_Sig.
+type Share_Sig struct {
+	S   ibs.Sig
+	HM  *[]rss.Share_Fp
+	HID *[]rss.Share_Fp
+	HS1 *[]rss.Share_Fp
+}
+
+type InputSet struct {
+	Sigs       []*Share_Sig
+	Partyindex int
+	inputsize  int
+}
+
+type VerSet = pii.Verified[rss.Share_Fp, rss.Share_GT]
+
+type SeedSet struct {
+	Seeds      [](*[]rss.Share_Fp)
+	Partyindex int
+}
+
+// Result is the outcome of one run. OfflineCom and OnlineCom are the
+// communication of the system so far in MB, including the input sharing.
+type Result struct {
+	Intersection []*big.Int
+	VerTime      time.Duration
+	InterTime    time.Duration
+	OfflineCom   float64
+	OnlineCom    float64
+}
+
+func (result *Result) print() {
+	fmt.Println("ver time:", result.VerTime)
+	fmt.Println("inter time:", result.InterTime)
+	fmt.Printf("Offline Communication: %f MB\n", result.OfflineCom)
+	fmt.Printf("Online Communication: %f MB\n", result.OnlineCom)
+}
+
+// PiiInitSystem returns ErrParties unless Partynum, the number of input
+// parties, is two or three.
+func PiiInitSystem(Partynum int, isWAN bool, bandwidth float64) (*PIISystem, error) {
+	if Partynum < 2 || Partynum > rss.Partynum {
+		return nil, ErrParties
+	}
+	piisystem := new(PIISystem)
+	if isWAN {
+		system, err := rss.SystemInitWAN(bandwidth)
+		if err != nil {
+			return nil, err
+		}
+		piisystem.System = system
+	} else {
+		piisystem.System = rss.SystemInit()
+	}
+	piisystem.MK = ibs.MasterKeyGen()
+	piisystem.mpkshare = piisystem.System.Share_A_G2(piisystem.MK.Mpk)
+	piisystem.IdentityGTbytes = piisystem.System.IdentityGT.Marshal()
+	piisystem.partynum = Partynum
+	return piisystem, nil
+}
+
+func (system *PIISystem) Share_A_Sig(sig ibs.Sig, msg []byte, id *big.Int) *Share_Sig {
+	share_sig := new(Share_Sig)
+	share_sig.S = sig
+	share_sig.HM = system.System.Share_An_Fp_Offline(ibs.H1(msg))
+	share_sig.HID = system.System.Share_An_Fp_Offline(ibs.H2(id))
+	share_sig.HS1 = system.System.Share_An_Fp_Offline(ibs.H3(sig.S1))
+	return share_sig
+}
+
+// SecVerWithoutOpen is ibs.SecureVer.SecVerWithoutOpen on replicated shares:
+// the result is a sharing of the identity exactly when the signature is
+// valid.
+func (system *PIISystem) SecVerWithoutOpen(sigshares *Share_Sig) *[]rss.Share_GT {
+	g2hidshares := system.System.EXP_P_G2_1(curve.Gen2, sigshares.HID)
+	mpkg2hidshares := system.System.SecAdd_G2(*g2hidshares, *system.mpkshare)
+	wshares := system.System.Pair_P_2(sigshares.S.S2, mpkg2hidshares)
+	hidaddhs1shares := system.System.SecAdd(*sigshares.HM, *sigshares.HS1)
+	hshares := system.System.EXP_P_GT_1(system.MK.G, hidaddhs1shares)
+	haddwshares := system.System.SecAdd_GT(*wshares, *hshares)
+	resshares := system.System.SecSubPlaintext_GT(*haddwshares, sigshares.S.S1)
+	return resshares
+}
+
+func (system *PIISystem) prepareseeds(inputsize []int) []SeedSet {
+	var wg sync.WaitGroup
+	seedsets := make([]SeedSet, inputsize[0])
+	for i := 0; i < inputsize[0]; i++ {
+		seedsets[i].Seeds = make([](*[]rss.Share_Fp), inputsize[1])
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < inputsize[1]; j++ {
+				seedsets[i].Seeds[j] = system.System.RandomShareFp()
+			}
+		}(i)
+	}
+	wg.Wait()
+	return seedsets
+}
+
+func (system *PIISystem) prepareseeds_m(inputsize []int) *SeedSet {
+	seedsets := new(SeedSet)
+	seedsets.Seeds = make([]*[]rss.Share_Fp, inputsize[0])
+	for i := 0; i < inputsize[0]; i++ {
+		seedsets.Seeds[i] = system.System.RandomShareFp()
+	}
+	return seedsets
+}
+
+func (system *PIISystem) prepareinput(idset []pii.IDSet) []InputSet {
+	var wg sync.WaitGroup
+	sigsets := make([]InputSet, system.partynum)
+	for i := 0; i < system.partynum; i++ {
+		sigsets[i].Sigs = make([]*Share_Sig, len(idset[i].IDs))
+		sigsets[i].inputsize = len(idset[i].IDs)
+		sigsets[i].Partyindex = i
+		for q := range idset[i].IDs {
+			wg.Add(1)
+			go func(i, q int) {
+				defer wg.Done()
+				uk := ibs.UserKeyGen(system.MK, idset[i].IDs[q])
+				mbytes := idset[i].IDs[q].Bytes()
+				sig := ibs.Sign(uk, &system.MK.MasterPubKey, mbytes)
+				sigsets[i].Sigs[q] = system.Share_A_Sig(*sig, mbytes, idset[i].IDs[q])
+			}(i, q)
+		}
+	}
+	wg.Wait()
+	return sigsets
+}
+
+func (system *PIISystem) PrepareData(intersize int, inputsize []int) ([]InputSet, []SeedSet) {
+	idsets := pii.RandomIDSets(intersize, inputsize)
+	seedsets := system.prepareseeds(inputsize)
+	privatesets := system.prepareinput(idsets)
+	return privatesets, seedsets
+}
+
+func (system *PIISystem) PrepareData_m(intersize int, inputsize []int) ([]InputSet, *SeedSet) {
+	idsets := pii.RandomIDSets(intersize, inputsize)
+	seedsets := system.prepareseeds_m(inputsize)
+	privatesets := system.prepareinput(idsets)
+	return privatesets, seedsets
+}
+
+func (system *PIISystem) Run(inputsets []InputSet, seedset []SeedSet) (*Result, error) {
+	return system.twoPartyPiiRun(inputsets, seedset)
+}
+
+func (system *PIISystem) Run_m(inputsets []InputSet, seedset *SeedSet) (*Result, error) {
+	return system.PartyPiiRun(inputsets, *seedset)
+}
+
+func (system *PIISystem) GetCommunication() (float64, float64) {
+	return float64(system.System.OfflineCom) / 1024 / 1024, float64(system.System.Com) / 1024 / 1024
+}
+
+// PIIProtocol runs PII with two or three input parties on the replicated
+// system. It returns a *rss.MismatchError when an opening fails, ErrParties
+// for any other number of parties, and rss.ErrBandwidth for a WAN run
+// without a positive bandwidth.
+func PIIProtocol(intersize int, inputsize []int, isWAN bool, bandwidth float64) (*PIISystem, error) {
+	partynum := len(inputsize)
+	piisystem, err := PiiInitSystem(partynum, isWAN, bandwidth)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("n = %d (replicated, 3 computing parties)\n", partynum)
+	if isWAN {
+		fmt.Printf("Network Mode: WAN\n")
+		fmt.Printf("Bandwidth: %.2f Mbps\n", bandwidth)
+	} else {
+		fmt.Printf("Network Mode: LAN\n")
+	}
+	fmt.Printf("Input Sizes: %v\n", inputsize)
+	if partynum == 2 {
+		timepoint := time.Now()
+		privatesets, seedsets := piisystem.PrepareData(intersize, inputsize)
+		fmt.Println("Data Preparation Time:", time.Since(timepoint))
+		result, err := piisystem.Run(privatesets, seedsets)
+		if err != nil {
+			return piisystem, err
+		}
+		result.print()
+	} else {
+		timepoint := time.Now()
+		privatesets, seedsets := piisystem.PrepareData_m(intersize, inputsize)
+		fmt.Println("Data Preparation Time:", time.Since(timepoint))
+		result, err := piisystem.Run_m(privatesets, seedsets)
+		if err != nil {
+			return piisystem, err
+		}
+		result.print()
+	}
+	return piisystem, nil
+}
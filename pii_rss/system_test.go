@@ -0,0 +1,88 @@
+package pii_rss
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	ibs "github.com/Oryx/IBS"
+	"github.com/Oryx/pii"
+)
+
+// sameSet reports whether got holds the hashes, as the intersection opens
+// them, of the identities in want.
+func sameSet(got, want []*big.Int) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	seen := make(map[string]int)
+	for _, id := range want {
+		seen[ibs.H2(id).String()]++
+	}
+	for _, id := range got {
+		if seen[id.String()] == 0 {
+			return false
+		}
+		seen[id.String()]--
+	}
+	return true
+}
+
+func TestRun(t *testing.T) {
+	for _, tc := range []struct {
+		intersize int
+		inputsize []int
+		forged    bool
+	}{
+		{0, []int{3, 3}, false},
+		{2, []int{2, 2}, false},
+		{2, []int{4, 3}, false},
+		// the first common identity of party 1 carries an invalid signature
+		{2, []int{3, 3}, true},
+	} {
+		system, err := PiiInitSystem(2, false, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		idsets := pii.RandomIDSets(tc.intersize, tc.inputsize)
+		inputsets := system.prepareinput(idsets)
+		want := idsets[0].IDs[:tc.intersize]
+		if tc.forged {
+			inputsets[1].Sigs[0].HS1 = system.System.Share_An_Fp_Offline(big.NewInt(1))
+			want = want[1:]
+		}
+		result, err := system.Run(inputsets, system.prepareseeds(tc.inputsize))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sameSet(result.Intersection, want) {
+			t.Fatalf("got %v for %v, want %v", result.Intersection, tc.inputsize, want)
+		}
+	}
+}
+
+func TestRun_m(t *testing.T) {
+	for _, intersize := range []int{0, 1, 3} {
+		inputsize := []int{3, 4, 3}
+		system, err := PiiInitSystem(len(inputsize), false, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		idsets := pii.RandomIDSets(intersize, inputsize)
+		result, err := system.Run_m(system.prepareinput(idsets), system.prepareseeds_m(inputsize))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := idsets[0].IDs[:intersize]; !sameSet(result.Intersection, want) {
+			t.Fatalf("got %v, want %v", result.Intersection, want)
+		}
+	}
+}
+
+func TestPiiInitSystemParties(t *testing.T) {
+	for _, n := range []int{1, 4} {
+		if _, err := PiiInitSystem(n, false, 0); !errors.Is(err, ErrParties) {
+			t.Fatalf("got %v for %d parties, want %v", err, n, ErrParties)
+		}
+	}
+}
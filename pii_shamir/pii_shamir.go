@@ -0,0 +1,94 @@
+package pii_shamir
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/Oryx/curve"
+	"github.com/Oryx/pii"
+	"github.com/Oryx/shamir"
+)
+
+// backend runs the comparisons of pii on shamir.
+type backend struct {
+	*shamir.ShareSystem
+}
+
+func (b backend) NewBatch() pii.Batch[shamir.Share_Fp, shamir.Share_GT] {
+	return batch{b.ShareSystem}
+}
+
+// batch opens right away. Without MACs there is nothing to check
+// afterwards; an opening fails only when too many parties dropped out.
+type batch struct {
+	system *shamir.ShareSystem
+}
+
+func (b batch) OpenFp(party, element int, shares []shamir.Share_Fp) (*big.Int, error) {
+	return b.system.OpenFp(shares)
+}
+
+func (b batch) OpenGT(party, element int, shares []shamir.Share_GT) (*curve.GT, error) {
+	return b.system.OpenGT(shares)
+}
+
+func (b batch) Check(op string) error {
+	return nil
+}
+
+// verphase is local, so it needs no party to be online.
+func (system *PIISystem) verphase(inputsets []InputSet) []VerSet {
+	var wg sync.WaitGroup
+	versets := make([]VerSet, len(inputsets))
+	for i := range inputsets {
+		versets[i].Vers = make([](*[]shamir.Share_GT), inputsets[i].inputsize)
+		versets[i].HIDs = make([](*[]shamir.Share_Fp), inputsets[i].inputsize)
+		for q := 0; q < inputsets[i].inputsize; q++ {
+			wg.Add(1)
+			go func(i, q int) {
+				defer wg.Done()
+				versets[i].Vers[q] = system.PiiSystem.SecVerWithoutOpen(inputsets[i].Sigs[q])
+				versets[i].HIDs[q] = inputsets[i].Sigs[q].HID
+			}(i, q)
+		}
+	}
+	wg.Wait()
+	return versets
+}
+
+func (system *PIISystem) twoPartyPiiRun(inputsets []InputSet, seedsets []SeedSet) (*Result, error) {
+	var err error
+	result := new(Result)
+	vertime := time.Now()
+	versets := system.verphase(inputsets)
+	result.VerTime = time.Since(vertime)
+	intertime := time.Now()
+	seeds := make([][]*[]shamir.Share_Fp, len(seedsets))
+	for i := range seedsets {
+		seeds[i] = seedsets[i].Seeds
+	}
+	result.Intersection, err = pii.Interphase[shamir.Share_Fp, shamir.Share_GT](backend{system.PiiSystem.System}, system.MK.G, system.PiiSystem.IdentityGTbytes, versets, seeds)
+	if err != nil {
+		return nil, err
+	}
+	result.InterTime = time.Since(intertime)
+	result.OfflineCom, result.OnlineCom = system.GetCommunication()
+	return result, nil
+}
+
+func (system *PIISystem) PartyPiiRun(inputsets []InputSet, seedsets SeedSet) (*Result, error) {
+	var err error
+	result := new(Result)
+	vertime := time.Now()
+	versets := system.verphase(inputsets)
+	result.VerTime = time.Since(vertime)
+	intertime := time.Now()
+	result.Intersection, err = pii.Interphase_m[shamir.Share_Fp, shamir.Share_GT](backend{system.PiiSystem.System}, system.MK.G, system.PiiSystem.IdentityGTbytes, versets, seedsets.Seeds)
+	if err != nil {
+		return nil, err
+	}
+	result.InterTime = time.Since(intertime)
+	result.OfflineCom, result.OnlineCom = system.GetCommunication()
+	return result, nil
+}
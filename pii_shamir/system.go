@@ -0,0 +1,216 @@
+package pii_shamir
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	ibs "github.com/Oryx/IBS"
+	"github.com/Oryx/pii"
+	"github.com/Oryx/shamir"
+)
+
+var ErrParties = errors.New("pii_shamir: need two input parties or more, and at most one per computing party")
+
+// PIISystem runs semi-honest PII on the t-out-of-n system of shamir. Parties
+// taken offline with PiiSystem.System.Drop after their inputs were shared
+// keep their elements in the run.
+type PIISystem struct {
+	PiiSystem *ibs.ShamirVer
+	MK        *ibs.MasterKey
+	partynum  int
+}
+
+type InputSet struct {
+	Sigs       []*ibs.Share_Sig_Shamir
+	Partyindex int
+	inputsize  int
+}
+
+type VerSet = pii.Verified[shamir.Share_Fp, shamir.Share_GT]
+
+type SeedSet struct {
+	Seeds      [](*[]shamir.Share_Fp)
+	Partyindex int
+}
+
+// Result is the outcome of one run. OfflineCom and OnlineCom are the
+// communication of the system so far in MB, including the input sharing.
+type Result struct {
+	Intersection []*big.Int
+	VerTime      time.Duration
+	InterTime    time.Duration
+	OfflineCom   float64
+	OnlineCom    float64
+}
+
+func (result *Result) print() {
+	fmt.Println("ver time:", result.VerTime)
+	fmt.Println("inter time:", result.InterTime)
+	fmt.Printf("Offline Communication: %f MB\n", result.OfflineCom)
+	fmt.Printf("Online Communication: %f MB\n", result.OnlineCom)
+}
+
+// PiiInitSystem sets up Partynum computing parties, of which any Threshold
+// learn nothing. It returns shamir.ErrThreshold unless 1 <= Threshold <
+// Partynum, and shamir.ErrBandwidth for a WAN system without a positive
+// bandwidth.
+func PiiInitSystem(Partynum, Threshold int, isWAN bool, bandwidth float64) (*PIISystem, error) {
+	piisystem := new(PIISystem)
+	piisystem.MK = ibs.MasterKeyGen()
+	securever, err := ibs.ShamirVerInit(Partynum, Threshold, &piisystem.MK.MasterPubKey, isWAN, bandwidth)
+	if err != nil {
+		return nil, err
+	}
+	piisystem.PiiSystem = securever
+	piisystem.partynum = Partynum
+	return piisystem, nil
+}
+
+func (system *PIISystem) prepareseeds(inputsize []int) []SeedSet {
+	var wg sync.WaitGroup
+	seedsets := make([]SeedSet, inputsize[0])
+	for i := 0; i < inputsize[0]; i++ {
+		seedsets[i].Seeds = make([](*[]shamir.Share_Fp), inputsize[1])
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < inputsize[1]; j++ {
+				seedsets[i].Seeds[j] = system.PiiSystem.System.RandomShareFp()
+			}
+		}(i)
+	}
+	wg.Wait()
+	return seedsets
+}
+
+func (system *PIISystem) prepareseeds_m(inputsize []int) *SeedSet {
+	seedsets := new(SeedSet)
+	seedsets.Seeds = make([]*[]shamir.Share_Fp, inputsize[0])
+	for i := 0; i < inputsize[0]; i++ {
+		seedsets.Seeds[i] = system.PiiSystem.System.RandomShareFp()
+	}
+	return seedsets
+}
+
+func (system *PIISystem) prepareinput(idsets []pii.IDSet) []InputSet {
+	var wg sync.WaitGroup
+	sigsets := make([]InputSet, len(idsets))
+	for i := range idsets {
+		sigsets[i].Sigs = make([]*ibs.Share_Sig_Shamir, len(idsets[i].IDs))
+		sigsets[i].inputsize = len(idsets[i].IDs)
+		sigsets[i].Partyindex = i
+		for q := range idsets[i].IDs {
+			wg.Add(1)
+			go func(i, q int) {
+				defer wg.Done()
+				id := idsets[i].IDs[q]
+				uk := ibs.UserKeyGen(system.MK, id)
+				sig := ibs.Sign(uk, &system.MK.MasterPubKey, id.Bytes())
+				sigsets[i].Sigs[q] = system.PiiSystem.Share_A_Sig(*sig, id.Bytes(), id)
+			}(i, q)
+		}
+	}
+	wg.Wait()
+	return sigsets
+}
+
+// PrepareData returns ErrParties unless inputsize has two entries.
+func (system *PIISystem) PrepareData(intersize int, inputsize []int) ([]InputSet, []SeedSet, error) {
+	if len(inputsize) != 2 {
+		return nil, nil, ErrParties
+	}
+	privatesets := system.prepareinput(pii.RandomIDSets(intersize, inputsize))
+	return privatesets, system.prepareseeds(inputsize), nil
+}
+
+// PrepareData_m returns ErrParties unless inputsize has between two and
+// Partynum entries.
+func (system *PIISystem) PrepareData_m(intersize int, inputsize []int) ([]InputSet, *SeedSet, error) {
+	if len(inputsize) < 2 || len(inputsize) > system.partynum {
+		return nil, nil, ErrParties
+	}
+	privatesets := system.prepareinput(pii.RandomIDSets(intersize, inputsize))
+	return privatesets, system.prepareseeds_m(inputsize), nil
+}
+
+// Run returns shamir.ErrDropouts when fewer than Threshold+1 parties are
+// online.
+func (system *PIISystem) Run(inputsets []InputSet, seedset []SeedSet) (*Result, error) {
+	if len(inputsets) != 2 {
+		return nil, ErrParties
+	}
+	return system.twoPartyPiiRun(inputsets, seedset)
+}
+
+// Run_m multiplies, so it returns shamir.ErrDropouts when fewer than
+// 2*Threshold+1 parties are online.
+func (system *PIISystem) Run_m(inputsets []InputSet, seedset *SeedSet) (*Result, error) {
+	if len(inputsets) < 2 || len(inputsets) > system.partynum {
+		return nil, ErrParties
+	}
+	return system.PartyPiiRun(inputsets, *seedset)
+}
+
+func (system *PIISystem) GetCommunication() (float64, float64) {
+	return float64(system.PiiSystem.System.OfflineCom) / 1024 / 1024, float64(system.PiiSystem.System.Com) / 1024 / 1024
+}
+
+// PIIProtocol runs PII with one input party per computing party, any
+// threshold of which learn nothing. The last dropouts parties go offline
+// after the inputs are shared. It returns shamir.ErrDropouts when too few
+// parties remain, shamir.ErrThreshold for an invalid threshold and
+// shamir.ErrBandwidth for a WAN run without a positive bandwidth.
+func PIIProtocol(intersize int, inputsize []int, threshold, dropouts int, isWAN bool, bandwidth float64) (*PIISystem, error) {
+	partynum := len(inputsize)
+	piisystem, err := PiiInitSystem(partynum, threshold, isWAN, bandwidth)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("n = %d, t = %d, %d dropped out\n", partynum, threshold, dropouts)
+	if isWAN {
+		fmt.Printf("Network Mode: WAN\n")
+		fmt.Printf("Bandwidth: %.2f Mbps\n", bandwidth)
+	} else {
+		fmt.Printf("Network Mode: LAN\n")
+	}
+	fmt.Printf("Input Sizes: %v\n", inputsize)
+	timepoint := time.Now()
+	var result *Result
+	if partynum == 2 {
+		privatesets, seedsets, err := piisystem.PrepareData(intersize, inputsize)
+		if err != nil {
+			return piisystem, err
+		}
+		fmt.Println("Data Preparation Time:", time.Since(timepoint))
+		piisystem.drop(dropouts)
+		result, err = piisystem.Run(privatesets, seedsets)
+		if err != nil {
+			return piisystem, err
+		}
+	} else {
+		privatesets, seedsets, err := piisystem.PrepareData_m(intersize, inputsize)
+		if err != nil {
+			return piisystem, err
+		}
+		fmt.Println("Data Preparation Time:", time.Since(timepoint))
+		piisystem.drop(dropouts)
+		result, err = piisystem.Run_m(privatesets, seedsets)
+		if err != nil {
+			return piisystem, err
+		}
+	}
+	result.print()
+	return piisystem, nil
+}
+
+// drop takes the last dropouts parties offline.
+func (system *PIISystem) drop(dropouts int) {
+	for i := system.partynum - dropouts; i < system.partynum; i++ {
+		if i >= 0 {
+			system.PiiSystem.System.Drop(i)
+		}
+	}
+}
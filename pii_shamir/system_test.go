@@ -0,0 +1,101 @@
+package pii_shamir
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	ibs "github.com/Oryx/IBS"
+	"github.com/Oryx/pii"
+	"github.com/Oryx/shamir"
+)
+
+// sameSet reports whether got holds the hashes, as the intersection opens
+// them, of the identities in want.
+func sameSet(got, want []*big.Int) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	seen := make(map[string]int)
+	for _, id := range want {
+		seen[ibs.H2(id).String()]++
+	}
+	for _, id := range got {
+		if seen[id.String()] == 0 {
+			return false
+		}
+		seen[id.String()]--
+	}
+	return true
+}
+
+func TestRun(t *testing.T) {
+	for _, tc := range []struct {
+		intersize int
+		inputsize []int
+	}{
+		{0, []int{3, 3}},
+		{2, []int{2, 2}},
+		{2, []int{4, 3}},
+	} {
+		system, err := PiiInitSystem(2, 1, false, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		idsets := pii.RandomIDSets(tc.intersize, tc.inputsize)
+		result, err := system.Run(system.prepareinput(idsets), system.prepareseeds(tc.inputsize))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := idsets[0].IDs[:tc.intersize]; !sameSet(result.Intersection, want) {
+			t.Fatalf("got %v for %v, want %v", result.Intersection, tc.inputsize, want)
+		}
+	}
+}
+
+func TestRun_m(t *testing.T) {
+	for _, tc := range []struct {
+		threshold, dropouts int
+		inputsize           []int
+		want                error
+	}{
+		{1, 0, []int{3, 3, 3}, nil},
+		{2, 0, []int{2, 3, 2, 2, 3}, nil},
+		// exactly 2*Threshold+1 parties online
+		{1, 2, []int{2, 3, 2, 2, 3}, nil},
+		{1, 1, []int{3, 3, 3}, shamir.ErrDropouts},
+	} {
+		partynum := len(tc.inputsize)
+		system, err := PiiInitSystem(partynum, tc.threshold, false, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		idsets := pii.RandomIDSets(2, tc.inputsize)
+		inputsets := system.prepareinput(idsets)
+		seedset := system.prepareseeds_m(tc.inputsize)
+		system.drop(tc.dropouts)
+		result, err := system.Run_m(inputsets, seedset)
+		if !errors.Is(err, tc.want) {
+			t.Fatalf("got %v for n = %d, t = %d, %d dropped, want %v", err, partynum, tc.threshold, tc.dropouts, tc.want)
+		}
+		if err != nil {
+			continue
+		}
+		if want := idsets[0].IDs[:2]; !sameSet(result.Intersection, want) {
+			t.Fatalf("got %v for n = %d, want %v", result.Intersection, partynum, want)
+		}
+	}
+}
+
+func TestPrepareDataParties(t *testing.T) {
+	system, err := PiiInitSystem(3, 1, false, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := system.PrepareData(1, []int{2, 2, 2}); !errors.Is(err, ErrParties) {
+		t.Fatalf("got %v, want %v", err, ErrParties)
+	}
+	if _, _, err := system.PrepareData_m(1, []int{2, 2, 2, 2}); !errors.Is(err, ErrParties) {
+		t.Fatalf("got %v, want %v", err, ErrParties)
+	}
+}
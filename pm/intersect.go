@@ -0,0 +1,68 @@
+package pm
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/Oryx/mpc"
+)
+
+var (
+	ErrID        = errors.New("pm: id out of range")
+	ErrParties   = errors.New("pm: need one input set per party")
+	ErrDuplicate = errors.New("pm: duplicate id in an input set")
+)
+
+// Result is the outcome of one run. OfflineCom and OnlineCom are the
+// communication of the system so far in MB, including the input sharing.
+type Result struct {
+	Intersection []*big.Int
+	InterTime    time.Duration
+	OfflineCom   float64
+	OnlineCom    float64
+}
+
+func (result *Result) print() {
+	fmt.Println("inter time:", result.InterTime)
+	fmt.Println("intersection:", result.Intersection)
+	fmt.Printf("Offline Communication: %f MB\n", result.OfflineCom)
+	fmt.Printf("Online Communication: %f MB\n", result.OnlineCom)
+}
+
+// ShareInput secret-shares the ids of one party. Each id must lie in
+// [0, Order) and appear only once.
+func (system *PMSystem) ShareInput(partyindex int, ids []*big.Int) (InputSet, error) {
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if id == nil || id.Sign() < 0 || id.Cmp(system.System.Order) >= 0 {
+			return InputSet{}, ErrID
+		}
+		if seen[id.String()] {
+			return InputSet{}, ErrDuplicate
+		}
+		seen[id.String()] = true
+	}
+	var inputset InputSet
+	inputset.HIDs = make([](*[]mpc.Share_Fp), len(ids))
+	for q, id := range ids {
+		inputset.HIDs[q] = system.System.Share_An_Fp_Offline(id)
+	}
+	inputset.Partyindex = partyindex
+	inputset.inputsize = len(ids)
+	return inputset, nil
+}
+
+// Intersect runs PM on the input sets of all parties, in party order, and
+// returns the ids of party 0 that are in the intersection. mode has the same
+// meaning as in PMProtocol.
+func (system *PMSystem) Intersect(inputsets []InputSet, mode int) (*Result, error) {
+	if len(inputsets) != system.System.Partynum {
+		return nil, ErrParties
+	}
+	if mode == 0 && system.System.Partynum == 2 {
+		return system.run(inputsets, nil)
+	}
+	return system.run_m(inputsets, nil)
+}
@@ -1,21 +1,26 @@
 package pm
 
 import (
-	"fmt"
+	"context"
 	"math/big"
-	"os"
 	"sync"
 	"time"
+
+	"github.com/Oryx/mpc"
 )
 
-func (system *PMSystem) interphase(versets []InputSet, seedsets []SeedSet) []*big.Int {
+func (system *PMSystem) interphase(versets []InputSet, seedsets []SeedSet) ([]*big.Int, error) {
 	intersection := make([]*big.Int, 0)
 	interChan := make(chan *big.Int, 100)
+	done := make(chan struct{})
+	ctx, cancel := context.WithCancelCause(system.System.Context())
+	defer cancel(nil)
 	var wg sync.WaitGroup
 	go func() {
 		for inter := range interChan {
 			intersection = append(intersection, inter)
 		}
+		close(done)
 	}()
 	if versets[0].inputsize*versets[1].inputsize <= 16384 {
 		for i := 0; i < versets[0].inputsize; i++ {
@@ -23,6 +28,10 @@ func (system *PMSystem) interphase(versets []InputSet, seedsets []SeedSet) []*bi
 				wg.Add(1)
 				go func(i, j int) {
 					defer wg.Done()
+					defer mpc.Recover(cancel)
+					if ctx.Err() != nil {
+						return
+					}
 					v := system.System.SecSub(*versets[0].HIDs[i], *versets[1].HIDs[j])
 					v = system.System.SecMul(*v, *seedsets[i].Seeds[j])
 					vvalue, chk := system.System.OpenFp(*v)
@@ -32,13 +41,11 @@ func (system *PMSystem) interphase(versets []InputSet, seedsets []SeedSet) []*bi
 							if chkid {
 								interChan <- interid
 							} else {
-								fmt.Println("chk error")
-								os.Exit(1)
+								cancel(&mpc.MacCheckError{Op: "OpenFp", Party: 0, Element: i, Cheater: system.System.IdentifyFp(*versets[0].HIDs[i])})
 							}
 						}
 					} else {
-						fmt.Println("chk error")
-						os.Exit(1)
+						cancel(&mpc.MacCheckError{Op: "OpenFp", Party: 0, Element: i, Cheater: system.System.IdentifyFp(*v)})
 					}
 				}(i, j)
 
@@ -49,7 +56,11 @@ func (system *PMSystem) interphase(versets []InputSet, seedsets []SeedSet) []*bi
 			wg.Add(1)
 			go func(i int) {
 				defer wg.Done()
+				defer mpc.Recover(cancel)
 				for j := 0; j < versets[1].inputsize; j++ {
+					if ctx.Err() != nil {
+						return
+					}
 					v := system.System.SecSub(*versets[0].HIDs[i], *versets[1].HIDs[j])
 					v = system.System.SecMul(*v, *seedsets[i].Seeds[j])
 					vvalue, chk := system.System.OpenFp(*v)
@@ -59,13 +70,13 @@ func (system *PMSystem) interphase(versets []InputSet, seedsets []SeedSet) []*bi
 							if chkid {
 								interChan <- interid
 							} else {
-								fmt.Println("chk error")
-								os.Exit(1)
+								cancel(&mpc.MacCheckError{Op: "OpenFp", Party: 0, Element: i, Cheater: system.System.IdentifyFp(*versets[0].HIDs[i])})
+								return
 							}
 						}
 					} else {
-						fmt.Println("chk error")
-						os.Exit(1)
+						cancel(&mpc.MacCheckError{Op: "OpenFp", Party: 0, Element: i, Cheater: system.System.IdentifyFp(*v)})
+						return
 					}
 				}
 			}(i)
@@ -73,16 +84,22 @@ func (system *PMSystem) interphase(versets []InputSet, seedsets []SeedSet) []*bi
 	}
 	wg.Wait()
 	close(interChan) // Close the channel after all goroutines are done
-	return intersection
+	<-done
+	if ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+	return intersection, nil
 }
 
-func (system *PMSystem) twoPartyRun(inputsets []InputSet, seedsets []SeedSet) []*big.Int {
-	fmt.Println("inter phase start")
+func (system *PMSystem) twoPartyRun(inputsets []InputSet, seedsets []SeedSet) (*Result, error) {
+	result := new(Result)
 	intertime := time.Now()
-	intersection := system.interphase(inputsets, seedsets)
-	fmt.Println("inter time:", time.Since(intertime))
-	fmt.Println("intersection:", intersection)
-	fmt.Printf("Offline Communication: %f MB\n", float64(system.System.OfflineCom)/1024/1024)
-	fmt.Printf("Online Communication: %f MB\n", float64(system.System.Com)/1024/1024)
-	return intersection
+	intersection, err := system.interphase(inputsets, seedsets)
+	if err != nil {
+		return nil, err
+	}
+	result.Intersection = intersection
+	result.InterTime = time.Since(intertime)
+	result.OfflineCom, result.OnlineCom = system.GetCommunication()
+	return result, nil
 }
@@ -1,21 +1,26 @@
 package pm
 
 import (
-	"fmt"
+	"context"
 	"math/big"
-	"os"
 	"sync"
 	"time"
+
+	"github.com/Oryx/mpc"
 )
 
-func (system *PMSystem) interphase_m(versets []InputSet, seedsets *SeedSet) []*big.Int {
+func (system *PMSystem) interphase_m(versets []InputSet, seedsets *SeedSet) ([]*big.Int, error) {
 	intersection := make([]*big.Int, 0)
 	interChan := make(chan *big.Int, 100)
+	done := make(chan struct{})
+	ctx, cancel := context.WithCancelCause(system.System.Context())
+	defer cancel(nil)
 	var wg sync.WaitGroup
 	go func() {
 		for inter := range interChan {
 			intersection = append(intersection, inter)
 		}
+		close(done)
 	}()
 	size_except_for_one := 0
 	for i := 1; i < system.System.Partynum; i++ {
@@ -25,9 +30,13 @@ func (system *PMSystem) interphase_m(versets []InputSet, seedsets *SeedSet) []*b
 		wg.Add(1)
 		go func(i int) {
 			defer wg.Done()
+			defer mpc.Recover(cancel)
 			v := system.System.SecSub(*versets[0].HIDs[i], *versets[1].HIDs[0])
 			t := 0
 			for j := 1; j < size_except_for_one; j++ {
+				if ctx.Err() != nil {
+					return
+				}
 				t = j
 				k := 1
 				for {
@@ -51,28 +60,32 @@ func (system *PMSystem) interphase_m(versets []InputSet, seedsets *SeedSet) []*b
 					if chkid {
 						interChan <- interid
 					} else {
-						fmt.Println("chk error")
-						os.Exit(1)
+						cancel(&mpc.MacCheckError{Op: "OpenFp", Party: 0, Element: i, Cheater: system.System.IdentifyFp(*versets[0].HIDs[i])})
 					}
 				}
 			} else {
-				fmt.Println("chk error")
-				os.Exit(1)
+				cancel(&mpc.MacCheckError{Op: "OpenFp", Party: 0, Element: i, Cheater: system.System.IdentifyFp(*v)})
 			}
 		}(i)
 	}
 	wg.Wait()
 	close(interChan) // Close the channel after all goroutines are done
-	return intersection
+	<-done
+	if ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+	return intersection, nil
 }
 
-func (system *PMSystem) PartyRun(inputsets []InputSet, seedsets SeedSet) []*big.Int {
-	fmt.Println("inter phase start")
+func (system *PMSystem) PartyRun(inputsets []InputSet, seedsets SeedSet) (*Result, error) {
+	result := new(Result)
 	intertime := time.Now()
-	intersection := system.interphase_m(inputsets, &seedsets)
-	fmt.Println("inter time:", time.Since(intertime))
-	fmt.Println("intersection:", intersection)
-	fmt.Printf("Offline Communication: %f MB\n", float64(system.System.OfflineCom)/1024/1024)
-	fmt.Printf("Online Communication: %f MB\n", float64(system.System.Com)/1024/1024)
-	return intersection
+	intersection, err := system.interphase_m(inputsets, &seedsets)
+	if err != nil {
+		return nil, err
+	}
+	result.Intersection = intersection
+	result.InterTime = time.Since(intertime)
+	result.OfflineCom, result.OnlineCom = system.GetCommunication()
+	return result, nil
 }
@@ -41,13 +41,24 @@ func (system *PMSystem) generateBigIntSlice(size, intersize int) []*big.Int {
 	return slice
 }
 
-func PMInitSystem(Partynum int) *PMSystem {
+// PMInitSystem returns the error of the PRSS setup.
+func PMInitSystem(Partynum int) (*PMSystem, error) {
 	system := new(PMSystem)
 	system.System = mpc.SystemInit(Partynum)
+	if err := system.System.SetupPRSS(); err != nil {
+		return nil, err
+	}
 	one := big.NewInt(1)
 	system.maxID = new(big.Int).Lsh(one, 64)
 	system.zero = big.NewInt(0)
-	return system
+	return system, nil
+}
+
+// SetupOfflinePhase makes the system take its triples and the MACs of its
+// seeds from the offline phase of mpc instead of the dealer. bits is the
+// Paillier modulus size, e.g. 2048. Call it before the inputs are shared.
+func (system *PMSystem) SetupOfflinePhase(bits int) error {
+	return system.System.SetupOfflinePhase(bits)
 }
 
 func (system *PMSystem) prepareid(intersize int, inputsize []int) []IDSet {
@@ -77,49 +88,43 @@ func (system *PMSystem) prepareid(intersize int, inputsize []int) []IDSet {
 	return idsets
 }
 
-func (system *PMSystem) prepareseeds(inputsize []int) []SeedSet {
-	var wg sync.WaitGroup
-	seedsets := make([]SeedSet, inputsize[0])
-	if inputsize[0]*inputsize[1] < 16384 {
-		for i := 0; i < inputsize[0]; i++ {
-			seedsets[i].Seeds = make([](*[]mpc.Share_Fp), inputsize[1])
-			for j := 0; j < inputsize[1]; j++ {
-				wg.Add(1)
-				go func(i, j int) {
-					defer wg.Done()
-					seedsets[i].Seeds[j] = system.System.RandomShareFp()
-				}(i, j)
+// seeds takes n seeds from the attached pool, if any, and otherwise derives
+// the PRSS values with indices k to k+n-1, so that the dealer does not pick
+// them. It panics with the error of the pool or the PRSS MACs; the runs check
+// the pool before they prepare their seeds.
+func (system *PMSystem) seeds(k uint64, n int) []*[]mpc.Share_Fp {
+	if system.System.Pool != nil {
+		shares := make([]*[]mpc.Share_Fp, n)
+		for i := range shares {
+			rshares, err := system.System.RandomShareFp()
+			if err != nil {
+				panic(err)
 			}
+			shares[i] = rshares
 		}
-	} else {
-		for i := 0; i < inputsize[0]; i++ {
-			seedsets[i].Seeds = make([](*[]mpc.Share_Fp), inputsize[1])
-			wg.Add(1)
-			go func(i int) {
-				defer wg.Done()
-				for j := 0; j < inputsize[1]; j++ {
-					seedsets[i].Seeds[j] = system.System.RandomShareFp()
-				}
-			}(i)
-		}
+		return shares
+	}
+	shares, err := system.System.RandomSharesFp_PRSS(k, n)
+	if err != nil {
+		panic(err)
+	}
+	return shares
+}
+
+func (system *PMSystem) prepareseeds(inputsize []int) []SeedSet {
+	seedsets := make([]SeedSet, inputsize[0])
+	base := system.System.ReservePRSS(inputsize[0] * inputsize[1])
+	seeds := system.seeds(base, inputsize[0]*inputsize[1])
+	for i := 0; i < inputsize[0]; i++ {
+		seedsets[i].Seeds = seeds[i*inputsize[1] : (i+1)*inputsize[1] : (i+1)*inputsize[1]]
 	}
-	wg.Wait()
 	return seedsets
 }
 
 func (system *PMSystem) prepareseeds_m(inputsize []int) *SeedSet {
-	var wg sync.WaitGroup
 	seedsets := new(SeedSet)
-	seedsets.Seeds = make([]*[]mpc.Share_Fp, inputsize[0])
-	//wg.Wait()
-	for i := 0; i < inputsize[0]; i++ {
-		wg.Add(1)
-		go func(i int) {
-			defer wg.Done()
-			seedsets.Seeds[i] = system.System.RandomShareFp()
-		}(i)
-	}
-	wg.Wait()
+	base := system.System.ReservePRSS(inputsize[0])
+	seedsets.Seeds = system.seeds(base, inputsize[0])
 	return seedsets
 }
 
@@ -176,34 +181,138 @@ func (system *PMSystem) prepareinput(idset []IDSet) []InputSet {
 	return sigsets
 }
 
-func (system *PMSystem) Run(inputsets []InputSet, seedset []SeedSet) {
-	//fmt.Println(inputsets)
-	system.twoPartyRun(inputsets, seedset)
+func inputsizes(inputsets []InputSet) []int {
+	inputsize := make([]int, len(inputsets))
+	for i := range inputsets {
+		inputsize[i] = inputsets[i].inputsize
+	}
+	return inputsize
+}
+
+// poolneeds returns the triples and random shares that Run (two-party mode)
+// or Run_m takes from the preprocessing pool for inputs of the given sizes.
+func poolneeds(inputsize []int, twoparty bool) (int, int) {
+	if twoparty {
+		return inputsize[0] * inputsize[1], inputsize[0] * inputsize[1]
+	}
+	muls := 1
+	for i := 1; i < len(inputsize); i++ {
+		muls = muls + inputsize[i] - 1
+	}
+	return inputsize[0] * muls, inputsize[0]
+}
+
+// PreparePool generates the triples and seeds for one run in bulk and
+// attaches them to the system. mode has the same meaning as in the protocol.
+// It returns the error of mpc.GenPool.
+func (system *PMSystem) PreparePool(inputsize []int, mode int) (*mpc.Pool, error) {
+	triplets, randoms := poolneeds(inputsize, mode == 0 && len(inputsize) == 2)
+	pool, err := system.System.GenPool(triplets, 0, randoms)
+	if err != nil {
+		return nil, err
+	}
+	system.System.UsePool(pool)
+	return pool, nil
+}
+
+// UsePool attaches a preprocessing pool, e.g. one read with mpc.LoadPool.
+func (system *PMSystem) UsePool(pool *mpc.Pool) {
+	system.System.UsePool(pool)
+}
+
+// Run returns the result of one run without printing it. It takes its
+// triples from the attached pool, if any, and returns a
+// *mpc.PoolExhaustedError before starting when the pool is too small.
+// seedset may be nil; the seeds are then drawn from the pool, if any, or
+// generated.
+func (system *PMSystem) Run(inputsets []InputSet, seedset []SeedSet) (*Result, error) {
+	return system.run(inputsets, seedset)
+}
+
+func (system *PMSystem) Run_m(inputsets []InputSet, seedset *SeedSet) (*Result, error) {
+	return system.run_m(inputsets, seedset)
+}
+
+func (system *PMSystem) run(inputsets []InputSet, seedset []SeedSet) (*Result, error) {
+	inputsize := inputsizes(inputsets)
+	if pool := system.System.Pool; pool != nil {
+		triplets, randoms := poolneeds(inputsize, true)
+		if seedset != nil {
+			randoms = 0
+		}
+		if err := pool.Require(triplets, 0, randoms); err != nil {
+			return nil, err
+		}
+	}
+	if seedset == nil {
+		seedset = system.prepareseeds(inputsize)
+	}
+	return system.pooled(system.twoPartyRun(inputsets, seedset))
+}
+
+func (system *PMSystem) run_m(inputsets []InputSet, seedset *SeedSet) (*Result, error) {
+	inputsize := inputsizes(inputsets)
+	if pool := system.System.Pool; pool != nil {
+		triplets, randoms := poolneeds(inputsize, false)
+		if seedset != nil {
+			randoms = 0
+		}
+		if err := pool.Require(triplets, 0, randoms); err != nil {
+			return nil, err
+		}
+	}
+	if seedset == nil {
+		seedset = system.prepareseeds_m(inputsize)
+	}
+	return system.pooled(system.PartyRun(inputsets, *seedset))
 }
 
-func (system *PMSystem) Run_m(inputsets []InputSet, seedset *SeedSet) {
-	system.PartyRun(inputsets, *seedset)
+// pooled returns the result of a run, or the *mpc.PoolExhaustedError of the
+// attached pool if the run needed more material than the pool held.
+func (system *PMSystem) pooled(result *Result, err error) (*Result, error) {
+	if err != nil {
+		return nil, err
+	}
+	if pool := system.System.Pool; pool != nil {
+		if err := pool.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
 }
 
 func (system *PMSystem) GetCommunication() (float64, float64) {
 	return float64(system.System.OfflineCom) / 1024 / 1024, float64(system.System.Com) / 1024 / 1024
 }
 
-func PMProtocol(intersize int, inputsize []int, mode int) *PMSystem {
+// PMProtocol returns a *mpc.MacCheckError when an opening fails its MAC
+// check.
+func PMProtocol(intersize int, inputsize []int, mode int) (*PMSystem, error) {
 	partynum := len(inputsize)
-	system := PMInitSystem(partynum)
+	system, err := PMInitSystem(partynum)
+	if err != nil {
+		return nil, err
+	}
 	if mode == 0 && partynum == 2 {
 		timepoint := time.Now()
 		seedsets, privatesets := system.PrepareData(intersize, inputsize)
 		timepoint1 := time.Since(timepoint)
 		fmt.Println("Data Preparation Time:", timepoint1)
-		system.Run(seedsets, privatesets)
+		result, err := system.Run(seedsets, privatesets)
+		if err != nil {
+			return system, err
+		}
+		result.print()
 	} else {
 		timepoint := time.Now()
 		seedsets, privatesets := system.PrepareData_m(intersize, inputsize)
 		timepoint1 := time.Since(timepoint)
 		fmt.Println("Data Preparation Time:", timepoint1)
-		system.Run_m(seedsets, privatesets)
+		result, err := system.Run_m(seedsets, privatesets)
+		if err != nil {
+			return system, err
+		}
+		result.print()
 	}
-	return system
+	return system, nil
 }
@@ -0,0 +1,398 @@
+package rss
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"sync"
+
+	"github.com/Oryx/network"
+)
+
+// Share_G is the share of party Index of a secp256k1 point: components
+// Index and Index+1.
+type Share_G struct {
+	ShareX *big.Int
+	ShareY *big.Int
+	NextX  *big.Int
+	NextY  *big.Int
+	Index  int
+}
+
+func (system *ECCShareSystem) negY(y *big.Int) *big.Int {
+	return new(big.Int).Mod(new(big.Int).Neg(y), system.Curve.P)
+}
+
+func (system *ECCShareSystem) RandomG() (*big.Int, *big.Int) {
+	scalar, _ := rand.Int(rand.Reader, system.Order)
+	RGX, RGY := system.Curve.ScalarMult(system.Curve.Gx, system.Curve.Gy, scalar.Bytes())
+	return RGX, RGY
+}
+
+func (system *ECCShareSystem) RandomShareG() *[]Share_G {
+	rX, rY := system.RandomG()
+	rshares := system.Share_A_G_Offline(rX, rY)
+	return rshares
+}
+
+func (system *ECCShareSystem) split_G(elementX, elementY *big.Int) ([Partynum]*big.Int, [Partynum]*big.Int) {
+	var partsX, partsY [Partynum]*big.Int
+	lastX, lastY := elementX, elementY
+	for k := 0; k < Partynum-1; k++ {
+		partsX[k], partsY[k] = system.RandomG()
+		lastX, lastY = system.Curve.Add(lastX, lastY, partsX[k], system.negY(partsY[k]))
+	}
+	partsX[Partynum-1], partsY[Partynum-1] = lastX, lastY
+	return partsX, partsY
+}
+
+func (system *ECCShareSystem) const_G(i int, scalarX, scalarY *big.Int) Share_G {
+	share := Share_G{ShareX: system.IdentityGx, ShareY: system.IdentityGy, NextX: system.IdentityGx, NextY: system.IdentityGy, Index: i}
+	if i == 0 {
+		share.ShareX, share.ShareY = scalarX, scalarY
+	}
+	if next(i) == 0 {
+		share.NextX, share.NextY = scalarX, scalarY
+	}
+	return share
+}
+
+func (system *ECCShareSystem) Share_A_G(elementX, elementY *big.Int) *[]Share_G {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go system.Send(&wg, system.Partynum, network.Pack(elementX.Bytes(), elementY.Bytes()))
+	partsX, partsY := system.split_G(elementX, elementY)
+	shares := make([]Share_G, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = Share_G{ShareX: partsX[i], ShareY: partsY[i], NextX: partsX[next(i)], NextY: partsY[next(i)], Index: i}
+		wg.Add(1)
+		go system.Send(&wg, i, network.Pack(shares[i].ShareX.Bytes(), shares[i].ShareY.Bytes(), shares[i].NextX.Bytes(), shares[i].NextY.Bytes()))
+	}
+	wg.Wait()
+	return &shares
+}
+
+func (system *ECCShareSystem) Share_A_G_Offline(elementX, elementY *big.Int) *[]Share_G {
+	var wg sync.WaitGroup
+	partsX, partsY := system.split_G(elementX, elementY)
+	shares := make([]Share_G, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = Share_G{ShareX: partsX[i], ShareY: partsY[i], NextX: partsX[next(i)], NextY: partsY[next(i)], Index: i}
+		wg.Add(1)
+		go system.OfflineSend(&wg, i, network.Pack(shares[i].ShareX.Bytes(), shares[i].ShareY.Bytes(), shares[i].NextX.Bytes(), shares[i].NextY.Bytes()))
+	}
+	wg.Wait()
+	return &shares
+}
+
+func (system *ECCShareSystem) share_EXP_P_G_1(elementX, elementY *big.Int, xshares Share_Fp) Share_G {
+	shares := new(Share_G)
+	shares.Index = xshares.Index
+	shares.ShareX, shares.ShareY = system.Curve.ScalarMult(elementX, elementY, xshares.Share.Bytes())
+	shares.NextX, shares.NextY = system.Curve.ScalarMult(elementX, elementY, xshares.Next.Bytes())
+	return *shares
+}
+
+func (system *ECCShareSystem) EXP_P_G_1(elementX, elementY *big.Int, xshares *[]Share_Fp) *[]Share_G {
+	shares := make([]Share_G, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.share_EXP_P_G_1(elementX, elementY, (*xshares)[i])
+	}
+	return &shares
+}
+
+func (system *ECCShareSystem) share_EXP_P_G_2(eshare Share_G, x *big.Int) Share_G {
+	shares := new(Share_G)
+	shares.Index = eshare.Index
+	shares.ShareX, shares.ShareY = system.Curve.ScalarMult(eshare.ShareX, eshare.ShareY, x.Bytes())
+	shares.NextX, shares.NextY = system.Curve.ScalarMult(eshare.NextX, eshare.NextY, x.Bytes())
+	return *shares
+}
+
+func (system *ECCShareSystem) EXP_P_G_2(eshares *[]Share_G, x *big.Int) *[]Share_G {
+	shares := make([]Share_G, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.share_EXP_P_G_2((*eshares)[i], x)
+	}
+	return &shares
+}
+
+func (system *ECCShareSystem) shareAdd_G(shares1, shares2 Share_G) Share_G {
+	shares := new(Share_G)
+	shares.Index = shares1.Index
+	shares.ShareX, shares.ShareY = system.Curve.Add(shares1.ShareX, shares1.ShareY, shares2.ShareX, shares2.ShareY)
+	shares.NextX, shares.NextY = system.Curve.Add(shares1.NextX, shares1.NextY, shares2.NextX, shares2.NextY)
+	return *shares
+}
+
+func (system *ECCShareSystem) SecAddPlaintext_G(shares1 []Share_G, scalarX, scalarY *big.Int) *[]Share_G {
+	shares := make([]Share_G, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareAdd_G(shares1[i], system.const_G(i, scalarX, scalarY))
+	}
+	return &shares
+}
+
+func (system *ECCShareSystem) SecAdd_G(shares1, shares2 []Share_G) *[]Share_G {
+	shares := make([]Share_G, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareAdd_G(shares1[i], shares2[i])
+	}
+	return &shares
+}
+
+func (system *ECCShareSystem) shareSub_G(shares1, shares2 Share_G) Share_G {
+	shares := new(Share_G)
+	shares.Index = shares1.Index
+	shares.ShareX, shares.ShareY = system.Curve.Add(shares1.ShareX, shares1.ShareY, shares2.ShareX, system.negY(shares2.ShareY))
+	shares.NextX, shares.NextY = system.Curve.Add(shares1.NextX, shares1.NextY, shares2.NextX, system.negY(shares2.NextY))
+	return *shares
+}
+
+func (system *ECCShareSystem) SecSub_G(shares1, shares2 []Share_G) *[]Share_G {
+	shares := make([]Share_G, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareSub_G(shares1[i], shares2[i])
+	}
+	return &shares
+}
+
+func (system *ECCShareSystem) SecSubPlaintext_G(shares1 []Share_G, scalarX, scalarY *big.Int) *[]Share_G {
+	shares := make([]Share_G, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareSub_G(shares1[i], system.const_G(i, scalarX, scalarY))
+	}
+	return &shares
+}
+
+// EXP_S_G panics with a *MismatchError when an opening fails.
+func (system *ECCShareSystem) EXP_S_G(hshares []Share_G, xshares []Share_Fp) *[]Share_G {
+	sharesA, sharesB, sharesC := system.GenTriplets()
+	sharesgB := system.EXP_P_G_1(system.Curve.Gx, system.Curve.Gy, sharesB)
+	sharesgC := system.EXP_P_G_1(system.Curve.Gx, system.Curve.Gy, sharesC)
+	XsubAshares := system.SecSub(xshares, *sharesA)
+	xsuba := system.mustOpenFp("EXP_S_G", *XsubAshares)
+	tshares := system.SecSub_G(hshares, *sharesgB)
+	tx, ty := system.mustOpenG("EXP_S_G", *tshares)
+	t_exp_xsuba_shares := system.EXP_P_G_1(tx, ty, XsubAshares)
+	t_exp_a_shares := system.EXP_P_G_1(tx, ty, sharesA)
+	gb_exp_xsuba_shares := system.EXP_P_G_2(sharesgB, xsuba)
+	shares := system.SecAdd_G(*sharesgC, *gb_exp_xsuba_shares)
+	shares = system.SecAdd_G(*shares, *t_exp_a_shares)
+	shares = system.SecAdd_G(*shares, *t_exp_xsuba_shares)
+	return shares
+}
+
+func (system *ECCShareSystem) openG(shares []Share_G) (*big.Int, *big.Int, int) {
+	var wg sync.WaitGroup
+	var msgs [Partynum][2][]byte
+	for i := 0; i < Partynum; i++ {
+		msgs[i] = [2][]byte{
+			network.Pack(shares[i].ShareX.Bytes(), shares[i].ShareY.Bytes()),
+			network.Pack(shares[i].NextX.Bytes(), shares[i].NextY.Bytes()),
+		}
+	}
+	system.exchange(&wg, msgs)
+	cheat := -1
+	for i := Partynum - 1; i >= 0; i-- {
+		if !bytes.Equal(msgs[next(i)][1], msgs[prev(i)][0]) {
+			cheat = i
+		}
+	}
+	ori_valueX, ori_valueY := system.Curve.Add(shares[0].ShareX, shares[0].ShareY, shares[0].NextX, shares[0].NextY)
+	ori_valueX, ori_valueY = system.Curve.Add(ori_valueX, ori_valueY, shares[1].NextX, shares[1].NextY)
+	wg.Wait()
+	return ori_valueX, ori_valueY, cheat
+}
+
+func (system *ECCShareSystem) mustOpenG(op string, shares []Share_G) (*big.Int, *big.Int) {
+	x, y, cheat := system.openG(shares)
+	if cheat >= 0 {
+		panic(&MismatchError{Op: op, Party: cheat})
+	}
+	return x, y
+}
+
+func (system *ECCShareSystem) OpenG(shares []Share_G) (*big.Int, *big.Int, bool) {
+	x, y, cheat := system.openG(shares)
+	return x, y, cheat < 0
+}
+
+func (system *ECCShareSystem) Share_An_Fp(element *big.Int) *[]Share_Fp {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go system.Send(&wg, system.Partynum, element.Bytes())
+	parts := split(element, system.Order)
+	shares := make([]Share_Fp, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = Share_Fp{Share: parts[i], Next: parts[next(i)], Index: i}
+		wg.Add(1)
+		go system.Send(&wg, i, network.Pack(shares[i].Share.Bytes(), shares[i].Next.Bytes()))
+	}
+	wg.Wait()
+	return &shares
+}
+
+func (system *ECCShareSystem) Share_An_Fp_Offline(element *big.Int) *[]Share_Fp {
+	var wg sync.WaitGroup
+	parts := split(element, system.Order)
+	shares := make([]Share_Fp, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = Share_Fp{Share: parts[i], Next: parts[next(i)], Index: i}
+		wg.Add(1)
+		go system.OfflineSend(&wg, i, network.Pack(shares[i].Share.Bytes(), shares[i].Next.Bytes()))
+	}
+	wg.Wait()
+	return &shares
+}
+
+func (system *ECCShareSystem) RandomShareFp() *[]Share_Fp {
+	r, _ := rand.Int(rand.Reader, system.Order)
+	rshares := system.Share_An_Fp_Offline(r)
+	return rshares
+}
+
+func (system *ECCShareSystem) shareAdd(shares1, shares2 Share_Fp) Share_Fp {
+	shares := new(Share_Fp)
+	shares.Index = shares1.Index
+	shares.Share = new(big.Int).Add(shares1.Share, shares2.Share)
+	shares.Share = shares.Share.Mod(shares.Share, system.Order)
+	shares.Next = new(big.Int).Add(shares1.Next, shares2.Next)
+	shares.Next = shares.Next.Mod(shares.Next, system.Order)
+	return *shares
+}
+
+// SecAddPlaintext adds the constant to component 0, held by parties 0 and 2.
+func (system *ECCShareSystem) SecAddPlaintext(shares1 []Share_Fp, scalar *big.Int) *[]Share_Fp {
+	shares := make([]Share_Fp, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareAdd(shares1[i], constFp(i, scalar))
+	}
+	return &shares
+}
+
+func (system *ECCShareSystem) SecAdd(shares1, shares2 []Share_Fp) *[]Share_Fp {
+	shares := make([]Share_Fp, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareAdd(shares1[i], shares2[i])
+	}
+	return &shares
+}
+
+func (system *ECCShareSystem) SecSubPlaintext(shares1 []Share_Fp, scalar *big.Int) *[]Share_Fp {
+	shares := make([]Share_Fp, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareSub(shares1[i], constFp(i, scalar))
+	}
+	return &shares
+}
+
+func (system *ECCShareSystem) shareSub(shares1, shares2 Share_Fp) Share_Fp {
+	shares := new(Share_Fp)
+	shares.Index = shares1.Index
+	shares.Share = new(big.Int).Sub(shares1.Share, shares2.Share)
+	shares.Share = shares.Share.Mod(shares.Share, system.Order)
+	shares.Next = new(big.Int).Sub(shares1.Next, shares2.Next)
+	shares.Next = shares.Next.Mod(shares.Next, system.Order)
+	return *shares
+}
+
+func (system *ECCShareSystem) SecSub(shares1, shares2 []Share_Fp) *[]Share_Fp {
+	shares := make([]Share_Fp, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareSub(shares1[i], shares2[i])
+	}
+	return &shares
+}
+
+func (system *ECCShareSystem) shareMulPlaintext(shares1 Share_Fp, scalar *big.Int) Share_Fp {
+	shares := new(Share_Fp)
+	shares.Index = shares1.Index
+	shares.Share = new(big.Int).Mul(shares1.Share, scalar)
+	shares.Share = shares.Share.Mod(shares.Share, system.Order)
+	shares.Next = new(big.Int).Mul(shares1.Next, scalar)
+	shares.Next = shares.Next.Mod(shares.Next, system.Order)
+	return *shares
+}
+
+func (system *ECCShareSystem) SecMulPlaintext(shares1 []Share_Fp, scalar *big.Int) *[]Share_Fp {
+	shares := make([]Share_Fp, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareMulPlaintext(shares1[i], scalar)
+	}
+	return &shares
+}
+
+// SecMul is ShareSystem.SecMul modulo the order of secp256k1.
+func (system *ECCShareSystem) SecMul(shares1, shares2 []Share_Fp) *[]Share_Fp {
+	var wg sync.WaitGroup
+	masks := system.zeroMasks()
+	var parts [Partynum]*big.Int
+	for i := 0; i < Partynum; i++ {
+		parts[i] = product(shares1[i], shares2[i], masks[i], system.Order)
+		wg.Add(1)
+		go system.transfer(&wg, i, prev(i), parts[i].Bytes())
+	}
+	shares := make([]Share_Fp, system.Partynum)
+	for i := 0; i < Partynum; i++ {
+		shares[i] = Share_Fp{Share: parts[i], Next: parts[next(i)], Index: i}
+	}
+	wg.Wait()
+	return &shares
+}
+
+func (system *ECCShareSystem) zeroMasks() [Partynum]*big.Int {
+	var wg sync.WaitGroup
+	var r, masks [Partynum]*big.Int
+	for j := 0; j < Partynum; j++ {
+		r[j], _ = rand.Int(rand.Reader, system.Order)
+		wg.Add(1)
+		go system.offlineTransfer(&wg, j, prev(j), r[j].Bytes())
+	}
+	for i := 0; i < Partynum; i++ {
+		masks[i] = new(big.Int).Sub(r[i], r[next(i)])
+	}
+	wg.Wait()
+	return masks
+}
+
+func (system *ECCShareSystem) SecSquare(shares1 []Share_Fp) *[]Share_Fp {
+	return system.SecMul(shares1, shares1)
+}
+
+// openFp returns the opened value and the first party that received two
+// different copies of its missing component, or -1.
+func (system *ECCShareSystem) openFp(shares []Share_Fp) (*big.Int, int) {
+	var wg sync.WaitGroup
+	var msgs [Partynum][2][]byte
+	for i := 0; i < Partynum; i++ {
+		msgs[i] = [2][]byte{shares[i].Share.Bytes(), shares[i].Next.Bytes()}
+	}
+	system.exchange(&wg, msgs)
+	cheat := -1
+	for i := Partynum - 1; i >= 0; i-- {
+		if !bytes.Equal(msgs[next(i)][1], msgs[prev(i)][0]) {
+			cheat = i
+		}
+	}
+	ori_value := new(big.Int).Add(shares[0].Share, shares[0].Next)
+	ori_value.Add(ori_value, shares[1].Next)
+	ori_value = ori_value.Mod(ori_value, system.Order)
+	wg.Wait()
+	return ori_value, cheat
+}
+
+func (system *ECCShareSystem) mustOpenFp(op string, shares []Share_Fp) *big.Int {
+	value, cheat := system.openFp(shares)
+	if cheat >= 0 {
+		panic(&MismatchError{Op: op, Party: cheat})
+	}
+	return value
+}
+
+// OpenFp returns false when a party received two different copies of its
+// missing component.
+func (system *ECCShareSystem) OpenFp(shares []Share_Fp) (*big.Int, bool) {
+	value, cheat := system.openFp(shares)
+	return value, cheat < 0
+}
@@ -0,0 +1,226 @@
+package rss
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"sync"
+
+	"github.com/Oryx/network"
+)
+
+// Share_Fp is the share of party Index: components Index and Index+1.
+type Share_Fp struct {
+	Share *big.Int
+	Next  *big.Int
+	Index int
+}
+
+// constFp is the share of party i of a public constant, which is put in
+// component 0.
+func constFp(i int, scalar *big.Int) Share_Fp {
+	share := Share_Fp{Share: zero, Next: zero, Index: i}
+	if i == 0 {
+		share.Share = scalar
+	}
+	if next(i) == 0 {
+		share.Next = scalar
+	}
+	return share
+}
+
+func (system *ShareSystem) Share_An_Fp(element *big.Int) *[]Share_Fp {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go system.Send(&wg, system.Partynum, element.Bytes())
+	parts := split(element, system.Order)
+	shares := make([]Share_Fp, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = Share_Fp{Share: parts[i], Next: parts[next(i)], Index: i}
+		wg.Add(1)
+		go system.Send(&wg, i, network.Pack(shares[i].Share.Bytes(), shares[i].Next.Bytes()))
+	}
+	wg.Wait()
+	return &shares
+}
+
+func (system *ShareSystem) Share_An_Fp_Offline(element *big.Int) *[]Share_Fp {
+	var wg sync.WaitGroup
+	parts := split(element, system.Order)
+	shares := make([]Share_Fp, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = Share_Fp{Share: parts[i], Next: parts[next(i)], Index: i}
+		wg.Add(1)
+		go system.OfflineSend(&wg, i, network.Pack(shares[i].Share.Bytes(), shares[i].Next.Bytes()))
+	}
+	wg.Wait()
+	return &shares
+}
+
+func (system *ShareSystem) RandomShareFp() *[]Share_Fp {
+	r, _ := rand.Int(rand.Reader, system.Order)
+	rshares := system.Share_An_Fp_Offline(r)
+	return rshares
+}
+
+func (system *ShareSystem) shareAdd(shares1, shares2 Share_Fp) Share_Fp {
+	shares := new(Share_Fp)
+	shares.Index = shares1.Index
+	shares.Share = new(big.Int).Add(shares1.Share, shares2.Share)
+	shares.Share = shares.Share.Mod(shares.Share, system.Order)
+	shares.Next = new(big.Int).Add(shares1.Next, shares2.Next)
+	shares.Next = shares.Next.Mod(shares.Next, system.Order)
+	return *shares
+}
+
+// SecAddPlaintext adds the constant to component 0, held by parties 0 and 2.
+func (system *ShareSystem) SecAddPlaintext(shares1 []Share_Fp, scalar *big.Int) *[]Share_Fp {
+	shares := make([]Share_Fp, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareAdd(shares1[i], constFp(i, scalar))
+	}
+	return &shares
+}
+
+func (system *ShareSystem) SecAdd(shares1, shares2 []Share_Fp) *[]Share_Fp {
+	shares := make([]Share_Fp, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareAdd(shares1[i], shares2[i])
+	}
+	return &shares
+}
+
+func (system *ShareSystem) SecSubPlaintext(shares1 []Share_Fp, scalar *big.Int) *[]Share_Fp {
+	shares := make([]Share_Fp, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareSub(shares1[i], constFp(i, scalar))
+	}
+	return &shares
+}
+
+func (system *ShareSystem) shareSub(shares1, shares2 Share_Fp) Share_Fp {
+	shares := new(Share_Fp)
+	shares.Index = shares1.Index
+	shares.Share = new(big.Int).Sub(shares1.Share, shares2.Share)
+	shares.Share = shares.Share.Mod(shares.Share, system.Order)
+	shares.Next = new(big.Int).Sub(shares1.Next, shares2.Next)
+	shares.Next = shares.Next.Mod(shares.Next, system.Order)
+	return *shares
+}
+
+func (system *ShareSystem) SecSub(shares1, shares2 []Share_Fp) *[]Share_Fp {
+	shares := make([]Share_Fp, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareSub(shares1[i], shares2[i])
+	}
+	return &shares
+}
+
+func (system *ShareSystem) shareMulPlaintext(shares1 Share_Fp, scalar *big.Int) Share_Fp {
+	shares := new(Share_Fp)
+	shares.Index = shares1.Index
+	shares.Share = new(big.Int).Mul(shares1.Share, scalar)
+	shares.Share = shares.Share.Mod(shares.Share, system.Order)
+	shares.Next = new(big.Int).Mul(shares1.Next, scalar)
+	shares.Next = shares.Next.Mod(shares.Next, system.Order)
+	return *shares
+}
+
+func (system *ShareSystem) SecMulPlaintext(shares1 []Share_Fp, scalar *big.Int) *[]Share_Fp {
+	shares := make([]Share_Fp, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareMulPlaintext(shares1[i], scalar)
+	}
+	return &shares
+}
+
+// SecMul multiplies without triples. Party i adds up the products of
+// components it can form, x_i*y_i + x_i*y_(i+1) + x_(i+1)*y_i, masks the sum
+// with its part of a sharing of zero and sends it to party i-1. The three
+// sums are the components of x*y, and every party then holds two of them.
+// Nothing is opened, so nothing is checked either: a party can add an error
+// to its sum, and SecMul is secure against semi-honest parties only.
+func (system *ShareSystem) SecMul(shares1, shares2 []Share_Fp) *[]Share_Fp {
+	var wg sync.WaitGroup
+	masks := system.zeroMasks()
+	var parts [Partynum]*big.Int
+	for i := 0; i < Partynum; i++ {
+		parts[i] = product(shares1[i], shares2[i], masks[i], system.Order)
+		wg.Add(1)
+		go system.transfer(&wg, i, prev(i), parts[i].Bytes())
+	}
+	shares := make([]Share_Fp, system.Partynum)
+	for i := 0; i < Partynum; i++ {
+		shares[i] = Share_Fp{Share: parts[i], Next: parts[next(i)], Index: i}
+	}
+	wg.Wait()
+	return &shares
+}
+
+// zeroMasks returns a random sharing of zero, alpha_i = r_i - r_(i+1) for
+// party i. Party j picks r_j and sends it to party j-1 ahead of time, as it
+// does not depend on any input.
+func (system *ShareSystem) zeroMasks() [Partynum]*big.Int {
+	var wg sync.WaitGroup
+	var r, masks [Partynum]*big.Int
+	for j := 0; j < Partynum; j++ {
+		r[j], _ = rand.Int(rand.Reader, system.Order)
+		wg.Add(1)
+		go system.offlineTransfer(&wg, j, prev(j), r[j].Bytes())
+	}
+	for i := 0; i < Partynum; i++ {
+		masks[i] = new(big.Int).Sub(r[i], r[next(i)])
+	}
+	wg.Wait()
+	return masks
+}
+
+// product is the local step of SecMul for the shares of one party.
+func product(shares1, shares2 Share_Fp, mask, order *big.Int) *big.Int {
+	z := new(big.Int).Mul(shares1.Share, shares2.Share)
+	z.Add(z, new(big.Int).Mul(shares1.Share, shares2.Next))
+	z.Add(z, new(big.Int).Mul(shares1.Next, shares2.Share))
+	z.Add(z, mask)
+	return z.Mod(z, order)
+}
+
+func (system *ShareSystem) SecSquare(shares1 []Share_Fp) *[]Share_Fp {
+	return system.SecMul(shares1, shares1)
+}
+
+// openFp returns the opened value and the first party that received two
+// different copies of its missing component, or -1.
+func (system *ShareSystem) openFp(shares []Share_Fp) (*big.Int, int) {
+	var wg sync.WaitGroup
+	var msgs [Partynum][2][]byte
+	for i := 0; i < Partynum; i++ {
+		msgs[i] = [2][]byte{shares[i].Share.Bytes(), shares[i].Next.Bytes()}
+	}
+	system.exchange(&wg, msgs)
+	cheat := -1
+	for i := Partynum - 1; i >= 0; i-- {
+		if !bytes.Equal(msgs[next(i)][1], msgs[prev(i)][0]) {
+			cheat = i
+		}
+	}
+	ori_value := new(big.Int).Add(shares[0].Share, shares[0].Next)
+	ori_value.Add(ori_value, shares[1].Next)
+	ori_value = ori_value.Mod(ori_value, system.Order)
+	wg.Wait()
+	return ori_value, cheat
+}
+
+func (system *ShareSystem) mustOpenFp(op string, shares []Share_Fp) *big.Int {
+	value, cheat := system.openFp(shares)
+	if cheat >= 0 {
+		panic(&MismatchError{Op: op, Party: cheat})
+	}
+	return value
+}
+
+// OpenFp returns false when a party received two different copies of its
+// missing component.
+func (system *ShareSystem) OpenFp(shares []Share_Fp) (*big.Int, bool) {
+	value, cheat := system.openFp(shares)
+	return value, cheat < 0
+}
@@ -0,0 +1,42 @@
+package rss
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestSecMul(t *testing.T) {
+	system := SystemInit()
+	pminus1 := new(big.Int).Sub(system.Order, big.NewInt(1))
+	for _, tc := range []struct {
+		x, y *big.Int
+	}{
+		{big.NewInt(0), big.NewInt(5)},
+		{big.NewInt(1), big.NewInt(1)},
+		{big.NewInt(123456789), big.NewInt(987654321)},
+		{pminus1, pminus1},
+		{pminus1, big.NewInt(2)},
+	} {
+		want := new(big.Int).Mul(tc.x, tc.y)
+		want.Mod(want, system.Order)
+		got, ok := system.OpenFp(*system.SecMul(*system.Share_An_Fp(tc.x), *system.Share_An_Fp(tc.y)))
+		if !ok {
+			t.Fatalf("opening failed for %v * %v", tc.x, tc.y)
+		}
+		if got.Cmp(want) != 0 {
+			t.Fatalf("got %v for %v * %v, want %v", got, tc.x, tc.y, want)
+		}
+	}
+}
+
+func TestOpenFpMismatch(t *testing.T) {
+	system := SystemInit()
+	shares := *system.Share_An_Fp(big.NewInt(7))
+	shares[1].Share = new(big.Int).Add(shares[1].Share, big.NewInt(1))
+	if _, cheat := system.openFp(shares); cheat != 2 {
+		t.Fatalf("got party %d, want party 2 to see the mismatch", cheat)
+	}
+	if _, ok := system.OpenFp(shares); ok {
+		t.Fatal("opening of a corrupted sharing passed")
+	}
+}
@@ -0,0 +1,203 @@
+package rss
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"sync"
+
+	curve "github.com/Oryx/curve"
+	"github.com/Oryx/network"
+)
+
+type Share_G1 struct {
+	Share *curve.G1
+	Next  *curve.G1
+	Index int
+}
+
+func split_G1(element *curve.G1) [Partynum]*curve.G1 {
+	var parts [Partynum]*curve.G1
+	last := new(curve.G1).Set(element)
+	for k := 0; k < Partynum-1; k++ {
+		_, parts[k], _ = curve.RandomG1(rand.Reader)
+		last.Add(last, new(curve.G1).Neg(parts[k]))
+	}
+	parts[Partynum-1] = last
+	return parts
+}
+
+func (system *ShareSystem) const_G1(i int, scalar *curve.G1) Share_G1 {
+	share := Share_G1{Share: system.IdentityG1, Next: system.IdentityG1, Index: i}
+	if i == 0 {
+		share.Share = scalar
+	}
+	if next(i) == 0 {
+		share.Next = scalar
+	}
+	return share
+}
+
+func (system *ShareSystem) Share_A_G1(element *curve.G1) *[]Share_G1 {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go system.Send(&wg, system.Partynum, element.Marshal())
+	parts := split_G1(element)
+	shares := make([]Share_G1, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = Share_G1{Share: parts[i], Next: parts[next(i)], Index: i}
+		wg.Add(1)
+		go system.Send(&wg, i, network.Pack(shares[i].Share.Marshal(), shares[i].Next.Marshal()))
+	}
+	wg.Wait()
+	return &shares
+}
+
+func (system *ShareSystem) Share_A_G1_Offline(element *curve.G1) *[]Share_G1 {
+	var wg sync.WaitGroup
+	parts := split_G1(element)
+	shares := make([]Share_G1, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = Share_G1{Share: parts[i], Next: parts[next(i)], Index: i}
+		wg.Add(1)
+		go system.OfflineSend(&wg, i, network.Pack(shares[i].Share.Marshal(), shares[i].Next.Marshal()))
+	}
+	wg.Wait()
+	return &shares
+}
+
+func (system *ShareSystem) RandomShareG1() *[]Share_G1 {
+	_, r, _ := curve.RandomG1(rand.Reader)
+	rshares := system.Share_A_G1_Offline(r)
+	return rshares
+}
+
+func (system *ShareSystem) share_EXP_P_G1_1(element *curve.G1, xshares Share_Fp) Share_G1 {
+	shares := new(Share_G1)
+	shares.Index = xshares.Index
+	shares.Share = new(curve.G1).ScalarMult(element, xshares.Share)
+	shares.Next = new(curve.G1).ScalarMult(element, xshares.Next)
+	return *shares
+}
+
+func (system *ShareSystem) EXP_P_G1_1(element *curve.G1, xshares *[]Share_Fp) *[]Share_G1 {
+	shares := make([]Share_G1, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.share_EXP_P_G1_1(element, (*xshares)[i])
+	}
+	return &shares
+}
+
+func (system *ShareSystem) share_EXP_P_G1_2(eshare Share_G1, x *big.Int) Share_G1 {
+	shares := new(Share_G1)
+	shares.Index = eshare.Index
+	shares.Share = new(curve.G1).ScalarMult(eshare.Share, x)
+	shares.Next = new(curve.G1).ScalarMult(eshare.Next, x)
+	return *shares
+}
+
+func (system *ShareSystem) EXP_P_G1_2(eshares *[]Share_G1, x *big.Int) *[]Share_G1 {
+	shares := make([]Share_G1, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.share_EXP_P_G1_2((*eshares)[i], x)
+	}
+	return &shares
+}
+
+func (system *ShareSystem) shareAdd_G1(shares1, shares2 Share_G1) Share_G1 {
+	shares := new(Share_G1)
+	shares.Index = shares1.Index
+	shares.Share = new(curve.G1).Add(shares1.Share, shares2.Share)
+	shares.Next = new(curve.G1).Add(shares1.Next, shares2.Next)
+	return *shares
+}
+
+func (system *ShareSystem) SecAddPlaintext_G1(shares1 []Share_G1, scalar *curve.G1) *[]Share_G1 {
+	shares := make([]Share_G1, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareAdd_G1(shares1[i], system.const_G1(i, scalar))
+	}
+	return &shares
+}
+
+func (system *ShareSystem) SecAdd_G1(shares1, shares2 []Share_G1) *[]Share_G1 {
+	shares := make([]Share_G1, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareAdd_G1(shares1[i], shares2[i])
+	}
+	return &shares
+}
+
+func (system *ShareSystem) shareSub_G1(shares1, shares2 Share_G1) Share_G1 {
+	shares := new(Share_G1)
+	shares.Index = shares1.Index
+	shares.Share = new(curve.G1).Add(shares1.Share, new(curve.G1).Neg(shares2.Share))
+	shares.Next = new(curve.G1).Add(shares1.Next, new(curve.G1).Neg(shares2.Next))
+	return *shares
+}
+
+func (system *ShareSystem) SecSub_G1(shares1, shares2 []Share_G1) *[]Share_G1 {
+	shares := make([]Share_G1, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareSub_G1(shares1[i], shares2[i])
+	}
+	return &shares
+}
+
+func (system *ShareSystem) SecSubPlaintext_G1(shares1 []Share_G1, scalar *curve.G1) *[]Share_G1 {
+	shares := make([]Share_G1, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareSub_G1(shares1[i], system.const_G1(i, scalar))
+	}
+	return &shares
+}
+
+// EXP_S_G1 panics with a *MismatchError when an opening fails.
+func (system *ShareSystem) EXP_S_G1(hshares []Share_G1, xshares []Share_Fp) *[]Share_G1 {
+	sharesA, sharesB, sharesC := system.GenTriplets()
+	sharesgB := system.EXP_P_G1_1(curve.Gen1, sharesB)
+	sharesgC := system.EXP_P_G1_1(curve.Gen1, sharesC)
+	XsubAshares := system.SecSub(xshares, *sharesA)
+	xsuba := system.mustOpenFp("EXP_S_G1", *XsubAshares)
+	tshares := system.SecSub_G1(hshares, *sharesgB)
+	t := system.mustOpenG1("EXP_S_G1", *tshares)
+	t_exp_xsuba_shares := system.EXP_P_G1_1(t, XsubAshares)
+	t_exp_a_shares := system.EXP_P_G1_1(t, sharesA)
+	gb_exp_xsuba_shares := system.EXP_P_G1_2(sharesgB, xsuba)
+	shares := system.SecAdd_G1(*sharesgC, *gb_exp_xsuba_shares)
+	shares = system.SecAdd_G1(*shares, *t_exp_a_shares)
+	shares = system.SecAdd_G1(*shares, *t_exp_xsuba_shares)
+	return shares
+}
+
+func (system *ShareSystem) openG1(shares []Share_G1) (*curve.G1, int) {
+	var wg sync.WaitGroup
+	var msgs [Partynum][2][]byte
+	for i := 0; i < Partynum; i++ {
+		msgs[i] = [2][]byte{shares[i].Share.Marshal(), shares[i].Next.Marshal()}
+	}
+	system.exchange(&wg, msgs)
+	cheat := -1
+	for i := Partynum - 1; i >= 0; i-- {
+		if !bytes.Equal(msgs[next(i)][1], msgs[prev(i)][0]) {
+			cheat = i
+		}
+	}
+	ori_value := new(curve.G1).Add(shares[0].Share, shares[0].Next)
+	ori_value.Add(ori_value, shares[1].Next)
+	wg.Wait()
+	return ori_value, cheat
+}
+
+func (system *ShareSystem) mustOpenG1(op string, shares []Share_G1) *curve.G1 {
+	value, cheat := system.openG1(shares)
+	if cheat >= 0 {
+		panic(&MismatchError{Op: op, Party: cheat})
+	}
+	return value
+}
+
+func (system *ShareSystem) OpenG1(shares []Share_G1) (*curve.G1, bool) {
+	value, cheat := system.openG1(shares)
+	return value, cheat < 0
+}
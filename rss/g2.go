@@ -0,0 +1,203 @@
+package rss
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"sync"
+
+	curve "github.com/Oryx/curve"
+	"github.com/Oryx/network"
+)
+
+type Share_G2 struct {
+	Share *curve.G2
+	Next  *curve.G2
+	Index int
+}
+
+func split_G2(element *curve.G2) [Partynum]*curve.G2 {
+	var parts [Partynum]*curve.G2
+	last := new(curve.G2).Set(element)
+	for k := 0; k < Partynum-1; k++ {
+		_, parts[k], _ = curve.RandomG2(rand.Reader)
+		last.Add(last, new(curve.G2).Neg(parts[k]))
+	}
+	parts[Partynum-1] = last
+	return parts
+}
+
+func (system *ShareSystem) const_G2(i int, scalar *curve.G2) Share_G2 {
+	share := Share_G2{Share: system.IdentityG2, Next: system.IdentityG2, Index: i}
+	if i == 0 {
+		share.Share = scalar
+	}
+	if next(i) == 0 {
+		share.Next = scalar
+	}
+	return share
+}
+
+func (system *ShareSystem) Share_A_G2(element *curve.G2) *[]Share_G2 {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go system.Send(&wg, system.Partynum, element.Marshal())
+	parts := split_G2(element)
+	shares := make([]Share_G2, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = Share_G2{Share: parts[i], Next: parts[next(i)], Index: i}
+		wg.Add(1)
+		go system.Send(&wg, i, network.Pack(shares[i].Share.Marshal(), shares[i].Next.Marshal()))
+	}
+	wg.Wait()
+	return &shares
+}
+
+func (system *ShareSystem) Share_A_G2_Offline(element *curve.G2) *[]Share_G2 {
+	var wg sync.WaitGroup
+	parts := split_G2(element)
+	shares := make([]Share_G2, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = Share_G2{Share: parts[i], Next: parts[next(i)], Index: i}
+		wg.Add(1)
+		go system.OfflineSend(&wg, i, network.Pack(shares[i].Share.Marshal(), shares[i].Next.Marshal()))
+	}
+	wg.Wait()
+	return &shares
+}
+
+func (system *ShareSystem) RandomShareG2() *[]Share_G2 {
+	_, r, _ := curve.RandomG2(rand.Reader)
+	rshares := system.Share_A_G2_Offline(r)
+	return rshares
+}
+
+func (system *ShareSystem) share_EXP_P_G2_1(element *curve.G2, xshares Share_Fp) Share_G2 {
+	shares := new(Share_G2)
+	shares.Index = xshares.Index
+	shares.Share = new(curve.G2).ScalarMult(element, xshares.Share)
+	shares.Next = new(curve.G2).ScalarMult(element, xshares.Next)
+	return *shares
+}
+
+func (system *ShareSystem) EXP_P_G2_1(element *curve.G2, xshares *[]Share_Fp) *[]Share_G2 {
+	shares := make([]Share_G2, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.share_EXP_P_G2_1(element, (*xshares)[i])
+	}
+	return &shares
+}
+
+func (system *ShareSystem) share_EXP_P_G2_2(eshare Share_G2, x *big.Int) Share_G2 {
+	shares := new(Share_G2)
+	shares.Index = eshare.Index
+	shares.Share = new(curve.G2).ScalarMult(eshare.Share, x)
+	shares.Next = new(curve.G2).ScalarMult(eshare.Next, x)
+	return *shares
+}
+
+func (system *ShareSystem) EXP_P_G2_2(eshares *[]Share_G2, x *big.Int) *[]Share_G2 {
+	shares := make([]Share_G2, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.share_EXP_P_G2_2((*eshares)[i], x)
+	}
+	return &shares
+}
+
+func (system *ShareSystem) shareAdd_G2(shares1, shares2 Share_G2) Share_G2 {
+	shares := new(Share_G2)
+	shares.Index = shares1.Index
+	shares.Share = new(curve.G2).Add(shares1.Share, shares2.Share)
+	shares.Next = new(curve.G2).Add(shares1.Next, shares2.Next)
+	return *shares
+}
+
+func (system *ShareSystem) SecAddPlaintext_G2(shares1 []Share_G2, scalar *curve.G2) *[]Share_G2 {
+	shares := make([]Share_G2, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareAdd_G2(shares1[i], system.const_G2(i, scalar))
+	}
+	return &shares
+}
+
+func (system *ShareSystem) SecAdd_G2(shares1, shares2 []Share_G2) *[]Share_G2 {
+	shares := make([]Share_G2, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareAdd_G2(shares1[i], shares2[i])
+	}
+	return &shares
+}
+
+func (system *ShareSystem) shareSub_G2(shares1, shares2 Share_G2) Share_G2 {
+	shares := new(Share_G2)
+	shares.Index = shares1.Index
+	shares.Share = new(curve.G2).Add(shares1.Share, new(curve.G2).Neg(shares2.Share))
+	shares.Next = new(curve.G2).Add(shares1.Next, new(curve.G2).Neg(shares2.Next))
+	return *shares
+}
+
+func (system *ShareSystem) SecSub_G2(shares1, shares2 []Share_G2) *[]Share_G2 {
+	shares := make([]Share_G2, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareSub_G2(shares1[i], shares2[i])
+	}
+	return &shares
+}
+
+func (system *ShareSystem) SecSubPlaintext_G2(shares1 []Share_G2, scalar *curve.G2) *[]Share_G2 {
+	shares := make([]Share_G2, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareSub_G2(shares1[i], system.const_G2(i, scalar))
+	}
+	return &shares
+}
+
+// EXP_S_G2 panics with a *MismatchError when an opening fails.
+func (system *ShareSystem) EXP_S_G2(hshares []Share_G2, xshares []Share_Fp) *[]Share_G2 {
+	sharesA, sharesB, sharesC := system.GenTriplets()
+	sharesgB := system.EXP_P_G2_1(curve.Gen2, sharesB)
+	sharesgC := system.EXP_P_G2_1(curve.Gen2, sharesC)
+	XsubAshares := system.SecSub(xshares, *sharesA)
+	xsuba := system.mustOpenFp("EXP_S_G2", *XsubAshares)
+	tshares := system.SecSub_G2(hshares, *sharesgB)
+	t := system.mustOpenG2("EXP_S_G2", *tshares)
+	t_exp_xsuba_shares := system.EXP_P_G2_1(t, XsubAshares)
+	t_exp_a_shares := system.EXP_P_G2_1(t, sharesA)
+	gb_exp_xsuba_shares := system.EXP_P_G2_2(sharesgB, xsuba)
+	shares := system.SecAdd_G2(*sharesgC, *gb_exp_xsuba_shares)
+	shares = system.SecAdd_G2(*shares, *t_exp_a_shares)
+	shares = system.SecAdd_G2(*shares, *t_exp_xsuba_shares)
+	return shares
+}
+
+func (system *ShareSystem) openG2(shares []Share_G2) (*curve.G2, int) {
+	var wg sync.WaitGroup
+	var msgs [Partynum][2][]byte
+	for i := 0; i < Partynum; i++ {
+		msgs[i] = [2][]byte{shares[i].Share.Marshal(), shares[i].Next.Marshal()}
+	}
+	system.exchange(&wg, msgs)
+	cheat := -1
+	for i := Partynum - 1; i >= 0; i-- {
+		if !bytes.Equal(msgs[next(i)][1], msgs[prev(i)][0]) {
+			cheat = i
+		}
+	}
+	ori_value := new(curve.G2).Add(shares[0].Share, shares[0].Next)
+	ori_value.Add(ori_value, shares[1].Next)
+	wg.Wait()
+	return ori_value, cheat
+}
+
+func (system *ShareSystem) mustOpenG2(op string, shares []Share_G2) *curve.G2 {
+	value, cheat := system.openG2(shares)
+	if cheat >= 0 {
+		panic(&MismatchError{Op: op, Party: cheat})
+	}
+	return value
+}
+
+func (system *ShareSystem) OpenG2(shares []Share_G2) (*curve.G2, bool) {
+	value, cheat := system.openG2(shares)
+	return value, cheat < 0
+}
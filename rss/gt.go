@@ -0,0 +1,203 @@
+package rss
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"sync"
+
+	curve "github.com/Oryx/curve"
+	"github.com/Oryx/network"
+)
+
+type Share_GT struct {
+	Share *curve.GT
+	Next  *curve.GT
+	Index int
+}
+
+func split_GT(element *curve.GT) [Partynum]*curve.GT {
+	var parts [Partynum]*curve.GT
+	last := new(curve.GT).Set(element)
+	for k := 0; k < Partynum-1; k++ {
+		_, parts[k], _ = curve.RandomGTK(rand.Reader)
+		last.Add(last, new(curve.GT).Neg(parts[k]))
+	}
+	parts[Partynum-1] = last
+	return parts
+}
+
+func (system *ShareSystem) const_GT(i int, scalar *curve.GT) Share_GT {
+	share := Share_GT{Share: system.IdentityGT, Next: system.IdentityGT, Index: i}
+	if i == 0 {
+		share.Share = scalar
+	}
+	if next(i) == 0 {
+		share.Next = scalar
+	}
+	return share
+}
+
+func (system *ShareSystem) Share_A_GT(element *curve.GT) *[]Share_GT {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go system.Send(&wg, system.Partynum, element.Marshal())
+	parts := split_GT(element)
+	shares := make([]Share_GT, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = Share_GT{Share: parts[i], Next: parts[next(i)], Index: i}
+		wg.Add(1)
+		go system.Send(&wg, i, network.Pack(shares[i].Share.Marshal(), shares[i].Next.Marshal()))
+	}
+	wg.Wait()
+	return &shares
+}
+
+func (system *ShareSystem) Share_A_GT_Offline(element *curve.GT) *[]Share_GT {
+	var wg sync.WaitGroup
+	parts := split_GT(element)
+	shares := make([]Share_GT, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = Share_GT{Share: parts[i], Next: parts[next(i)], Index: i}
+		wg.Add(1)
+		go system.OfflineSend(&wg, i, network.Pack(shares[i].Share.Marshal(), shares[i].Next.Marshal()))
+	}
+	wg.Wait()
+	return &shares
+}
+
+func (system *ShareSystem) RandomShareGT() *[]Share_GT {
+	_, r, _ := curve.RandomGTK(rand.Reader)
+	rshares := system.Share_A_GT_Offline(r)
+	return rshares
+}
+
+func (system *ShareSystem) share_EXP_P_GT_1(element *curve.GT, xshares Share_Fp) Share_GT {
+	shares := new(Share_GT)
+	shares.Index = xshares.Index
+	shares.Share = new(curve.GT).ScalarMult(element, xshares.Share)
+	shares.Next = new(curve.GT).ScalarMult(element, xshares.Next)
+	return *shares
+}
+
+func (system *ShareSystem) EXP_P_GT_1(element *curve.GT, xshares *[]Share_Fp) *[]Share_GT {
+	shares := make([]Share_GT, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.share_EXP_P_GT_1(element, (*xshares)[i])
+	}
+	return &shares
+}
+
+func (system *ShareSystem) share_EXP_P_GT_2(eshare Share_GT, x *big.Int) Share_GT {
+	shares := new(Share_GT)
+	shares.Index = eshare.Index
+	shares.Share = new(curve.GT).ScalarMult(eshare.Share, x)
+	shares.Next = new(curve.GT).ScalarMult(eshare.Next, x)
+	return *shares
+}
+
+func (system *ShareSystem) EXP_P_GT_2(eshares *[]Share_GT, x *big.Int) *[]Share_GT {
+	shares := make([]Share_GT, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.share_EXP_P_GT_2((*eshares)[i], x)
+	}
+	return &shares
+}
+
+func (system *ShareSystem) shareAdd_GT(shares1, shares2 Share_GT) Share_GT {
+	shares := new(Share_GT)
+	shares.Index = shares1.Index
+	shares.Share = new(curve.GT).Add(shares1.Share, shares2.Share)
+	shares.Next = new(curve.GT).Add(shares1.Next, shares2.Next)
+	return *shares
+}
+
+func (system *ShareSystem) SecAddPlaintext_GT(shares1 []Share_GT, scalar *curve.GT) *[]Share_GT {
+	shares := make([]Share_GT, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareAdd_GT(shares1[i], system.const_GT(i, scalar))
+	}
+	return &shares
+}
+
+func (system *ShareSystem) SecAdd_GT(shares1, shares2 []Share_GT) *[]Share_GT {
+	shares := make([]Share_GT, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareAdd_GT(shares1[i], shares2[i])
+	}
+	return &shares
+}
+
+func (system *ShareSystem) shareSub_GT(shares1, shares2 Share_GT) Share_GT {
+	shares := new(Share_GT)
+	shares.Index = shares1.Index
+	shares.Share = new(curve.GT).Add(shares1.Share, new(curve.GT).Neg(shares2.Share))
+	shares.Next = new(curve.GT).Add(shares1.Next, new(curve.GT).Neg(shares2.Next))
+	return *shares
+}
+
+func (system *ShareSystem) SecSub_GT(shares1, shares2 []Share_GT) *[]Share_GT {
+	shares := make([]Share_GT, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareSub_GT(shares1[i], shares2[i])
+	}
+	return &shares
+}
+
+func (system *ShareSystem) SecSubPlaintext_GT(shares1 []Share_GT, scalar *curve.GT) *[]Share_GT {
+	shares := make([]Share_GT, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareSub_GT(shares1[i], system.const_GT(i, scalar))
+	}
+	return &shares
+}
+
+// EXP_S_GT panics with a *MismatchError when an opening fails.
+func (system *ShareSystem) EXP_S_GT(hshares []Share_GT, xshares []Share_Fp) *[]Share_GT {
+	sharesA, sharesB, sharesC := system.GenTriplets()
+	sharesgB := system.EXP_P_GT_1(system.GenGT, sharesB)
+	sharesgC := system.EXP_P_GT_1(system.GenGT, sharesC)
+	XsubAshares := system.SecSub(xshares, *sharesA)
+	xsuba := system.mustOpenFp("EXP_S_GT", *XsubAshares)
+	tshares := system.SecSub_GT(hshares, *sharesgB)
+	t := system.mustOpenGT("EXP_S_GT", *tshares)
+	t_exp_xsuba_shares := system.EXP_P_GT_1(t, XsubAshares)
+	t_exp_a_shares := system.EXP_P_GT_1(t, sharesA)
+	gb_exp_xsuba_shares := system.EXP_P_GT_2(sharesgB, xsuba)
+	shares := system.SecAdd_GT(*sharesgC, *gb_exp_xsuba_shares)
+	shares = system.SecAdd_GT(*shares, *t_exp_a_shares)
+	shares = system.SecAdd_GT(*shares, *t_exp_xsuba_shares)
+	return shares
+}
+
+func (system *ShareSystem) openGT(shares []Share_GT) (*curve.GT, int) {
+	var wg sync.WaitGroup
+	var msgs [Partynum][2][]byte
+	for i := 0; i < Partynum; i++ {
+		msgs[i] = [2][]byte{shares[i].Share.Marshal(), shares[i].Next.Marshal()}
+	}
+	system.exchange(&wg, msgs)
+	cheat := -1
+	for i := Partynum - 1; i >= 0; i-- {
+		if !bytes.Equal(msgs[next(i)][1], msgs[prev(i)][0]) {
+			cheat = i
+		}
+	}
+	ori_value := new(curve.GT).Add(shares[0].Share, shares[0].Next)
+	ori_value.Add(ori_value, shares[1].Next)
+	wg.Wait()
+	return ori_value, cheat
+}
+
+func (system *ShareSystem) mustOpenGT(op string, shares []Share_GT) *curve.GT {
+	value, cheat := system.openGT(shares)
+	if cheat >= 0 {
+		panic(&MismatchError{Op: op, Party: cheat})
+	}
+	return value
+}
+
+func (system *ShareSystem) OpenGT(shares []Share_GT) (*curve.GT, bool) {
+	value, cheat := system.openGT(shares)
+	return value, cheat < 0
+}
@@ -0,0 +1,282 @@
+package rss
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"sync/atomic"
+
+	curve "github.com/Oryx/curve"
+	"github.com/Oryx/ecc"
+	"github.com/Oryx/network"
+	"github.com/Oryx/shmpc"
+)
+
+// Partynum is fixed: replicated sharing here is 2-out-of-3.
+const Partynum = 3
+
+var zero = big.NewInt(0)
+
+var ErrBandwidth = errors.New("rss: bandwidth must be positive")
+
+// MismatchError reports that Party received two different copies of the
+// component it was missing while opening, so one of the other two parties
+// deviated. Op names the operation; Party is -1 when the caller only
+// learnt that an opening failed.
+type MismatchError struct {
+	Op    string
+	Party int
+}
+
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("rss: %s: party %d received two different copies of a share", e.Op, e.Party)
+}
+
+// ShareSystem is a 3-party honest-majority system. A value x is split into
+// three components x = x_0 + x_1 + x_2 and party i holds x_i and x_(i+1), so
+// every component is held by two parties and any two parties can open x.
+// When a value is opened, party i gets its missing component x_(i+2) from
+// both other parties and aborts if the copies differ; a single cheating
+// party therefore cannot change an opened value. SecMul reshares local
+// products instead of opening anything, so a cheating party can change a
+// product; EXP_S_* and Pair_S use Beaver triples from the dealer and consist
+// of such openings only.
+type ShareSystem struct {
+	Partynum      int
+	IdentityG1    *curve.G1
+	IdentityG2    *curve.G2
+	GenGT         *curve.GT
+	IdentityGT    *curve.GT
+	Order         *big.Int
+	Com           int64
+	OfflineCom    int64
+	isWAN         bool
+	bandwidth     float64
+	BandwidthCtrl *shmpc.BandwidthSimulator
+	Net           []network.Transport
+	// failure records the first message that could not be delivered; see
+	// Context.
+	failure *network.Failure
+}
+
+// ECCShareSystem is ShareSystem for secp256k1, with scalars modulo its
+// order.
+type ECCShareSystem struct {
+	Partynum      int
+	IdentityGx    *big.Int
+	IdentityGy    *big.Int
+	Order         *big.Int
+	Curve         *ecc.KoblitzCurve
+	Com           int64
+	OfflineCom    int64
+	isWAN         bool
+	bandwidth     float64
+	BandwidthCtrl *shmpc.BandwidthSimulator
+	Net           []network.Transport
+	failure       *network.Failure
+}
+
+func next(i int) int {
+	return (i + 1) % Partynum
+}
+
+func prev(i int) int {
+	return (i + Partynum - 1) % Partynum
+}
+
+// split returns three random components that add up to element.
+func split(element, order *big.Int) [Partynum]*big.Int {
+	var parts [Partynum]*big.Int
+	last := new(big.Int).Set(element)
+	for k := 0; k < Partynum-1; k++ {
+		parts[k], _ = rand.Int(rand.Reader, order)
+		last.Sub(last, parts[k])
+	}
+	parts[Partynum-1] = last.Mod(last, order)
+	return parts
+}
+
+// Context is cancelled with the error of the first message the system fails
+// to deliver, as for mpc.ShareSystem. NetErr returns it.
+func (system *ShareSystem) Context() context.Context {
+	return system.failure.Context()
+}
+
+func (system *ShareSystem) NetErr() error {
+	return system.failure.Err()
+}
+
+func (system *ShareSystem) Send(wg *sync.WaitGroup, to int, msg []byte) {
+	atomic.AddInt64(&system.Com, int64(len(msg)))
+	if system.isWAN && system.BandwidthCtrl != nil {
+		system.BandwidthCtrl.SimulateSend(msg)
+	}
+	system.failure.Record(network.Deliver(system.Net, system.Partynum, to, msg))
+	wg.Done()
+}
+
+func (system *ShareSystem) OfflineSend(wg *sync.WaitGroup, to int, msg []byte) {
+	atomic.AddInt64(&system.OfflineCom, int64(len(msg)))
+	if system.isWAN && system.BandwidthCtrl != nil {
+		system.BandwidthCtrl.SimulateSend(msg)
+	}
+	system.failure.Record(network.Deliver(system.Net, system.Partynum, to, msg))
+	wg.Done()
+}
+
+// exchange is one round of an opening: every party sends its first
+// component to the next party and its second to the previous one, which is
+// the component each of them is missing.
+func (system *ShareSystem) exchange(wg *sync.WaitGroup, msgs [Partynum][2][]byte) {
+	for i := 0; i < Partynum; i++ {
+		wg.Add(2)
+		go system.transfer(wg, i, next(i), msgs[i][0])
+		go system.transfer(wg, i, prev(i), msgs[i][1])
+	}
+}
+
+func (system *ShareSystem) transfer(wg *sync.WaitGroup, from, to int, msg []byte) {
+	atomic.AddInt64(&system.Com, int64(len(msg)))
+	if system.isWAN && system.BandwidthCtrl != nil {
+		system.BandwidthCtrl.SimulateSend(msg)
+	}
+	system.failure.Record(network.Deliver(system.Net, from, to, msg))
+	wg.Done()
+}
+
+// offlineTransfer is transfer for a message that does not depend on any
+// input.
+func (system *ShareSystem) offlineTransfer(wg *sync.WaitGroup, from, to int, msg []byte) {
+	atomic.AddInt64(&system.OfflineCom, int64(len(msg)))
+	if system.isWAN && system.BandwidthCtrl != nil {
+		system.BandwidthCtrl.SimulateSend(msg)
+	}
+	system.failure.Record(network.Deliver(system.Net, from, to, msg))
+	wg.Done()
+}
+
+func (system *ECCShareSystem) Context() context.Context {
+	return system.failure.Context()
+}
+
+func (system *ECCShareSystem) NetErr() error {
+	return system.failure.Err()
+}
+
+func (system *ECCShareSystem) Send(wg *sync.WaitGroup, to int, msg []byte) {
+	atomic.AddInt64(&system.Com, int64(len(msg)))
+	if system.isWAN && system.BandwidthCtrl != nil {
+		system.BandwidthCtrl.SimulateSend(msg)
+	}
+	system.failure.Record(network.Deliver(system.Net, system.Partynum, to, msg))
+	wg.Done()
+}
+
+func (system *ECCShareSystem) OfflineSend(wg *sync.WaitGroup, to int, msg []byte) {
+	atomic.AddInt64(&system.OfflineCom, int64(len(msg)))
+	if system.isWAN && system.BandwidthCtrl != nil {
+		system.BandwidthCtrl.SimulateSend(msg)
+	}
+	system.failure.Record(network.Deliver(system.Net, system.Partynum, to, msg))
+	wg.Done()
+}
+
+func (system *ECCShareSystem) exchange(wg *sync.WaitGroup, msgs [Partynum][2][]byte) {
+	for i := 0; i < Partynum; i++ {
+		wg.Add(2)
+		go system.transfer(wg, i, next(i), msgs[i][0])
+		go system.transfer(wg, i, prev(i), msgs[i][1])
+	}
+}
+
+func (system *ECCShareSystem) transfer(wg *sync.WaitGroup, from, to int, msg []byte) {
+	atomic.AddInt64(&system.Com, int64(len(msg)))
+	if system.isWAN && system.BandwidthCtrl != nil {
+		system.BandwidthCtrl.SimulateSend(msg)
+	}
+	system.failure.Record(network.Deliver(system.Net, from, to, msg))
+	wg.Done()
+}
+
+func (system *ECCShareSystem) offlineTransfer(wg *sync.WaitGroup, from, to int, msg []byte) {
+	atomic.AddInt64(&system.OfflineCom, int64(len(msg)))
+	if system.isWAN && system.BandwidthCtrl != nil {
+		system.BandwidthCtrl.SimulateSend(msg)
+	}
+	system.failure.Record(network.Deliver(system.Net, from, to, msg))
+	wg.Done()
+}
+
+func (system *ShareSystem) GenTriplets() (*[]Share_Fp, *[]Share_Fp, *[]Share_Fp) {
+	A, _ := curve.RandomK(rand.Reader)
+	B, _ := curve.RandomK(rand.Reader)
+	C := new(big.Int).Mul(A, B)
+	C = C.Mod(C, system.Order)
+	sharesA := system.Share_An_Fp_Offline(A)
+	sharesB := system.Share_An_Fp_Offline(B)
+	sharesC := system.Share_An_Fp_Offline(C)
+	return sharesA, sharesB, sharesC
+}
+
+func (system *ECCShareSystem) GenTriplets() (*[]Share_Fp, *[]Share_Fp, *[]Share_Fp) {
+	A, _ := rand.Int(rand.Reader, system.Order)
+	B, _ := rand.Int(rand.Reader, system.Order)
+	C := new(big.Int).Mul(A, B)
+	C = C.Mod(C, system.Order)
+	sharesA := system.Share_An_Fp_Offline(A)
+	sharesB := system.Share_An_Fp_Offline(B)
+	sharesC := system.Share_An_Fp_Offline(C)
+	return sharesA, sharesB, sharesC
+}
+
+func SystemInit() *ShareSystem {
+	system := new(ShareSystem)
+	system.failure = network.NewFailure()
+	system.Partynum = Partynum
+	system.Net = network.NewChanNetwork(Partynum + 1)
+	system.IdentityG1 = new(curve.G1).ScalarBaseMult(zero)
+	system.IdentityG2 = new(curve.G2).ScalarBaseMult(zero)
+	system.GenGT = curve.Pair(curve.Gen1, curve.Gen2)
+	system.IdentityGT = new(curve.GT).ScalarMult(system.GenGT, zero)
+	system.Order = new(big.Int).Set(curve.Order)
+	return system
+}
+
+// SystemInitWAN returns ErrBandwidth when bandwidth is not positive.
+func SystemInitWAN(bandwidth float64) (*ShareSystem, error) {
+	if bandwidth <= 0 {
+		return nil, ErrBandwidth
+	}
+	system := SystemInit()
+	system.isWAN = true
+	system.bandwidth = bandwidth
+	system.BandwidthCtrl = shmpc.NewBandwidthSimulator(bandwidth)
+	return system, nil
+}
+
+func ECCSystemInit() *ECCShareSystem {
+	system := new(ECCShareSystem)
+	system.failure = network.NewFailure()
+	system.Partynum = Partynum
+	system.Net = network.NewChanNetwork(Partynum + 1)
+	s := ecc.S256()
+	system.IdentityGx, system.IdentityGy = s.ScalarMult(s.Gx, s.Gy, zero.Bytes())
+	system.Order = new(big.Int).Set(s.N)
+	system.Curve = s
+	return system
+}
+
+// ECCSystemInitWAN returns ErrBandwidth when bandwidth is not positive.
+func ECCSystemInitWAN(bandwidth float64) (*ECCShareSystem, error) {
+	if bandwidth <= 0 {
+		return nil, ErrBandwidth
+	}
+	system := ECCSystemInit()
+	system.isWAN = true
+	system.bandwidth = bandwidth
+	system.BandwidthCtrl = shmpc.NewBandwidthSimulator(bandwidth)
+	return system, nil
+}
@@ -0,0 +1,174 @@
+package shamir
+
+import (
+	"crypto/rand"
+	"math/big"
+	"sync"
+
+	"github.com/Oryx/curve"
+)
+
+type Share_Fp struct {
+	Share *big.Int
+	Index int
+}
+
+func (system *ShareSystem) Share_An_Fp(element *big.Int) *[]Share_Fp {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go system.Send(&wg, system.Partynum, element.Bytes())
+	values := system.poly(element)
+	shares := make([]Share_Fp, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i].Index = i
+		shares[i].Share = values[i]
+		wg.Add(1)
+		go system.Send(&wg, i, shares[i].Share.Bytes())
+	}
+	wg.Wait()
+	return &shares
+}
+
+func (system *ShareSystem) Share_An_Fp_Offline(element *big.Int) *[]Share_Fp {
+	var wg sync.WaitGroup
+	values := system.poly(element)
+	shares := make([]Share_Fp, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i].Index = i
+		shares[i].Share = values[i]
+		wg.Add(1)
+		go system.OfflineSend(&wg, i, shares[i].Share.Bytes())
+	}
+	wg.Wait()
+	return &shares
+}
+
+func (system *ShareSystem) RandomShareFp() *[]Share_Fp {
+	r, _ := curve.RandomK(rand.Reader)
+	rshares := system.Share_An_Fp_Offline(r)
+	return rshares
+}
+
+func (system *ShareSystem) shareAdd(shares1, shares2 Share_Fp) Share_Fp {
+	shares := new(Share_Fp)
+	shares.Index = shares1.Index
+	shares.Share = new(big.Int).Add(shares1.Share, shares2.Share)
+	shares.Share = shares.Share.Mod(shares.Share, system.Order)
+	return *shares
+}
+
+// SecAddPlaintext adds the constant to every share, which shifts the constant
+// term of the polynomial; no dealer is needed.
+func (system *ShareSystem) SecAddPlaintext(shares1 []Share_Fp, scalar *big.Int) *[]Share_Fp {
+	shares := make([]Share_Fp, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareAdd(shares1[i], Share_Fp{Share: scalar, Index: i})
+	}
+	return &shares
+}
+
+func (system *ShareSystem) SecAdd(shares1, shares2 []Share_Fp) *[]Share_Fp {
+	shares := make([]Share_Fp, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareAdd(shares1[i], shares2[i])
+	}
+	return &shares
+}
+
+func (system *ShareSystem) SecSubPlaintext(shares1 []Share_Fp, scalar *big.Int) *[]Share_Fp {
+	shares := make([]Share_Fp, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareSub(shares1[i], Share_Fp{Share: scalar, Index: i})
+	}
+	return &shares
+}
+
+func (system *ShareSystem) shareSub(shares1, shares2 Share_Fp) Share_Fp {
+	shares := new(Share_Fp)
+	shares.Index = shares1.Index
+	shares.Share = new(big.Int).Sub(shares1.Share, shares2.Share)
+	shares.Share = shares.Share.Mod(shares.Share, system.Order)
+	return *shares
+}
+
+func (system *ShareSystem) SecSub(shares1, shares2 []Share_Fp) *[]Share_Fp {
+	shares := make([]Share_Fp, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareSub(shares1[i], shares2[i])
+	}
+	return &shares
+}
+
+func (system *ShareSystem) shareMulPlaintext(shares1 Share_Fp, scalar *big.Int) Share_Fp {
+	shares := new(Share_Fp)
+	shares.Index = shares1.Index
+	shares.Share = new(big.Int).Mul(shares1.Share, scalar)
+	shares.Share = shares.Share.Mod(shares.Share, system.Order)
+	return *shares
+}
+
+func (system *ShareSystem) SecMulPlaintext(shares1 []Share_Fp, scalar *big.Int) *[]Share_Fp {
+	shares := make([]Share_Fp, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareMulPlaintext(shares1[i], scalar)
+	}
+	return &shares
+}
+
+// SecMul multiplies without triples. The local products of the shares lie on
+// a polynomial of degree 2*Threshold, so 2*Threshold+1 online parties each
+// reshare their product with degree Threshold, and every party combines the
+// pieces it receives with the Lagrange coefficients of those parties. It
+// returns ErrDropouts when fewer parties are online.
+func (system *ShareSystem) SecMul(shares1, shares2 []Share_Fp) (*[]Share_Fp, error) {
+	var wg sync.WaitGroup
+	parties, lambdas, err := system.quorum(2*system.Threshold + 1)
+	if err != nil {
+		return nil, err
+	}
+	shares := make([]Share_Fp, system.Partynum)
+	for j := 0; j < system.Partynum; j++ {
+		shares[j].Index = j
+		shares[j].Share = big.NewInt(0)
+	}
+	for k, i := range parties {
+		d := new(big.Int).Mul(shares1[i].Share, shares2[i].Share)
+		pieces := system.poly(d)
+		for j := 0; j < system.Partynum; j++ {
+			shares[j].Share.Add(shares[j].Share, new(big.Int).Mul(lambdas[k], pieces[j]))
+			shares[j].Share.Mod(shares[j].Share, system.Order)
+			if j != i {
+				wg.Add(1)
+				go system.transfer(&wg, i, j, pieces[j].Bytes())
+			}
+		}
+	}
+	wg.Wait()
+	return &shares, nil
+}
+
+func (system *ShareSystem) SecSquare(shares1 []Share_Fp) (*[]Share_Fp, error) {
+	return system.SecMul(shares1, shares1)
+}
+
+// OpenFp has every online party broadcast its share and interpolates the
+// value from the first Threshold+1 of them. It returns ErrDropouts when
+// fewer parties are online.
+func (system *ShareSystem) OpenFp(shares []Share_Fp) (*big.Int, error) {
+	var wg sync.WaitGroup
+	parties, lambdas, err := system.quorum(system.Threshold + 1)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range system.Online() {
+		wg.Add(1)
+		go system.Broadcast(&wg, i, shares[i].Share.Bytes())
+	}
+	ori_value := big.NewInt(0)
+	for k, i := range parties {
+		ori_value.Add(ori_value, new(big.Int).Mul(lambdas[k], shares[i].Share))
+	}
+	ori_value = ori_value.Mod(ori_value, system.Order)
+	wg.Wait()
+	return ori_value, nil
+}
@@ -0,0 +1,101 @@
+package shamir
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestSystemInitThreshold(t *testing.T) {
+	for _, tc := range []struct {
+		n, t int
+		want error
+	}{
+		{3, 1, nil},
+		{5, 4, nil},
+		{3, 0, ErrThreshold},
+		{3, 3, ErrThreshold},
+		{3, -1, ErrThreshold},
+	} {
+		if _, err := SystemInit(tc.n, tc.t); !errors.Is(err, tc.want) {
+			t.Fatalf("got %v for n = %d, t = %d, want %v", err, tc.n, tc.t, tc.want)
+		}
+	}
+}
+
+func TestSecMul(t *testing.T) {
+	for _, tc := range []struct {
+		n, t    int
+		dropped []int
+		want    error
+	}{
+		{3, 1, nil, nil},
+		{5, 2, nil, nil},
+		{7, 3, nil, nil},
+		{5, 1, []int{0, 4}, nil},
+		{5, 2, []int{0}, ErrDropouts},
+	} {
+		system, err := SystemInit(tc.n, tc.t)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pminus1 := new(big.Int).Sub(system.Order, big.NewInt(1))
+		x := system.Share_An_Fp(pminus1)
+		y := system.Share_An_Fp(big.NewInt(3))
+		for _, i := range tc.dropped {
+			system.Drop(i)
+		}
+		shares, err := system.SecMul(*x, *y)
+		if !errors.Is(err, tc.want) {
+			t.Fatalf("got %v for n = %d, t = %d without %v, want %v", err, tc.n, tc.t, tc.dropped, tc.want)
+		}
+		if err != nil {
+			continue
+		}
+		got, err := system.OpenFp(*shares)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := new(big.Int).Sub(system.Order, big.NewInt(3))
+		if got.Cmp(want) != 0 {
+			t.Fatalf("got %v for (p-1) * 3, want %v", got, want)
+		}
+	}
+}
+
+func TestOpenFpDropouts(t *testing.T) {
+	for _, tc := range []struct {
+		n, t    int
+		dropped []int
+		want    error
+	}{
+		{5, 2, nil, nil},
+		// exactly Threshold+1 parties online
+		{5, 2, []int{0, 1}, nil},
+		{5, 2, []int{1, 3}, nil},
+		{4, 1, []int{0, 1}, nil},
+		{5, 2, []int{0, 1, 2}, ErrDropouts},
+		{3, 1, []int{0, 2}, ErrDropouts},
+	} {
+		system, err := SystemInit(tc.n, tc.t)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, x := range []*big.Int{big.NewInt(0), big.NewInt(424242), new(big.Int).Sub(system.Order, big.NewInt(1))} {
+			shares := system.Share_An_Fp(x)
+			for _, i := range tc.dropped {
+				system.Drop(i)
+			}
+			got, err := system.OpenFp(*shares)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("got %v for n = %d, t = %d without %v, want %v", err, tc.n, tc.t, tc.dropped, tc.want)
+			}
+			if err == nil && got.Cmp(x) != 0 {
+				t.Fatalf("got %v without %v, want %v", got, tc.dropped, x)
+			}
+			for _, i := range tc.dropped {
+				system.Rejoin(i)
+			}
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package shamir
+
+import (
+	"crypto/rand"
+	"math/big"
+	"sync"
+
+	curve "github.com/Oryx/curve"
+)
+
+type Share_G1 struct {
+	Share *curve.G1
+	Index int
+}
+
+// poly_G1 is poly for a group element: the coefficients of the polynomial
+// in the exponent are random elements.
+func (system *ShareSystem) poly_G1(element *curve.G1) []*curve.G1 {
+	coeffs := make([]*curve.G1, system.Threshold)
+	for k := range coeffs {
+		_, coeffs[k], _ = curve.RandomG1(rand.Reader)
+	}
+	values := make([]*curve.G1, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		values[i] = new(curve.G1).Set(element)
+		for k, x := range system.powers(i) {
+			values[i].Add(values[i], new(curve.G1).ScalarMult(coeffs[k], x))
+		}
+	}
+	return values
+}
+
+func (system *ShareSystem) Share_A_G1(element *curve.G1) *[]Share_G1 {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go system.Send(&wg, system.Partynum, element.Marshal())
+	values := system.poly_G1(element)
+	shares := make([]Share_G1, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i].Index = i
+		shares[i].Share = values[i]
+		wg.Add(1)
+		go system.Send(&wg, i, shares[i].Share.Marshal())
+	}
+	wg.Wait()
+	return &shares
+}
+
+func (system *ShareSystem) Share_A_G1_Offline(element *curve.G1) *[]Share_G1 {
+	var wg sync.WaitGroup
+	values := system.poly_G1(element)
+	shares := make([]Share_G1, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i].Index = i
+		shares[i].Share = values[i]
+		wg.Add(1)
+		go system.OfflineSend(&wg, i, shares[i].Share.Marshal())
+	}
+	wg.Wait()
+	return &shares
+}
+
+func (system *ShareSystem) RandomShareG1() *[]Share_G1 {
+	_, r, _ := curve.RandomG1(rand.Reader)
+	rshares := system.Share_A_G1_Offline(r)
+	return rshares
+}
+
+func (system *ShareSystem) share_EXP_P_G1_1(element *curve.G1, xshares Share_Fp) Share_G1 {
+	shares := new(Share_G1)
+	shares.Index = xshares.Index
+	shares.Share = new(curve.G1).ScalarMult(element, xshares.Share)
+	return *shares
+}
+
+func (system *ShareSystem) EXP_P_G1_1(element *curve.G1, xshares *[]Share_Fp) *[]Share_G1 {
+	shares := make([]Share_G1, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.share_EXP_P_G1_1(element, (*xshares)[i])
+	}
+	return &shares
+}
+
+func (system *ShareSystem) share_EXP_P_G1_2(eshare Share_G1, x *big.Int) Share_G1 {
+	shares := new(Share_G1)
+	shares.Index = eshare.Index
+	shares.Share = new(curve.G1).ScalarMult(eshare.Share, x)
+	return *shares
+}
+
+func (system *ShareSystem) EXP_P_G1_2(eshares *[]Share_G1, x *big.Int) *[]Share_G1 {
+	shares := make([]Share_G1, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.share_EXP_P_G1_2((*eshares)[i], x)
+	}
+	return &shares
+}
+
+func (system *ShareSystem) shareAdd_G1(shares1, shares2 Share_G1) Share_G1 {
+	shares := new(Share_G1)
+	shares.Index = shares1.Index
+	shares.Share = new(curve.G1).Add(shares1.Share, shares2.Share)
+	return *shares
+}
+
+func (system *ShareSystem) SecAddPlaintext_G1(shares1 []Share_G1, scalar *curve.G1) *[]Share_G1 {
+	shares := make([]Share_G1, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareAdd_G1(shares1[i], Share_G1{Share: scalar, Index: i})
+	}
+	return &shares
+}
+
+func (system *ShareSystem) SecAdd_G1(shares1, shares2 []Share_G1) *[]Share_G1 {
+	shares := make([]Share_G1, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareAdd_G1(shares1[i], shares2[i])
+	}
+	return &shares
+}
+
+func (system *ShareSystem) shareSub_G1(shares1, shares2 Share_G1) Share_G1 {
+	shares := new(Share_G1)
+	shares.Index = shares1.Index
+	shares.Share = new(curve.G1).Add(shares1.Share, new(curve.G1).Neg(shares2.Share))
+	return *shares
+}
+
+func (system *ShareSystem) SecSub_G1(shares1, shares2 []Share_G1) *[]Share_G1 {
+	shares := make([]Share_G1, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareSub_G1(shares1[i], shares2[i])
+	}
+	return &shares
+}
+
+func (system *ShareSystem) SecSubPlaintext_G1(shares1 []Share_G1, scalar *curve.G1) *[]Share_G1 {
+	shares := make([]Share_G1, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareSub_G1(shares1[i], Share_G1{Share: scalar, Index: i})
+	}
+	return &shares
+}
+
+// EXP_S_G1 opens two masked values, so it returns ErrDropouts when fewer
+// than Threshold+1 parties are online.
+func (system *ShareSystem) EXP_S_G1(hshares []Share_G1, xshares []Share_Fp) (*[]Share_G1, error) {
+	sharesA, sharesB, sharesC := system.GenTriplets()
+	sharesgB := system.EXP_P_G1_1(curve.Gen1, sharesB)
+	sharesgC := system.EXP_P_G1_1(curve.Gen1, sharesC)
+	XsubAshares := system.SecSub(xshares, *sharesA)
+	xsuba, err := system.OpenFp(*XsubAshares)
+	if err != nil {
+		return nil, err
+	}
+	tshares := system.SecSub_G1(hshares, *sharesgB)
+	t, err := system.OpenG1(*tshares)
+	if err != nil {
+		return nil, err
+	}
+	t_exp_xsuba_shares := system.EXP_P_G1_1(t, XsubAshares)
+	t_exp_a_shares := system.EXP_P_G1_1(t, sharesA)
+	gb_exp_xsuba_shares := system.EXP_P_G1_2(sharesgB, xsuba)
+	shares := system.SecAdd_G1(*sharesgC, *gb_exp_xsuba_shares)
+	shares = system.SecAdd_G1(*shares, *t_exp_a_shares)
+	shares = system.SecAdd_G1(*shares, *t_exp_xsuba_shares)
+	return shares, nil
+}
+
+// OpenG1 interpolates in the exponent: the shares of the first Threshold+1
+// online parties are raised to their Lagrange coefficients and added up. It
+// returns ErrDropouts when fewer parties are online.
+func (system *ShareSystem) OpenG1(shares []Share_G1) (*curve.G1, error) {
+	var wg sync.WaitGroup
+	parties, lambdas, err := system.quorum(system.Threshold + 1)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range system.Online() {
+		wg.Add(1)
+		go system.Broadcast(&wg, i, shares[i].Share.Marshal())
+	}
+	ori_value := new(curve.G1).Set(system.IdentityG1)
+	for k, i := range parties {
+		ori_value.Add(ori_value, new(curve.G1).ScalarMult(shares[i].Share, lambdas[k]))
+	}
+	wg.Wait()
+	return ori_value, nil
+}
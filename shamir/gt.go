@@ -0,0 +1,189 @@
+package shamir
+
+import (
+	"crypto/rand"
+	"math/big"
+	"sync"
+
+	curve "github.com/Oryx/curve"
+)
+
+type Share_GT struct {
+	Share *curve.GT
+	Index int
+}
+
+// poly_GT is poly for a group element: the coefficients of the polynomial
+// in the exponent are random elements.
+func (system *ShareSystem) poly_GT(element *curve.GT) []*curve.GT {
+	coeffs := make([]*curve.GT, system.Threshold)
+	for k := range coeffs {
+		_, coeffs[k], _ = curve.RandomGTK(rand.Reader)
+	}
+	values := make([]*curve.GT, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		values[i] = new(curve.GT).Set(element)
+		for k, x := range system.powers(i) {
+			values[i].Add(values[i], new(curve.GT).ScalarMult(coeffs[k], x))
+		}
+	}
+	return values
+}
+
+func (system *ShareSystem) Share_A_GT(element *curve.GT) *[]Share_GT {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go system.Send(&wg, system.Partynum, element.Marshal())
+	values := system.poly_GT(element)
+	shares := make([]Share_GT, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i].Index = i
+		shares[i].Share = values[i]
+		wg.Add(1)
+		go system.Send(&wg, i, shares[i].Share.Marshal())
+	}
+	wg.Wait()
+	return &shares
+}
+
+func (system *ShareSystem) Share_A_GT_Offline(element *curve.GT) *[]Share_GT {
+	var wg sync.WaitGroup
+	values := system.poly_GT(element)
+	shares := make([]Share_GT, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i].Index = i
+		shares[i].Share = values[i]
+		wg.Add(1)
+		go system.OfflineSend(&wg, i, shares[i].Share.Marshal())
+	}
+	wg.Wait()
+	return &shares
+}
+
+func (system *ShareSystem) RandomShareGT() *[]Share_GT {
+	_, r, _ := curve.RandomGTK(rand.Reader)
+	rshares := system.Share_A_GT_Offline(r)
+	return rshares
+}
+
+func (system *ShareSystem) share_EXP_P_GT_1(element *curve.GT, xshares Share_Fp) Share_GT {
+	shares := new(Share_GT)
+	shares.Index = xshares.Index
+	shares.Share = new(curve.GT).ScalarMult(element, xshares.Share)
+	return *shares
+}
+
+func (system *ShareSystem) EXP_P_GT_1(element *curve.GT, xshares *[]Share_Fp) *[]Share_GT {
+	shares := make([]Share_GT, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.share_EXP_P_GT_1(element, (*xshares)[i])
+	}
+	return &shares
+}
+
+func (system *ShareSystem) share_EXP_P_GT_2(eshare Share_GT, x *big.Int) Share_GT {
+	shares := new(Share_GT)
+	shares.Index = eshare.Index
+	shares.Share = new(curve.GT).ScalarMult(eshare.Share, x)
+	return *shares
+}
+
+func (system *ShareSystem) EXP_P_GT_2(eshares *[]Share_GT, x *big.Int) *[]Share_GT {
+	shares := make([]Share_GT, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.share_EXP_P_GT_2((*eshares)[i], x)
+	}
+	return &shares
+}
+
+func (system *ShareSystem) shareAdd_GT(shares1, shares2 Share_GT) Share_GT {
+	shares := new(Share_GT)
+	shares.Index = shares1.Index
+	shares.Share = new(curve.GT).Add(shares1.Share, shares2.Share)
+	return *shares
+}
+
+func (system *ShareSystem) SecAddPlaintext_GT(shares1 []Share_GT, scalar *curve.GT) *[]Share_GT {
+	shares := make([]Share_GT, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareAdd_GT(shares1[i], Share_GT{Share: scalar, Index: i})
+	}
+	return &shares
+}
+
+func (system *ShareSystem) SecAdd_GT(shares1, shares2 []Share_GT) *[]Share_GT {
+	shares := make([]Share_GT, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareAdd_GT(shares1[i], shares2[i])
+	}
+	return &shares
+}
+
+func (system *ShareSystem) shareSub_GT(shares1, shares2 Share_GT) Share_GT {
+	shares := new(Share_GT)
+	shares.Index = shares1.Index
+	shares.Share = new(curve.GT).Add(shares1.Share, new(curve.GT).Neg(shares2.Share))
+	return *shares
+}
+
+func (system *ShareSystem) SecSub_GT(shares1, shares2 []Share_GT) *[]Share_GT {
+	shares := make([]Share_GT, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareSub_GT(shares1[i], shares2[i])
+	}
+	return &shares
+}
+
+func (system *ShareSystem) SecSubPlaintext_GT(shares1 []Share_GT, scalar *curve.GT) *[]Share_GT {
+	shares := make([]Share_GT, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = system.shareSub_GT(shares1[i], Share_GT{Share: scalar, Index: i})
+	}
+	return &shares
+}
+
+// EXP_S_GT opens two masked values, so it returns ErrDropouts when fewer
+// than Threshold+1 parties are online.
+func (system *ShareSystem) EXP_S_GT(hshares []Share_GT, xshares []Share_Fp) (*[]Share_GT, error) {
+	sharesA, sharesB, sharesC := system.GenTriplets()
+	sharesgB := system.EXP_P_GT_1(system.GenGT, sharesB)
+	sharesgC := system.EXP_P_GT_1(system.GenGT, sharesC)
+	XsubAshares := system.SecSub(xshares, *sharesA)
+	xsuba, err := system.OpenFp(*XsubAshares)
+	if err != nil {
+		return nil, err
+	}
+	tshares := system.SecSub_GT(hshares, *sharesgB)
+	t, err := system.OpenGT(*tshares)
+	if err != nil {
+		return nil, err
+	}
+	t_exp_xsuba_shares := system.EXP_P_GT_1(t, XsubAshares)
+	t_exp_a_shares := system.EXP_P_GT_1(t, sharesA)
+	gb_exp_xsuba_shares := system.EXP_P_GT_2(sharesgB, xsuba)
+	shares := system.SecAdd_GT(*sharesgC, *gb_exp_xsuba_shares)
+	shares = system.SecAdd_GT(*shares, *t_exp_a_shares)
+	shares = system.SecAdd_GT(*shares, *t_exp_xsuba_shares)
+	return shares, nil
+}
+
+// OpenGT interpolates in the exponent: the shares of the first Threshold+1
+// online parties are raised to their Lagrange coefficients and added up. It
+// returns ErrDropouts when fewer parties are online.
+func (system *ShareSystem) OpenGT(shares []Share_GT) (*curve.GT, error) {
+	var wg sync.WaitGroup
+	parties, lambdas, err := system.quorum(system.Threshold + 1)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range system.Online() {
+		wg.Add(1)
+		go system.Broadcast(&wg, i, shares[i].Share.Marshal())
+	}
+	ori_value := new(curve.GT).Set(system.IdentityGT)
+	for k, i := range parties {
+		ori_value.Add(ori_value, new(curve.GT).ScalarMult(shares[i].Share, lambdas[k]))
+	}
+	wg.Wait()
+	return ori_value, nil
+}
@@ -0,0 +1,266 @@
+package shamir
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"sync"
+	"sync/atomic"
+
+	curve "github.com/Oryx/curve"
+	"github.com/Oryx/network"
+	"github.com/Oryx/shmpc"
+)
+
+var zero = big.NewInt(0)
+
+var ErrBandwidth = errors.New("shamir: bandwidth must be positive")
+var ErrThreshold = errors.New("shamir: threshold must be at least 1 and below the number of parties")
+var ErrDropouts = errors.New("shamir: too few parties online")
+
+// ShareSystem is the t-out-of-n counterpart of shmpc.ShareSystem. A value is
+// shared as the evaluations at 1..Partynum of a random polynomial of degree
+// Threshold with the value as constant term; party i holds the evaluation at
+// i+1. Any Threshold+1 parties can open a value and Threshold parties learn
+// nothing about it, so up to Partynum-Threshold-1 parties may drop out, or
+// Partynum-2*Threshold-1 when values are multiplied.
+type ShareSystem struct {
+	Partynum      int
+	Threshold     int
+	online        []bool
+	IdentityG1    *curve.G1
+	IdentityG2    *curve.G2
+	GenGT         *curve.GT
+	IdentityGT    *curve.GT
+	Order         *big.Int
+	OrderMul      *big.Int
+	Com           int64
+	OfflineCom    int64
+	isWAN         bool
+	bandwidth     float64
+	BandwidthCtrl *shmpc.BandwidthSimulator
+	Net           []network.Transport
+	// failure records the first message that could not be delivered; see
+	// Context.
+	failure *network.Failure
+}
+
+// Drop marks party i as gone. Its shares are still filled in so that the
+// indices stay aligned, but they are no longer sent to it or read. Drop must
+// not be called while an operation is running.
+func (system *ShareSystem) Drop(i int) {
+	system.online[i] = false
+}
+
+// Rejoin marks party i as online again. It only holds valid shares of the
+// values shared while it was online.
+func (system *ShareSystem) Rejoin(i int) {
+	system.online[i] = true
+}
+
+// Online returns the indices of the parties that have not dropped out.
+func (system *ShareSystem) Online() []int {
+	parties := make([]int, 0, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		if system.online[i] {
+			parties = append(parties, i)
+		}
+	}
+	return parties
+}
+
+// quorum returns the first size online parties and their Lagrange
+// coefficients for interpolating at zero. It returns ErrDropouts when fewer
+// parties are online.
+func (system *ShareSystem) quorum(size int) ([]int, []*big.Int, error) {
+	parties := system.Online()
+	if len(parties) < size {
+		return nil, nil, ErrDropouts
+	}
+	parties = parties[:size]
+	lambdas := make([]*big.Int, size)
+	for k, i := range parties {
+		num := big.NewInt(1)
+		den := big.NewInt(1)
+		for _, j := range parties {
+			if j == i {
+				continue
+			}
+			num.Mul(num, big.NewInt(int64(j+1)))
+			num.Mod(num, system.Order)
+			den.Mul(den, big.NewInt(int64(j-i)))
+			den.Mod(den, system.Order)
+		}
+		lambdas[k] = num.Mul(num, den.ModInverse(den, system.Order))
+		lambdas[k].Mod(lambdas[k], system.Order)
+	}
+	return parties, lambdas, nil
+}
+
+// complete returns ErrDropouts unless every party is online. The
+// multiplicative and exponent shares of fpmul.go are n-out-of-n, as in shmpc,
+// so they cannot be opened without all of them.
+func (system *ShareSystem) complete() error {
+	if len(system.Online()) < system.Partynum {
+		return ErrDropouts
+	}
+	return nil
+}
+
+// poly returns the evaluations at 1..Partynum of a random polynomial of
+// degree Threshold through (0, element).
+func (system *ShareSystem) poly(element *big.Int) []*big.Int {
+	coeffs := make([]*big.Int, system.Threshold+1)
+	coeffs[0] = new(big.Int).Mod(element, system.Order)
+	for k := 1; k <= system.Threshold; k++ {
+		coeffs[k], _ = curve.RandomK(rand.Reader)
+	}
+	values := make([]*big.Int, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		x := big.NewInt(int64(i + 1))
+		v := new(big.Int).Set(coeffs[system.Threshold])
+		for k := system.Threshold - 1; k >= 0; k-- {
+			v.Mul(v, x)
+			v.Add(v, coeffs[k])
+			v.Mod(v, system.Order)
+		}
+		values[i] = v
+	}
+	return values
+}
+
+// powers returns (i+1)^k for k = 1..Threshold, the weights of the random
+// coefficients in the share of party i.
+func (system *ShareSystem) powers(i int) []*big.Int {
+	x := big.NewInt(int64(i + 1))
+	pows := make([]*big.Int, system.Threshold+1)
+	pows[0] = big.NewInt(1)
+	for k := 1; k <= system.Threshold; k++ {
+		pows[k] = new(big.Int).Mul(pows[k-1], x)
+		pows[k].Mod(pows[k], system.Order)
+	}
+	return pows[1:]
+}
+
+// reachable tells whether messages to endpoint to are delivered; the dealer
+// never drops out.
+func (system *ShareSystem) reachable(to int) bool {
+	return to == system.Partynum || system.online[to]
+}
+
+// Context is cancelled with the error of the first message the system fails
+// to deliver, as for mpc.ShareSystem. NetErr returns it.
+func (system *ShareSystem) Context() context.Context {
+	return system.failure.Context()
+}
+
+func (system *ShareSystem) NetErr() error {
+	return system.failure.Err()
+}
+
+func (system *ShareSystem) Send(wg *sync.WaitGroup, to int, msg []byte) {
+	if system.reachable(to) {
+		atomic.AddInt64(&system.Com, int64(len(msg)))
+		if system.isWAN && system.BandwidthCtrl != nil {
+			system.BandwidthCtrl.SimulateSend(msg)
+		}
+		system.failure.Record(network.Deliver(system.Net, system.Partynum, to, msg))
+	}
+	wg.Done()
+}
+
+// Broadcast sends msg from party from to every other online party.
+func (system *ShareSystem) Broadcast(wg *sync.WaitGroup, from int, msg []byte) {
+	receivers := 0
+	for j := 0; j < system.Partynum; j++ {
+		if j != from && system.online[j] {
+			receivers++
+		}
+	}
+	atomic.AddInt64(&system.Com, int64(receivers*len(msg)))
+	if system.isWAN && system.BandwidthCtrl != nil {
+		system.BandwidthCtrl.SimulateBroadcast(msg, receivers)
+	}
+	for j := 0; j < system.Partynum; j++ {
+		if j != from && system.online[j] {
+			system.failure.Record(network.Deliver(system.Net, from, j, msg))
+		}
+	}
+	wg.Done()
+}
+
+func (system *ShareSystem) OfflineSend(wg *sync.WaitGroup, to int, msg []byte) {
+	if system.reachable(to) {
+		atomic.AddInt64(&system.OfflineCom, int64(len(msg)))
+		if system.isWAN && system.BandwidthCtrl != nil {
+			system.BandwidthCtrl.SimulateSend(msg)
+		}
+		system.failure.Record(network.Deliver(system.Net, system.Partynum, to, msg))
+	}
+	wg.Done()
+}
+
+// transfer carries one online message between two parties, if the receiver
+// is online.
+func (system *ShareSystem) transfer(wg *sync.WaitGroup, from, to int, msg []byte) {
+	if system.online[to] {
+		atomic.AddInt64(&system.Com, int64(len(msg)))
+		if system.isWAN && system.BandwidthCtrl != nil {
+			system.BandwidthCtrl.SimulateSend(msg)
+		}
+		system.failure.Record(network.Deliver(system.Net, from, to, msg))
+	}
+	wg.Done()
+}
+
+func (system *ShareSystem) GenTriplets() (*[]Share_Fp, *[]Share_Fp, *[]Share_Fp) {
+	A, _ := curve.RandomK(rand.Reader)
+	B, _ := curve.RandomK(rand.Reader)
+	C := new(big.Int).Mul(A, B)
+	C = C.Mod(C, system.Order)
+	sharesA := system.Share_An_Fp_Offline(A)
+	sharesB := system.Share_An_Fp_Offline(B)
+	sharesC := system.Share_An_Fp_Offline(C)
+	return sharesA, sharesB, sharesC
+}
+
+// SystemInit returns ErrThreshold unless 1 <= Threshold < Partynum.
+// Multiplication further needs 2*Threshold+1 parties online.
+func SystemInit(Partynum, Threshold int) (*ShareSystem, error) {
+	if Threshold < 1 || Threshold >= Partynum {
+		return nil, ErrThreshold
+	}
+	system := new(ShareSystem)
+	system.failure = network.NewFailure()
+	system.Partynum = Partynum
+	system.Threshold = Threshold
+	system.online = make([]bool, Partynum)
+	for i := range system.online {
+		system.online[i] = true
+	}
+	system.Net = network.NewChanNetwork(Partynum + 1)
+	system.IdentityG1 = new(curve.G1).ScalarBaseMult(zero)
+	system.IdentityG2 = new(curve.G2).ScalarBaseMult(zero)
+	system.GenGT = curve.Pair(curve.Gen1, curve.Gen2)
+	system.IdentityGT = new(curve.GT).ScalarMult(system.GenGT, zero)
+	system.Order = new(big.Int).Set(curve.Order)
+	system.OrderMul = new(big.Int).Sub(curve.Order, big.NewInt(1))
+	return system, nil
+}
+
+// SystemInitWAN returns ErrBandwidth when bandwidth is not positive, and the
+// errors of SystemInit.
+func SystemInitWAN(Partynum, Threshold int, bandwidth float64) (*ShareSystem, error) {
+	if bandwidth <= 0 {
+		return nil, ErrBandwidth
+	}
+	system, err := SystemInit(Partynum, Threshold)
+	if err != nil {
+		return nil, err
+	}
+	system.isWAN = true
+	system.bandwidth = bandwidth
+	system.BandwidthCtrl = shmpc.NewBandwidthSimulator(bandwidth)
+	return system, nil
+}
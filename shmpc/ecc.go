@@ -29,8 +29,8 @@ func (system *ECCShareSystem) Share_A_G(elementX, elementY *big.Int) *[]Share_G
 	ori_valueX := new(big.Int).Set(elementX)
 	ori_valueY := new(big.Int).Set(elementY)
 	wg.Add(2)
-	go system.Send(&wg, ori_valueX.Bytes())
-	go system.Send(&wg, ori_valueY.Bytes())
+	go system.Send(&wg, system.Partynum, ori_valueX.Bytes())
+	go system.Send(&wg, system.Partynum, ori_valueY.Bytes())
 	shares := make([]Share_G, system.Partynum)
 	for i := 0; i < system.Partynum; i++ {
 		shares[i].Index = i
@@ -42,8 +42,8 @@ func (system *ECCShareSystem) Share_A_G(elementX, elementY *big.Int) *[]Share_G
 			shares[i].ShareY = ori_valueY
 		}
 		wg.Add(2)
-		go system.Send(&wg, shares[i].ShareX.Bytes())
-		go system.Send(&wg, shares[i].ShareY.Bytes())
+		go system.Send(&wg, i, shares[i].ShareX.Bytes())
+		go system.Send(&wg, i, shares[i].ShareY.Bytes())
 	}
 	wg.Wait()
 	return &shares
@@ -64,8 +64,8 @@ func (system *ECCShareSystem) Share_A_G_Offline(elementX, elementY *big.Int) *[]
 			shares[i].ShareY = ori_valueY
 		}
 		wg.Add(2)
-		go system.OfflineSend(&wg, shares[i].ShareX.Bytes())
-		go system.OfflineSend(&wg, shares[i].ShareY.Bytes())
+		go system.OfflineSend(&wg, i, shares[i].ShareX.Bytes())
+		go system.OfflineSend(&wg, i, shares[i].ShareY.Bytes())
 	}
 	wg.Wait()
 	return &shares
@@ -178,8 +178,8 @@ func (system *ECCShareSystem) OpenG(shares []Share_G) (*big.Int, *big.Int) {
 			ori_valueX, ori_valueY = system.Curve.Add(ori_valueX, ori_valueY, shares[i].ShareX, shares[i].ShareY)
 		}
 		wg.Add(2)
-		go system.Broadcast(&wg, shares[i].ShareX.Bytes())
-		go system.Broadcast(&wg, shares[i].ShareY.Bytes())
+		go system.Broadcast(&wg, i, shares[i].ShareX.Bytes())
+		go system.Broadcast(&wg, i, shares[i].ShareY.Bytes())
 	}
 	wg.Wait()
 	return ori_valueX, ori_valueY
@@ -189,7 +189,7 @@ func (system *ECCShareSystem) Share_An_Fp(element *big.Int) *[]Share_Fp {
 	var wg sync.WaitGroup
 	ori_value := new(big.Int).Set(element)
 	wg.Add(1)
-	go system.Send(&wg, ori_value.Bytes())
+	go system.Send(&wg, system.Partynum, ori_value.Bytes())
 	shares := make([]Share_Fp, system.Partynum)
 	for i := 0; i < system.Partynum; i++ {
 		shares[i].Index = i
@@ -201,7 +201,7 @@ func (system *ECCShareSystem) Share_An_Fp(element *big.Int) *[]Share_Fp {
 			shares[i].Share = ori_value
 		}
 		wg.Add(1)
-		go system.Send(&wg, shares[i].Share.Bytes())
+		go system.Send(&wg, i, shares[i].Share.Bytes())
 	}
 	wg.Wait()
 	return &shares
@@ -221,7 +221,7 @@ func (system *ECCShareSystem) Share_An_Fp_Offline(element *big.Int) *[]Share_Fp
 			shares[i].Share = ori_value
 		}
 		wg.Add(1)
-		go system.OfflineSend(&wg, shares[i].Share.Bytes())
+		go system.OfflineSend(&wg, i, shares[i].Share.Bytes())
 	}
 	wg.Wait()
 	return &shares
@@ -337,7 +337,7 @@ func (system *ECCShareSystem) OpenFp(shares []Share_Fp) *big.Int {
 	var wg sync.WaitGroup
 	for i := 0; i < system.Partynum; i++ {
 		wg.Add(1)
-		go system.Broadcast(&wg, shares[i].Share.Bytes())
+		go system.Broadcast(&wg, i, shares[i].Share.Bytes())
 		ori_value = ori_value.Add(ori_value, shares[i].Share)
 	}
 	wg.Wait()
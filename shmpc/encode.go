@@ -0,0 +1,40 @@
+package shmpc
+
+import (
+	"math/big"
+
+	curve "github.com/Oryx/curve"
+)
+
+func MarshalFp(share Share_Fp) []byte {
+	return share.Share.Bytes()
+}
+
+func UnmarshalFp(msg []byte, index int) (Share_Fp, error) {
+	var share Share_Fp
+	share.Share = new(big.Int).SetBytes(msg)
+	share.Index = index
+	return share, nil
+}
+
+func marshalShare[E any, P curve.Group[E]](share Share[E]) []byte {
+	return P(share.Share).Marshal()
+}
+
+func unmarshalShare[E any, P curve.Group[E]](msg []byte, index int) (Share[E], error) {
+	var share Share[E]
+	share.Share = new(E)
+	if _, err := P(share.Share).Unmarshal(msg); err != nil {
+		return share, err
+	}
+	share.Index = index
+	return share, nil
+}
+
+func MarshalGT(share Share_GT) []byte {
+	return marshalShare(share)
+}
+
+func UnmarshalGT(msg []byte, index int) (Share_GT, error) {
+	return unmarshalShare[curve.GT](msg, index)
+}
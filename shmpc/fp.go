@@ -17,7 +17,7 @@ func (system *ShareSystem) Share_An_Fp(element *big.Int) *[]Share_Fp {
 	var wg sync.WaitGroup
 	ori_value := new(big.Int).Set(element)
 	wg.Add(1)
-	go system.Send(&wg, ori_value.Bytes())
+	go system.Send(&wg, system.Partynum, ori_value.Bytes())
 	shares := make([]Share_Fp, system.Partynum)
 	for i := 0; i < system.Partynum; i++ {
 		shares[i].Index = i
@@ -29,7 +29,7 @@ func (system *ShareSystem) Share_An_Fp(element *big.Int) *[]Share_Fp {
 			shares[i].Share = ori_value
 		}
 		wg.Add(1)
-		go system.Send(&wg, shares[i].Share.Bytes())
+		go system.Send(&wg, i, shares[i].Share.Bytes())
 	}
 	wg.Wait()
 	return &shares
@@ -49,7 +49,7 @@ func (system *ShareSystem) Share_An_Fp_Offline(element *big.Int) *[]Share_Fp {
 			shares[i].Share = ori_value
 		}
 		wg.Add(1)
-		go system.OfflineSend(&wg, shares[i].Share.Bytes())
+		go system.OfflineSend(&wg, i, shares[i].Share.Bytes())
 	}
 	wg.Wait()
 	return &shares
@@ -165,7 +165,7 @@ func (system *ShareSystem) OpenFp(shares []Share_Fp) *big.Int {
 	ori_value := big.NewInt(0)
 	for i := 0; i < system.Partynum; i++ {
 		wg.Add(1)
-		go system.Broadcast(&wg, shares[i].Share.Bytes())
+		go system.Broadcast(&wg, i, shares[i].Share.Bytes())
 		ori_value = ori_value.Add(ori_value, shares[i].Share)
 	}
 	ori_value = ori_value.Mod(ori_value, system.Order)
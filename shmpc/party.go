@@ -0,0 +1,269 @@
+package shmpc
+
+import (
+	"math/big"
+	"sync/atomic"
+
+	curve "github.com/Oryx/curve"
+	"github.com/Oryx/network"
+)
+
+// Party is the runtime of one party in a distributed semi-honest deployment.
+// It holds its own shares only and exchanges openings with the other parties
+// through Net. Endpoint Partynum of Net is the dealer that hands out
+// preprocessed shares. Every party must call the interactive methods in the
+// same order.
+type Party struct {
+	Index    int
+	Partynum int
+	Net      network.Transport
+	Com      int64
+	system   *ShareSystem
+}
+
+// Dealer hands out the preprocessed material of a ShareSystem to the parties
+// of a distributed deployment.
+type Dealer struct {
+	System *ShareSystem
+	Net    network.Transport
+}
+
+// params returns a ShareSystem carrying only public parameters, so that
+// parties can reuse the local share arithmetic.
+func params(Partynum int) *ShareSystem {
+	system := new(ShareSystem)
+	system.Partynum = Partynum
+	system.IdentityG1 = new(curve.G1).ScalarBaseMult(zero)
+	system.IdentityG2 = new(curve.G2).ScalarBaseMult(zero)
+	system.GenGT = curve.Pair(curve.Gen1, curve.Gen2)
+	system.IdentityGT = new(curve.GT).ScalarMult(system.GenGT, zero)
+	system.Order = new(big.Int).Set(curve.Order)
+	system.OrderMul = new(big.Int).Sub(curve.Order, one)
+	return system
+}
+
+func NewParty(Index int, Partynum int, net network.Transport) *Party {
+	party := new(Party)
+	party.Index = Index
+	party.Partynum = Partynum
+	party.Net = net
+	party.system = params(Partynum)
+	return party
+}
+
+func NewDealer(system *ShareSystem, net network.Transport) *Dealer {
+	dealer := new(Dealer)
+	dealer.System = system
+	dealer.Net = net
+	return dealer
+}
+
+func (dealer *Dealer) DealFp(shares []Share_Fp) error {
+	for i := 0; i < dealer.System.Partynum; i++ {
+		if err := dealer.Net.Send(i, MarshalFp(shares[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func dealShares[E any, P curve.Group[E]](dealer *Dealer, shares []Share[E]) error {
+	for i := 0; i < dealer.System.Partynum; i++ {
+		if err := dealer.Net.Send(i, marshalShare[E, P](shares[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (dealer *Dealer) DealG1(shares []Share_G1) error {
+	return dealShares(dealer, shares)
+}
+
+func (dealer *Dealer) DealG2(shares []Share_G2) error {
+	return dealShares(dealer, shares)
+}
+
+func (dealer *Dealer) DealGT(shares []Share_GT) error {
+	return dealShares(dealer, shares)
+}
+
+func (dealer *Dealer) DealTriplet() error {
+	sharesA, sharesB, sharesC := dealer.System.GenTriplets()
+	for _, shares := range []*[]Share_Fp{sharesA, sharesB, sharesC} {
+		if err := dealer.DealFp(*shares); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (party *Party) dealer() int {
+	return party.Partynum
+}
+
+func (party *Party) RecvFp() (Share_Fp, error) {
+	msg, err := party.Net.Recv(party.dealer())
+	if err != nil {
+		return Share_Fp{}, err
+	}
+	return UnmarshalFp(msg, party.Index)
+}
+
+func partyRecv[E any, P curve.Group[E]](party *Party) (Share[E], error) {
+	msg, err := party.Net.Recv(party.dealer())
+	if err != nil {
+		return Share[E]{}, err
+	}
+	return unmarshalShare[E, P](msg, party.Index)
+}
+
+func (party *Party) RecvG1() (Share_G1, error) {
+	return partyRecv[curve.G1](party)
+}
+
+func (party *Party) RecvG2() (Share_G2, error) {
+	return partyRecv[curve.G2](party)
+}
+
+func (party *Party) RecvGT() (Share_GT, error) {
+	return partyRecv[curve.GT](party)
+}
+
+func (party *Party) RecvTriplet() (Share_Fp, Share_Fp, Share_Fp, error) {
+	var shares [3]Share_Fp
+	for k := range shares {
+		share, err := party.RecvFp()
+		if err != nil {
+			return Share_Fp{}, Share_Fp{}, Share_Fp{}, err
+		}
+		shares[k] = share
+	}
+	return shares[0], shares[1], shares[2], nil
+}
+
+// exchange sends msg to every other party and collects one message from each
+// of them. The caller's own message is placed at its own index.
+func (party *Party) exchange(msg []byte) ([][]byte, error) {
+	for j := 0; j < party.Partynum; j++ {
+		if j == party.Index {
+			continue
+		}
+		if err := party.Net.Send(j, msg); err != nil {
+			return nil, err
+		}
+		atomic.AddInt64(&party.Com, int64(len(msg)))
+	}
+	msgs := make([][]byte, party.Partynum)
+	for j := 0; j < party.Partynum; j++ {
+		if j == party.Index {
+			msgs[j] = msg
+			continue
+		}
+		m, err := party.Net.Recv(j)
+		if err != nil {
+			return nil, err
+		}
+		msgs[j] = m
+	}
+	return msgs, nil
+}
+
+func (party *Party) OpenFp(share Share_Fp) (*big.Int, error) {
+	msgs, err := party.exchange(share.Share.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	ori_value := big.NewInt(0)
+	for j := 0; j < party.Partynum; j++ {
+		ori_value = ori_value.Add(ori_value, new(big.Int).SetBytes(msgs[j]))
+	}
+	ori_value = ori_value.Mod(ori_value, party.system.Order)
+	return ori_value, nil
+}
+
+func partyOpen[E any, P curve.Group[E]](party *Party, share Share[E]) (*E, error) {
+	msgs, err := party.exchange(P(share.Share).Marshal())
+	if err != nil {
+		return nil, err
+	}
+	ori_value := P(new(E)).Identity()
+	for j := 0; j < party.Partynum; j++ {
+		s := new(E)
+		if _, err := P(s).Unmarshal(msgs[j]); err != nil {
+			return nil, err
+		}
+		ori_value = P(ori_value).Add(ori_value, s)
+	}
+	return ori_value, nil
+}
+
+func (party *Party) SecAdd(share1, share2 Share_Fp) Share_Fp {
+	return party.system.shareAdd(share1, share2)
+}
+
+func (party *Party) SecSub(share1, share2 Share_Fp) Share_Fp {
+	return party.system.shareSub(share1, share2)
+}
+
+func (party *Party) SecMulPlaintext(share1 Share_Fp, scalar *big.Int) Share_Fp {
+	return party.system.shareMulPlaintext(share1, scalar)
+}
+
+// SecAddPlaintext adds a public constant without interaction: only party 0
+// adds it to its share.
+func (party *Party) SecAddPlaintext(share1 Share_Fp, scalar *big.Int) Share_Fp {
+	share := new(Share_Fp)
+	share.Index = share1.Index
+	share.Share = new(big.Int).Set(share1.Share)
+	if party.Index == 0 {
+		share.Share = share.Share.Add(share.Share, scalar)
+		share.Share = share.Share.Mod(share.Share, party.system.Order)
+	}
+	return *share
+}
+
+func (party *Party) SecMul(share1, share2 Share_Fp) (Share_Fp, error) {
+	shareA, shareB, shareC, err := party.RecvTriplet()
+	if err != nil {
+		return Share_Fp{}, err
+	}
+	e, err := party.OpenFp(party.SecSub(share1, shareA))
+	if err != nil {
+		return Share_Fp{}, err
+	}
+	f, err := party.OpenFp(party.SecSub(share2, shareB))
+	if err != nil {
+		return Share_Fp{}, err
+	}
+	ef := new(big.Int).Mul(e, f)
+	ef = ef.Mod(ef, party.system.Order)
+	share := party.SecAdd(shareC, party.SecMulPlaintext(shareB, e))
+	share = party.SecAdd(share, party.SecMulPlaintext(shareA, f))
+	return party.SecAddPlaintext(share, ef), nil
+}
+
+// partyEXP_S computes this party's share of h^x, consuming the next triple
+// dealt by the dealer.
+func partyEXP_S[E any, P curve.Group[E]](party *Party, hshare Share[E], xshare Share_Fp) (Share[E], error) {
+	gen := P(new(E)).Generator()
+	shareA, shareB, shareC, err := party.RecvTriplet()
+	if err != nil {
+		return Share[E]{}, err
+	}
+	sharegB := groupShare_EXP_P_1[E, P](gen, shareB)
+	sharegC := groupShare_EXP_P_1[E, P](gen, shareC)
+	XsubAshare := party.SecSub(xshare, shareA)
+	xsuba, err := party.OpenFp(XsubAshare)
+	if err != nil {
+		return Share[E]{}, err
+	}
+	t, err := partyOpen[E, P](party, groupSub[E, P](hshare, sharegB))
+	if err != nil {
+		return Share[E]{}, err
+	}
+	share := groupAdd[E, P](sharegC, groupShare_EXP_P_2[E, P](sharegB, xsuba))
+	share = groupAdd[E, P](share, groupShare_EXP_P_1[E, P](t, shareA))
+	share = groupAdd[E, P](share, groupShare_EXP_P_1[E, P](t, XsubAshare))
+	return share, nil
+}
@@ -0,0 +1,129 @@
+package shmpc
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/Oryx/curve"
+)
+
+// Share is an additive share of a value in a curve.Group. Share_G1, Share_G2
+// and Share_GT are its instances; the methods of ShareSystem on them wrap the
+// functions below, which implement them once for every group.
+type Share[E any] struct {
+	Share *E
+	Index int
+}
+
+type Share_G1 = Share[curve.G1]
+type Share_G2 = Share[curve.G2]
+type Share_GT = Share[curve.GT]
+
+// groupShare_A is the dealer's sharing of element. The offline variant is
+// counted as preprocessing and does not send element to the dealer.
+func groupShare_A[E any, P curve.Group[E]](system *ShareSystem, element *E, offline bool) *[]Share[E] {
+	var wg sync.WaitGroup
+	send := system.Send
+	if offline {
+		send = system.OfflineSend
+	}
+	ori_value := P(new(E)).Set(element)
+	if !offline {
+		wg.Add(1)
+		go system.Send(&wg, system.Partynum, P(ori_value).Marshal())
+	}
+	shares := make([]Share[E], system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i].Index = i
+		if i < system.Partynum-1 {
+			shares[i].Share = curve.RandomElement[E, P]()
+			ori_value = P(ori_value).Add(ori_value, P(new(E)).Neg(shares[i].Share))
+		} else {
+			shares[i].Share = ori_value
+		}
+		wg.Add(1)
+		go send(&wg, i, P(shares[i].Share).Marshal())
+	}
+	wg.Wait()
+	return &shares
+}
+
+func groupShare_EXP_P_1[E any, P curve.Group[E]](element *E, xshare Share_Fp) Share[E] {
+	return Share[E]{Share: P(new(E)).ScalarMult(element, xshare.Share), Index: xshare.Index}
+}
+
+func groupEXP_P_1[E any, P curve.Group[E]](system *ShareSystem, element *E, xshares *[]Share_Fp) *[]Share[E] {
+	shares := make([]Share[E], system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = groupShare_EXP_P_1[E, P](element, (*xshares)[i])
+	}
+	return &shares
+}
+
+func groupShare_EXP_P_2[E any, P curve.Group[E]](eshare Share[E], x *big.Int) Share[E] {
+	return Share[E]{Share: P(new(E)).ScalarMult(eshare.Share, x), Index: eshare.Index}
+}
+
+func groupEXP_P_2[E any, P curve.Group[E]](system *ShareSystem, eshares *[]Share[E], x *big.Int) *[]Share[E] {
+	shares := make([]Share[E], system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = groupShare_EXP_P_2[E, P]((*eshares)[i], x)
+	}
+	return &shares
+}
+
+func groupAdd[E any, P curve.Group[E]](shares1, shares2 Share[E]) Share[E] {
+	return Share[E]{Share: P(new(E)).Add(shares1.Share, shares2.Share), Index: shares1.Index}
+}
+
+func groupSub[E any, P curve.Group[E]](shares1, shares2 Share[E]) Share[E] {
+	return Share[E]{Share: P(new(E)).Add(shares1.Share, P(new(E)).Neg(shares2.Share)), Index: shares1.Index}
+}
+
+func groupSecAdd[E any, P curve.Group[E]](system *ShareSystem, shares1, shares2 []Share[E]) *[]Share[E] {
+	shares := make([]Share[E], system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = groupAdd[E, P](shares1[i], shares2[i])
+	}
+	return &shares
+}
+
+func groupSecSub[E any, P curve.Group[E]](system *ShareSystem, shares1, shares2 []Share[E]) *[]Share[E] {
+	shares := make([]Share[E], system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		shares[i] = groupSub[E, P](shares1[i], shares2[i])
+	}
+	return &shares
+}
+
+// groupEXP_S raises a shared element h to a shared exponent x with a triple
+// (a, b, c): h^x = g^c * (g^b)^(x-a) * t^a * t^(x-a) for t = h - g^b.
+func groupEXP_S[E any, P curve.Group[E]](system *ShareSystem, hshares []Share[E], xshares []Share_Fp) *[]Share[E] {
+	gen := P(new(E)).Generator()
+	sharesA, sharesB, sharesC := system.GenTriplets()
+	sharesgB := groupEXP_P_1[E, P](system, gen, sharesB)
+	sharesgC := groupEXP_P_1[E, P](system, gen, sharesC)
+	XsubAshares := system.SecSub(xshares, *sharesA)
+	xsuba := system.OpenFp(*XsubAshares)
+	tshares := groupSecSub[E, P](system, hshares, *sharesgB)
+	t := groupOpen[E, P](system, *tshares)
+	t_exp_xsuba_shares := groupEXP_P_1[E, P](system, t, XsubAshares)
+	t_exp_a_shares := groupEXP_P_1[E, P](system, t, sharesA)
+	gb_exp_xsuba_shares := groupEXP_P_2[E, P](system, sharesgB, xsuba)
+	shares := groupSecAdd[E, P](system, *sharesgC, *gb_exp_xsuba_shares)
+	shares = groupSecAdd[E, P](system, *shares, *t_exp_a_shares)
+	shares = groupSecAdd[E, P](system, *shares, *t_exp_xsuba_shares)
+	return shares
+}
+
+func groupOpen[E any, P curve.Group[E]](system *ShareSystem, shares []Share[E]) *E {
+	var wg sync.WaitGroup
+	ori_value := P(new(E)).Identity()
+	for i := 0; i < system.Partynum; i++ {
+		ori_value = P(ori_value).Add(ori_value, shares[i].Share)
+		wg.Add(1)
+		go system.Broadcast(&wg, i, P(shares[i].Share).Marshal())
+	}
+	wg.Wait()
+	return ori_value
+}
@@ -0,0 +1,188 @@
+package shmpc
+
+import (
+	"math/big"
+	"sync"
+
+	curve "github.com/Oryx/curve"
+	"github.com/Oryx/network"
+)
+
+// Simulator runs all parties of a ShareSystem in one process on top of the
+// party-local API. Each call hands share i to party i, runs the parties
+// concurrently over an in-memory network and collects their results. The
+// ShareSystem acts as the dealer of triples. Calls are serialized because the
+// parties match messages by their order on each link.
+type Simulator struct {
+	System  *ShareSystem
+	Parties []*Party
+	dealer  *Dealer
+	mu      sync.Mutex
+}
+
+func NewSimulator(system *ShareSystem) *Simulator {
+	sim := new(Simulator)
+	sim.System = system
+	net := network.NewChanNetwork(system.Partynum + 1)
+	sim.dealer = NewDealer(system, net[system.Partynum])
+	sim.Parties = make([]*Party, system.Partynum)
+	for i := 0; i < system.Partynum; i++ {
+		sim.Parties[i] = NewParty(i, system.Partynum, net[i])
+	}
+	return sim
+}
+
+// run executes f for every party concurrently and returns the first error.
+func (sim *Simulator) run(f func(i int, party *Party) error) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(sim.Parties))
+	for i, party := range sim.Parties {
+		wg.Add(1)
+		go func(i int, party *Party) {
+			defer wg.Done()
+			errs[i] = f(i, party)
+		}(i, party)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Com returns the online communication of all parties in bytes.
+func (sim *Simulator) Com() int64 {
+	var com int64
+	for _, party := range sim.Parties {
+		com += party.Com
+	}
+	return com
+}
+
+func (sim *Simulator) SecAdd(shares1, shares2 []Share_Fp) *[]Share_Fp {
+	shares := make([]Share_Fp, len(sim.Parties))
+	for i, party := range sim.Parties {
+		shares[i] = party.SecAdd(shares1[i], shares2[i])
+	}
+	return &shares
+}
+
+func (sim *Simulator) SecSub(shares1, shares2 []Share_Fp) *[]Share_Fp {
+	shares := make([]Share_Fp, len(sim.Parties))
+	for i, party := range sim.Parties {
+		shares[i] = party.SecSub(shares1[i], shares2[i])
+	}
+	return &shares
+}
+
+func (sim *Simulator) SecAddPlaintext(shares1 []Share_Fp, scalar *big.Int) *[]Share_Fp {
+	shares := make([]Share_Fp, len(sim.Parties))
+	for i, party := range sim.Parties {
+		shares[i] = party.SecAddPlaintext(shares1[i], scalar)
+	}
+	return &shares
+}
+
+func (sim *Simulator) SecMulPlaintext(shares1 []Share_Fp, scalar *big.Int) *[]Share_Fp {
+	shares := make([]Share_Fp, len(sim.Parties))
+	for i, party := range sim.Parties {
+		shares[i] = party.SecMulPlaintext(shares1[i], scalar)
+	}
+	return &shares
+}
+
+func (sim *Simulator) SecMul(shares1, shares2 []Share_Fp) (*[]Share_Fp, error) {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	if err := sim.dealer.DealTriplet(); err != nil {
+		return nil, err
+	}
+	shares := make([]Share_Fp, len(sim.Parties))
+	err := sim.run(func(i int, party *Party) error {
+		share, err := party.SecMul(shares1[i], shares2[i])
+		shares[i] = share
+		return err
+	})
+	return &shares, err
+}
+
+func (sim *Simulator) OpenFp(shares []Share_Fp) (*big.Int, error) {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	values := make([]*big.Int, len(sim.Parties))
+	err := sim.run(func(i int, party *Party) error {
+		value, err := party.OpenFp(shares[i])
+		values[i] = value
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return values[0], nil
+}
+
+// The group operations run the same party-local code for G1, G2 and GT.
+
+func simEXP_P_1[E any, P curve.Group[E]](sim *Simulator, element *E, xshares []Share_Fp) *[]Share[E] {
+	shares := make([]Share[E], len(sim.Parties))
+	for i := range sim.Parties {
+		shares[i] = groupShare_EXP_P_1[E, P](element, xshares[i])
+	}
+	return &shares
+}
+
+func simEXP_P_2[E any, P curve.Group[E]](sim *Simulator, eshares []Share[E], x *big.Int) *[]Share[E] {
+	shares := make([]Share[E], len(sim.Parties))
+	for i := range sim.Parties {
+		shares[i] = groupShare_EXP_P_2[E, P](eshares[i], x)
+	}
+	return &shares
+}
+
+func simSecAdd[E any, P curve.Group[E]](sim *Simulator, shares1, shares2 []Share[E]) *[]Share[E] {
+	shares := make([]Share[E], len(sim.Parties))
+	for i := range sim.Parties {
+		shares[i] = groupAdd[E, P](shares1[i], shares2[i])
+	}
+	return &shares
+}
+
+func simSecSub[E any, P curve.Group[E]](sim *Simulator, shares1, shares2 []Share[E]) *[]Share[E] {
+	shares := make([]Share[E], len(sim.Parties))
+	for i := range sim.Parties {
+		shares[i] = groupSub[E, P](shares1[i], shares2[i])
+	}
+	return &shares
+}
+
+func simEXP_S[E any, P curve.Group[E]](sim *Simulator, hshares []Share[E], xshares []Share_Fp) (*[]Share[E], error) {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	if err := sim.dealer.DealTriplet(); err != nil {
+		return nil, err
+	}
+	shares := make([]Share[E], len(sim.Parties))
+	err := sim.run(func(i int, party *Party) error {
+		share, err := partyEXP_S[E, P](party, hshares[i], xshares[i])
+		shares[i] = share
+		return err
+	})
+	return &shares, err
+}
+
+func simOpen[E any, P curve.Group[E]](sim *Simulator, shares []Share[E]) (*E, error) {
+	sim.mu.Lock()
+	defer sim.mu.Unlock()
+	values := make([]*E, len(sim.Parties))
+	err := sim.run(func(i int, party *Party) error {
+		value, err := partyOpen[E, P](party, shares[i])
+		values[i] = value
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return values[0], nil
+}
@@ -2,21 +2,23 @@ package shmpc
 
 import (
 	"crypto/rand"
-	"fmt"
+	"errors"
 	"math/big"
-	"os"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	curve "github.com/Oryx/curve"
 	"github.com/Oryx/ecc"
+	"github.com/Oryx/network"
 )
 
 var zero = big.NewInt(0)
 var one = big.NewInt(1)
 var two = big.NewInt(2)
 
+var ErrBandwidth = errors.New("shmpc: bandwidth must be positive")
+
 type ShareSystem struct {
 	alpha         *big.Int
 	Partynum      int
@@ -32,6 +34,7 @@ type ShareSystem struct {
 	isWAN         bool
 	bandwidth     float64
 	BandwidthCtrl *BandwidthSimulator
+	Net           []network.Transport
 }
 
 type ECCShareSystem struct {
@@ -47,6 +50,7 @@ type ECCShareSystem struct {
 	isWAN         bool
 	bandwidth     float64
 	BandwidthCtrl *BandwidthSimulator
+	Net           []network.Transport
 }
 
 type Triplets struct {
@@ -122,19 +126,21 @@ func max(a, b int64) int64 {
 	return b
 }
 
-func (system *ShareSystem) Send(wg *sync.WaitGroup, msg []byte) {
+func (system *ShareSystem) Send(wg *sync.WaitGroup, to int, msg []byte) {
 	atomic.AddInt64(&system.Com, int64(len(msg)))
 	if system.isWAN && system.BandwidthCtrl != nil {
 		system.BandwidthCtrl.SimulateSend(msg)
 	}
+	network.Deliver(system.Net, system.Partynum, to, msg)
 	wg.Done()
 }
 
-func (system *ShareSystem) Broadcast(wg *sync.WaitGroup, msg []byte) {
+func (system *ShareSystem) Broadcast(wg *sync.WaitGroup, from int, msg []byte) {
 	atomic.AddInt64(&system.Com, int64((system.Partynum-1)*len(msg)))
 	if system.isWAN && system.BandwidthCtrl != nil {
 		system.BandwidthCtrl.SimulateBroadcast(msg, system.Partynum-1)
 	}
+	network.DeliverAll(system.Net, from, msg)
 	wg.Done()
 }
 
@@ -143,22 +149,25 @@ func (system *ShareSystem) BroadcastN(wg *sync.WaitGroup, msg []byte) {
 	if system.isWAN && system.BandwidthCtrl != nil {
 		system.BandwidthCtrl.SimulateBroadcast(msg, system.Partynum)
 	}
+	network.DeliverAll(system.Net, system.Partynum, msg)
 	wg.Done()
 }
 
-func (system *ShareSystem) OfflineSend(wg *sync.WaitGroup, msg []byte) {
+func (system *ShareSystem) OfflineSend(wg *sync.WaitGroup, to int, msg []byte) {
 	atomic.AddInt64(&system.OfflineCom, int64(len(msg)))
 	if system.isWAN && system.BandwidthCtrl != nil {
 		system.BandwidthCtrl.SimulateSend(msg)
 	}
+	network.Deliver(system.Net, system.Partynum, to, msg)
 	wg.Done()
 }
 
-func (system *ShareSystem) OfflineBroadcast(wg *sync.WaitGroup, msg []byte) {
+func (system *ShareSystem) OfflineBroadcast(wg *sync.WaitGroup, from int, msg []byte) {
 	atomic.AddInt64(&system.OfflineCom, int64((system.Partynum-1)*len(msg)))
 	if system.isWAN && system.BandwidthCtrl != nil {
 		system.BandwidthCtrl.SimulateBroadcast(msg, system.Partynum-1)
 	}
+	network.DeliverAll(system.Net, from, msg)
 	wg.Done()
 }
 
@@ -167,22 +176,25 @@ func (system *ShareSystem) OfflineBroadcastN(wg *sync.WaitGroup, msg []byte) {
 	if system.isWAN && system.BandwidthCtrl != nil {
 		system.BandwidthCtrl.SimulateBroadcast(msg, system.Partynum)
 	}
+	network.DeliverAll(system.Net, system.Partynum, msg)
 	wg.Done()
 }
 
-func (system *ECCShareSystem) Send(wg *sync.WaitGroup, msg []byte) {
+func (system *ECCShareSystem) Send(wg *sync.WaitGroup, to int, msg []byte) {
 	atomic.AddInt64(&system.Com, int64(len(msg)))
 	if system.isWAN && system.BandwidthCtrl != nil {
 		system.BandwidthCtrl.SimulateSend(msg)
 	}
+	network.Deliver(system.Net, system.Partynum, to, msg)
 	wg.Done()
 }
 
-func (system *ECCShareSystem) Broadcast(wg *sync.WaitGroup, msg []byte) {
+func (system *ECCShareSystem) Broadcast(wg *sync.WaitGroup, from int, msg []byte) {
 	atomic.AddInt64(&system.Com, int64((system.Partynum-1)*len(msg)))
 	if system.isWAN && system.BandwidthCtrl != nil {
 		system.BandwidthCtrl.SimulateBroadcast(msg, system.Partynum-1)
 	}
+	network.DeliverAll(system.Net, from, msg)
 	wg.Done()
 }
 
@@ -191,22 +203,25 @@ func (system *ECCShareSystem) BroadcastN(wg *sync.WaitGroup, msg []byte) {
 	if system.isWAN && system.BandwidthCtrl != nil {
 		system.BandwidthCtrl.SimulateBroadcast(msg, system.Partynum)
 	}
+	network.DeliverAll(system.Net, system.Partynum, msg)
 	wg.Done()
 }
 
-func (system *ECCShareSystem) OfflineSend(wg *sync.WaitGroup, msg []byte) {
+func (system *ECCShareSystem) OfflineSend(wg *sync.WaitGroup, to int, msg []byte) {
 	atomic.AddInt64(&system.OfflineCom, int64(len(msg)))
 	if system.isWAN && system.BandwidthCtrl != nil {
 		system.BandwidthCtrl.SimulateSend(msg)
 	}
+	network.Deliver(system.Net, system.Partynum, to, msg)
 	wg.Done()
 }
 
-func (system *ECCShareSystem) OfflineBroadcast(wg *sync.WaitGroup, msg []byte) {
+func (system *ECCShareSystem) OfflineBroadcast(wg *sync.WaitGroup, from int, msg []byte) {
 	atomic.AddInt64(&system.OfflineCom, int64((system.Partynum-1)*len(msg)))
 	if system.isWAN && system.BandwidthCtrl != nil {
 		system.BandwidthCtrl.SimulateBroadcast(msg, system.Partynum-1)
 	}
+	network.DeliverAll(system.Net, from, msg)
 	wg.Done()
 }
 
@@ -215,6 +230,7 @@ func (system *ECCShareSystem) OfflineBroadcastN(wg *sync.WaitGroup, msg []byte)
 	if system.isWAN && system.BandwidthCtrl != nil {
 		system.BandwidthCtrl.SimulateBroadcast(msg, system.Partynum)
 	}
+	network.DeliverAll(system.Net, system.Partynum, msg)
 	wg.Done()
 }
 
@@ -261,6 +277,7 @@ func (system *ECCShareSystem) GenSquarePair() (*[]Share_Fp, *[]Share_Fp) {
 func SystemInit(Partynum int) *ShareSystem {
 	system := new(ShareSystem)
 	system.Partynum = Partynum
+	system.Net = network.NewChanNetwork(Partynum + 1)
 	system.alpha, _ = curve.RandomK(rand.Reader)
 	orialpha := new(big.Int).Set(system.alpha)
 	system.Alphas = make([]*big.Int, Partynum)
@@ -285,6 +302,7 @@ func SystemInit(Partynum int) *ShareSystem {
 func ECCSystemInit(Partynum int) *ECCShareSystem {
 	system := new(ECCShareSystem)
 	system.Partynum = Partynum
+	system.Net = network.NewChanNetwork(Partynum + 1)
 	s := ecc.S256()
 	system.alpha, _ = rand.Int(rand.Reader, s.N)
 	orialpha := new(big.Int).Set(system.alpha)
@@ -304,9 +322,14 @@ func ECCSystemInit(Partynum int) *ECCShareSystem {
 	return system
 }
 
-func SystemInitWAN(Partynum int, bandwidth float64) *ShareSystem {
+// SystemInitWAN returns ErrBandwidth when bandwidth is not positive.
+func SystemInitWAN(Partynum int, bandwidth float64) (*ShareSystem, error) {
+	if bandwidth <= 0 {
+		return nil, ErrBandwidth
+	}
 	system := new(ShareSystem)
 	system.Partynum = Partynum
+	system.Net = network.NewChanNetwork(Partynum + 1)
 	system.alpha, _ = curve.RandomK(rand.Reader)
 	orialpha := new(big.Int).Set(system.alpha)
 	system.Alphas = make([]*big.Int, Partynum)
@@ -326,18 +349,18 @@ func SystemInitWAN(Partynum int, bandwidth float64) *ShareSystem {
 	system.Order = new(big.Int).Set(curve.Order)
 	system.OrderMul = new(big.Int).Sub(curve.Order, one)
 	system.isWAN = true
-	if bandwidth > 0 {
-		system.BandwidthCtrl = NewBandwidthSimulator(bandwidth)
-	} else {
-		fmt.Println("The bindwidth < 0")
-		os.Exit(1)
-	}
-	return system
+	system.BandwidthCtrl = NewBandwidthSimulator(bandwidth)
+	return system, nil
 }
 
-func ECCSystemInitWAN(Partynum int, bandwidth float64) *ECCShareSystem {
+// ECCSystemInitWAN returns ErrBandwidth when bandwidth is not positive.
+func ECCSystemInitWAN(Partynum int, bandwidth float64) (*ECCShareSystem, error) {
+	if bandwidth <= 0 {
+		return nil, ErrBandwidth
+	}
 	system := new(ECCShareSystem)
 	system.Partynum = Partynum
+	system.Net = network.NewChanNetwork(Partynum + 1)
 	s := ecc.S256()
 	system.alpha, _ = rand.Int(rand.Reader, s.N)
 	orialpha := new(big.Int).Set(system.alpha)
@@ -355,11 +378,6 @@ func ECCSystemInitWAN(Partynum int, bandwidth float64) *ECCShareSystem {
 	system.Order = new(big.Int).Set(s.N)
 	system.Curve = s
 	system.isWAN = true
-	if bandwidth > 0 {
-		system.BandwidthCtrl = NewBandwidthSimulator(bandwidth)
-	} else {
-		fmt.Println("The bindwidth < 0")
-		os.Exit(1)
-	}
-	return system
+	system.BandwidthCtrl = NewBandwidthSimulator(bandwidth)
+	return system, nil
 }